@@ -0,0 +1,208 @@
+// cmd/bible-importer - Imports Bibles into the shared Bible text store
+//
+// Usage:
+//
+//	go run ./cmd/bible-importer osis -translation WEB -name "World English Bible" eng-web.osis.xml
+//	go run ./cmd/bible-importer usfm -translation BSN -lang sw ./bsn-usfm/
+//	go run ./cmd/bible-importer zefania -translation ASV SF_2009-01-20_ENG_ASV.xml
+//	go run ./cmd/bible-importer csv -translation YLT -dry-run ylt.csv
+//	go run ./cmd/bible-importer json -translation SWNT -lang sw "verses/txt files/swahili-bible.json"
+//	go run ./cmd/bible-importer verify -translation WEB
+//
+// Each format subcommand reads one or more files (a directory stands for the
+// files in it, e.g. one USFM file per book), upserts the verses under the
+// translation code and prints a report of book, chapter and verse counts
+// against the canon. Importing the same translation again updates its verses
+// in place. The database comes from DATABASE_URL or the DB_* variables, as for
+// the server, which loads new translations on its next start.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"ubible/database"
+	"ubible/models"
+	"ubible/reference"
+	"ubible/services"
+
+	"github.com/joho/godotenv"
+)
+
+// maxDropped is how many reasons for dropping verses the report lists
+const maxDropped = 20
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	command := strings.ToLower(os.Args[1])
+	if command == "verify" {
+		verify(os.Args[2:])
+		return
+	}
+	if _, ok := services.BibleFormats[command]; !ok {
+		usage()
+		os.Exit(2)
+	}
+	importBible(command, os.Args[2:])
+}
+
+func usage() {
+	var formats []string
+	for name := range services.BibleFormats {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	fmt.Fprintf(os.Stderr, "usage: bible-importer <%s> -translation CODE [flags] FILE|DIR...\n", strings.Join(formats, "|"))
+	fmt.Fprintln(os.Stderr, "       bible-importer verify -translation CODE [-format json]")
+}
+
+func importBible(format string, args []string) {
+	fs := flag.NewFlagSet(format, flag.ExitOnError)
+	code := fs.String("translation", "", "Translation code, e.g. KJV or SWNT (required)")
+	name := fs.String("name", "", "Translation name (defaults to the file's title, then the code)")
+	lang := fs.String("lang", "en", "Language of the translation's book names: en or sw")
+	description := fs.String("description", "", "Translation description")
+	dryRun := fs.Bool("dry-run", false, "Read and report without writing to the database")
+	output := fs.String("format", "human", "Report format: human or json")
+	fs.Parse(args)
+
+	if strings.TrimSpace(*code) == "" || fs.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "bible-importer %s: -translation and at least one file are required\n", format)
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *lang != string(reference.English) && *lang != string(reference.Swahili) {
+		log.Fatalf("Unknown language %q (want en or sw)", *lang)
+	}
+
+	imp, err := services.ReadBibleFiles(format, fs.Args())
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", strings.Join(fs.Args(), ", "), err)
+	}
+	fmt.Fprintf(os.Stderr, "Read %d verses from %s\n", len(imp.Verses), strings.Join(fs.Args(), ", "))
+	if imp.Duplicates > 0 {
+		fmt.Fprintf(os.Stderr, "Skipped %d repeated verses (the first text was kept)\n", imp.Duplicates)
+	}
+
+	translation := models.BibleTranslation{
+		Code:        strings.ToUpper(strings.TrimSpace(*code)),
+		Name:        *name,
+		Language:    *lang,
+		Description: *description,
+		Source:      strings.Join(fs.Args(), ", "),
+	}
+	if translation.Name == "" {
+		translation.Name = imp.Name
+	}
+	if translation.Name == "" {
+		translation.Name = translation.Code
+	}
+
+	if *dryRun {
+		printReport(services.CountVerses(translation.Code, imp.Verses), imp.Dropped, *output)
+		return
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found, using system environment variables")
+	}
+	database.InitDB()
+
+	n, err := services.ImportTranslation(database.GetDB(), translation, imp.Verses)
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "✓ Imported %d verses into %s (%s)\n", n, translation.Code, translation.Name)
+
+	report, err := services.VerifyTranslation(database.GetDB(), translation.Code)
+	if err != nil {
+		log.Fatalf("Verification failed: %v", err)
+	}
+	printReport(report, imp.Dropped, *output)
+}
+
+func verify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	code := fs.String("translation", "", "Translation code to check (required)")
+	output := fs.String("format", "human", "Report format: human or json")
+	fs.Parse(args)
+
+	if strings.TrimSpace(*code) == "" {
+		fmt.Fprintln(os.Stderr, "bible-importer verify: -translation is required")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found, using system environment variables")
+	}
+	database.InitDB()
+
+	report, err := services.VerifyTranslation(database.GetDB(), *code)
+	if err != nil {
+		log.Fatalf("Verification failed: %v", err)
+	}
+	printReport(report, nil, *output)
+}
+
+func printReport(report services.TranslationReport, dropped []string, output string) {
+	if output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(struct {
+			services.TranslationReport
+			Dropped []string `json:"dropped"`
+		}{report, append([]string{}, dropped...)}); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+		return
+	}
+
+	fmt.Printf("\n%s: %d books, %d/%d verses\n", report.Translation, len(report.Books), report.Verses, report.ExpectedVerses)
+	fmt.Printf("%-8s %10s %12s\n", "Book", "Chapters", "Verses")
+	for _, b := range report.Books {
+		mark := "✓"
+		if !b.Complete() {
+			mark = "✗"
+		}
+		fmt.Printf("%-8s %4d/%-5d %6d/%-5d %s\n", b.Book, b.Chapters, b.ExpectedChapters, b.Verses, b.ExpectedVerses, mark)
+		if len(b.Incomplete) > 0 {
+			fmt.Printf("         incomplete chapters: %s\n", strings.Join(b.Incomplete, ", "))
+		}
+	}
+	if len(report.MissingBooks) > 0 {
+		fmt.Printf("Missing books (%d): %s\n", len(report.MissingBooks), strings.Join(report.MissingBooks, " "))
+	}
+
+	if len(dropped) == 0 {
+		return
+	}
+	// Verses of an unknown book share one reason; list each reason once
+	var reasons []string
+	counts := make(map[string]int)
+	for _, d := range dropped {
+		if counts[d] == 0 {
+			reasons = append(reasons, d)
+		}
+		counts[d]++
+	}
+	fmt.Printf("\nDropped %d verses:\n", len(dropped))
+	for i, reason := range reasons {
+		if i == maxDropped {
+			fmt.Printf("  ... and %d more\n", len(reasons)-maxDropped)
+			break
+		}
+		if counts[reason] > 1 {
+			fmt.Printf("  %s (%d verses)\n", reason, counts[reason])
+		} else {
+			fmt.Printf("  %s\n", reason)
+		}
+	}
+}
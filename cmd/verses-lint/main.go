@@ -1,354 +1,120 @@
-// ~/Documents/CODING/ubible/main.go
+// cmd/verses-lint - Offline validator for the theme files in ./verses
+//
+// Usage:
+//
+//	go run ./cmd/verses-lint                      # everything under ./verses
+//	go run ./cmd/verses-lint verses/Beards.txt    # specific files or directories
+//	go run ./cmd/verses-lint -format json -strict
+//
+// TXT verse lists are parsed exactly as the server loads them and JSON files
+// are checked against the VerseFile schema. It needs no database. The exit
+// code is 1 when any file has errors (or warnings, with -strict) and 2 when
+// the files can't be read.
 package main
 
 import (
-	"log"
-	"net/http"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
-	"time"
-	"ubible/database"
-	"ubible/handlers"
-	"ubible/middleware"
 	"ubible/services"
-
-	"github.com/joho/godotenv"
 )
 
-func main() {
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("Warning: .env file not found, using system environment variables")
-	}
-
-	// Validate critical environment variables
-	validateEnvironment()
-
-	// Initialize database
-	database.InitDB()
-
-	// Initialize team handlers
-	handlers.InitTeamHandlers()
-
-	// Load verses from files
-	log.Println("Loading verses from files...")
-	services.LoadVersesFromFiles()
-	services.LoadVersesFromTXT()
-
-	// Initialize cleanup service
-	services.InitCleanupService()
-	defer func() {
-		if cleanupService := services.GetCleanupService(); cleanupService != nil {
-			cleanupService.Stop()
-		}
-	}()
-
-	// HTTP Mux for REST API and HTML/static
-	mux := http.NewServeMux()
-
-	// Static files
-	// Directories
-	mux.Handle("/css/", http.StripPrefix("/css/", http.FileServer(http.Dir("./static/css"))))
-	mux.Handle("/js/", http.StripPrefix("/js/", http.FileServer(http.Dir("./static/js"))))
-	mux.Handle("/admin/", http.StripPrefix("/admin/", http.FileServer(http.Dir("./static/admin"))))
-	mux.Handle("/verses/", http.StripPrefix("/verses/", http.FileServer(http.Dir("./verses"))))
-	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("./static"))))
-
-	// HTML routes
-	mux.HandleFunc("/", serveFile("./static/index.html"))
-	mux.HandleFunc("/quiz", serveFile("./static/quiz.html"))
-	mux.HandleFunc("/quiz.html", serveFile("./static/quiz.html"))
-	mux.HandleFunc("/practice", serveFile("./static/practice.html"))
-	mux.HandleFunc("/practice.html", serveFile("./static/practice.html"))
-	mux.HandleFunc("/challenges", serveFile("./static/challenges.html"))
-	mux.HandleFunc("/challenges.html", serveFile("./static/challenges.html"))
-	mux.HandleFunc("/settings", serveFile("./static/settings.html"))
-	mux.HandleFunc("/settings.html", serveFile("./static/settings.html"))
-	mux.HandleFunc("/shop", serveFile("./static/shop.html"))
-	mux.HandleFunc("/shop.html", serveFile("./static/shop.html"))
-	mux.HandleFunc("/login", serveFile("./static/login.html"))
-	mux.HandleFunc("/login.html", serveFile("./static/login.html"))
-	mux.HandleFunc("/teams", serveFile("./static/teams.html"))
-	mux.HandleFunc("/teams.html", serveFile("./static/teams.html"))
-	mux.HandleFunc("/ai-theme-maker", serveFile("./static/ai-theme-maker.html"))
-	mux.HandleFunc("/ai-theme-maker.html", serveFile("./static/ai-theme-maker.html"))
-	mux.HandleFunc("/theme-maker", serveFile("./static/theme-maker.html"))
-	mux.HandleFunc("/theme-maker.html", serveFile("./static/theme-maker.html"))
-
-	// Health
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		writeJSON(w, http.StatusOK, map[string]any{
-			"status":    "healthy",
-			"timestamp": time.Now().Unix(),
-			"version":   "1.0.0",
-		})
-	})
-
-	// API routes (only wiring endpoints already using net/http signatures)
-	// Global API prefix helper
-	route := func(path string, h http.Handler) {
-		mux.Handle(path, h)
-	}
-
-	// Rate limits
-	globalRL := middleware.HTTPRateLimit(middleware.RateLimitConfig{
-		Requests:  300,
-		Burst:     60,
-		Window:    time.Minute,
-		KeyFunc:   middleware.IPKeyFunc,
-		BlockCode: http.StatusTooManyRequests,
-	})
-	authRL := middleware.HTTPRateLimit(middleware.RateLimitConfig{
-		Requests:  30,
-		Burst:     10,
-		Window:    time.Minute,
-		KeyFunc:   middleware.IPKeyFunc,
-		BlockCode: http.StatusTooManyRequests,
-	})
-
-	// CORS
-	corsOrigins := getEnv("CORS_ORIGINS", "http://localhost:3000")
-	allowed := splitAndTrim(corsOrigins)
-
-	// Helpers to build chains
-	chain := func(h http.Handler, mws ...func(http.Handler) http.Handler) http.Handler {
-		// Apply in reverse (outermost first in list)
-		for i := len(mws) - 1; i >= 0; i-- {
-			h = mws[i](h)
-		}
-		return h
-	}
-	mh := func(method string, fn http.HandlerFunc) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.Method == http.MethodOptions {
-				// Allow preflight through CORS middleware
-				w.WriteHeader(http.StatusNoContent)
-				return
-			}
-			if r.Method != method {
-				http.NotFound(w, r)
-				return
-			}
-			fn.ServeHTTP(w, r)
-		})
-	}
-
-	// Auth routes
-	route("/api/auth/guest", chain(
-		mh(http.MethodPost, handlers.GuestLogin),
-		authRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-	route("/api/auth/login", chain(
-		mh(http.MethodPost, handlers.Login),
-		authRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-	route("/api/auth/register", chain(
-		mh(http.MethodPost, handlers.Register),
-		authRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-	route("/api/auth/upgrade", chain(
-		middleware.AuthMiddleware(mh(http.MethodPost, handlers.UpgradeGuest)),
-		authRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-	route("/api/auth/preferences", chain(
-		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetPreferences)),
-		authRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-	route("/api/auth/preferences/save", chain( // if original was POST /preferences reuse same path
-		middleware.AuthMiddleware(mh(http.MethodPost, handlers.SavePreferences)),
-		authRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-
-	// Themes (only endpoints known to be net/http safe; comment others until migrated)
-	route("/api/themes", chain(
-		mh(http.MethodGet, handlers.GetThemes),
-		globalRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-	route("/api/themes/generate", chain(
-		mh(http.MethodPost, handlers.GenerateTheme),
-		globalRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-	route("/api/themes/public", chain(
-		mh(http.MethodPost, handlers.CreatePublicTheme),
-		globalRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-	// TODO: /api/themes/:id (requires param parsing in handler)
-
-	// Verses
-	route("/api/verses", chain(
-		mh(http.MethodGet, handlers.GetVerses),
-		globalRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-	// TODO: /api/verses/:id
-
-	// Quiz
-	route("/api/questions/quiz", chain(
-		mh(http.MethodGet, handlers.GetQuizQuestions),
-		globalRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-
-	// Practice
-	route("/api/practice/cards", chain(
-		mh(http.MethodGet, handlers.GetPracticeCards),
-		globalRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-
-	// Stats
-	route("/api/stats/players", chain(
-		mh(http.MethodGet, handlers.GetOnlinePlayersCount),
-		globalRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-	route("/api/stats/last-played", chain(
-		mh(http.MethodGet, handlers.GetLastPlayedTime),
-		globalRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-
-	// Debug
-	route("/api/debug/rooms", chain(
-		mh(http.MethodGet, handlers.GetActiveRooms),
-		globalRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-	route("/api/debug/sessions", chain(
-		mh(http.MethodGet, handlers.GetGameSessions),
-		globalRL,
-		middleware.HTTPCORSMiddleware(allowed),
-	))
-	// TODO: /api/debug/rooms/:code
+type summary struct {
+	Files    []services.LintReport `json:"files"`
+	Errors   int                   `json:"errors"`
+	Warnings int                   `json:"warnings"`
+}
 
-	// Wrap mux with global middlewares
-	rootHandler := chain(
-		mux,
-		globalRL,
-		middleware.HTTPLoggerMiddleware,
-		middleware.HTTPRecoverMiddleware,
-		middleware.HTTPCORSMiddleware(allowed), // also handle OPTIONS at root
-	)
+func main() {
+	format := flag.String("format", "human", "Output format: human or json")
+	strict := flag.Bool("strict", false, "Fail on warnings as well as errors")
+	flag.Parse()
 
-	// Start WebSocket server on port 4000 (pure net/http)
-	wsPort := getEnv("WS_PORT", "4000")
-	wsMux := http.NewServeMux()
-	wsMux.HandleFunc("/ws", handlers.WebSocketHandler)
-	wsServer := &http.Server{
-		Addr:    ":" + wsPort,
-		Handler: wsMux,
+	if *format != "human" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "verses-lint: unknown format %q (want human or json)\n", *format)
+		os.Exit(2)
 	}
-	go func() {
-		log.Printf("🌐 WebSocket server starting on port %s", wsPort)
-		if err := wsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal("WebSocket server failed:", err)
-		}
-	}()
 
-	// Start HTTP/REST server on port 3000
-	port := getEnv("PORT", "3000")
-	srv := &http.Server{
-		Addr:         ":" + port,
-		Handler:      rootHandler,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{services.VersesDirectory}
 	}
-
-	log.Printf("🚀 HTTP server starting on port %s", port)
-	log.Printf("📊 Environment: %s", getEnv("APP_ENV", "development"))
-	log.Printf("🔐 JWT Secret configured: %v", os.Getenv("JWT_SECRET") != "")
-	log.Printf("🧹 Guest cleanup: %s", getEnv("GUEST_CLEANUP_ENABLED", "true"))
-	log.Printf("🌐 WebSocket available at ws://localhost:%s/ws", wsPort)
-	log.Printf("✅ Quiz endpoint available at /api/questions/quiz")
-
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatal("Failed to start HTTP server:", err)
+	files, err := collectFiles(paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "verses-lint: %v\n", err)
+		os.Exit(2)
 	}
-}
 
-// validateEnvironment checks for required environment variables
-func validateEnvironment() {
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		log.Fatal("FATAL: JWT_SECRET environment variable must be set. Generate one with: openssl rand -base64 64")
-	}
-	if len(jwtSecret) < 32 {
-		log.Fatal("FATAL: JWT_SECRET must be at least 32 characters long")
+	var sum summary
+	for _, file := range files {
+		report := services.LintVerseFile(file)
+		sum.Errors += report.Count(services.LintError)
+		sum.Warnings += report.Count(services.LintWarning)
+		sum.Files = append(sum.Files, report)
 	}
 
-	appEnv := os.Getenv("APP_ENV")
-	if appEnv == "production" {
-		// Additional production checks
-		corsOrigins := os.Getenv("CORS_ORIGINS")
-		if corsOrigins == "" || corsOrigins == "http://localhost:3000" {
-			log.Println("WARNING: CORS_ORIGINS not properly configured for production")
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(sum); err != nil {
+			fmt.Fprintf(os.Stderr, "verses-lint: %v\n", err)
+			os.Exit(2)
 		}
+	} else {
+		printHuman(sum)
 	}
-}
 
-// Helpers
-
-func serveFile(filepath string) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, filepath)
+	if sum.Errors > 0 || (*strict && sum.Warnings > 0) {
+		os.Exit(1)
 	}
 }
 
-func writeJSON(w http.ResponseWriter, code int, v any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	_ = jsonEncode(w, v)
-}
-
-func jsonEncode(w http.ResponseWriter, v any) error {
-	// small local encoder to avoid importing encoding/json everywhere in this file
-	type jsonMarshaler interface {
-		MarshalJSON() ([]byte, error)
-	}
-	// fallback to standard library
-	return (&jsonEncoder{w: w}).Encode(v)
-}
-
-type jsonEncoder struct {
-	w http.ResponseWriter
-}
-
-func (e *jsonEncoder) Encode(v any) error {
-	// defer to stdlib
-	return json.NewEncoder(e.w).Encode(v)
-}
-
-func getEnv(key, defaultVal string) string {
-	if val := os.Getenv(key); val != "" {
-		return val
+// collectFiles expands directories into the .txt and .json files under them
+func collectFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			ext := strings.ToLower(filepath.Ext(p))
+			if !d.IsDir() && (ext == ".txt" || ext == ".json") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
-	return defaultVal
+	sort.Strings(files)
+	return files, nil
 }
 
-func splitAndTrim(s string) []string {
-	if s == "" {
-		return nil
-	}
-	parts := strings.Split(s, ",")
-	out := make([]string, 0, len(parts))
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		if p != "" {
-			out = append(out, p)
+func printHuman(sum summary) {
+	checked := 0
+	for _, report := range sum.Files {
+		if report.Kind == "bible" {
+			fmt.Printf("%s: skipped (Bible text)\n", report.File)
+			continue
+		}
+		checked++
+		for _, issue := range report.Issues {
+			fmt.Println(issue)
 		}
 	}
-	return out
+	fmt.Printf("\n%d files checked: %d errors, %d warnings\n", checked, sum.Errors, sum.Warnings)
 }
-
-// Local import of encoding/json to keep helpers above minimal
-// (kept at bottom to avoid cluttering imports at the top)
-import "encoding/json"
\ No newline at end of file
@@ -1,19 +1,44 @@
-// database/ubible_migration.go
+// database/bible_migration.go - Shared Bible text store
 package database
 
 import (
+	"ubible/models"
+	"ubible/reference"
+
 	"gorm.io/gorm"
 )
 
-type UBibleVerse struct {
-	ID          uint   `gorm:"primaryKey"`
-	Book        string `gorm:"index;not null"`
-	Chapter     int    `gorm:"index;not null"`
-	Verse       int    `gorm:"index;not null"`
-	Text        string `gorm:"type:text;not null"`
-	Translation string `gorm:"index;default:'KJV'"`
-}
+// MigrateBibleVerses creates the translation and verse tables. Verses written
+// by the old json-importer keyed books by English name and could be imported
+// twice; they are moved to OSIS book IDs and de-duplicated before the unique
+// index is built. Each translation they hold gets a translation row named after
+// its code, so the store lists and loads it.
+func MigrateBibleVerses(db *gorm.DB) error {
+	legacy := db.Migrator().HasTable(&models.BibleVerse{})
+	if legacy {
+		for _, book := range reference.Canon {
+			if err := db.Exec("UPDATE bible_verses SET book = ? WHERE book = ?", book.ID, book.Name).Error; err != nil {
+				return err
+			}
+		}
+		if err := db.Exec(`DELETE FROM bible_verses a USING bible_verses b
+			WHERE a.id > b.id AND a.translation = b.translation AND a.book = b.book
+			AND a.chapter = b.chapter AND a.verse = b.verse`).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := db.AutoMigrate(
+		&models.BibleTranslation{},
+		&models.BibleVerse{},
+	); err != nil {
+		return err
+	}
 
-func MigrateUBibleVerses(db *gorm.DB) error {
-	return db.AutoMigrate(&UBibleVerse{})
+	if !legacy {
+		return nil
+	}
+	return db.Exec(`INSERT INTO bible_translations (code, name, language, created_at, updated_at)
+		SELECT DISTINCT translation, translation, 'en', NOW(), NOW() FROM bible_verses
+		ON CONFLICT (code) DO NOTHING`).Error
 }
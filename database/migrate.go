@@ -2,8 +2,12 @@
 package database
 
 import (
+	"fmt"
 	"log"
+	"strings"
 	"ubible/models"
+
+	"gorm.io/gorm"
 )
 
 // RunMigrations runs all database migrations
@@ -21,6 +25,7 @@ func RunMigrations() {
 		&models.UserAchievement{},
 		&models.Friend{},
 		&models.FriendRequest{},
+		&models.UserBlock{},
 		&models.PowerUp{},
 	); err != nil {
 		log.Fatalf("❌ Failed to run core migrations: %v", err)
@@ -28,6 +33,18 @@ func RunMigrations() {
 
 	log.Println("✅ Core migrations completed")
 
+	// Achievements unlock once per user; grants rely on this index
+	if err := createUniqueIndex(db, "idx_user_achievements_unique", "user_achievements", "user_id", "achievement_id"); err != nil {
+		log.Fatalf("❌ Failed to create achievement unlock index: %v", err)
+	}
+
+	// Ratings moved from the old 0-10 scale to Glicko-2 (1500 = average)
+	if err := runOnce(db, "rating_glicko2_scale", func(tx *gorm.DB) error {
+		return tx.Exec("UPDATE users SET rating = 1500 + (rating - 5.0) * 100 WHERE rating <= 10").Error
+	}); err != nil {
+		log.Fatalf("❌ Failed to rescale ratings: %v", err)
+	}
+
 	// Multiplayer tracking models - all relationships removed from gorm tags to avoid circular dependencies
 	if err := db.AutoMigrate(
 		&models.MultiplayerGame{},
@@ -39,17 +56,111 @@ func RunMigrations() {
 
 	log.Println("✅ Multiplayer tracking migrations completed")
 
+	// Live game checkpoints for crash recovery
+	if err := db.AutoMigrate(&models.ActiveGameState{}); err != nil {
+		log.Fatalf("❌ Failed to run game state migrations: %v", err)
+	}
+
+	// Faith Points ledger and shop unlocks
+	if err := db.AutoMigrate(
+		&models.FaithPointTransaction{},
+		&models.ThemeUnlock{},
+	); err != nil {
+		log.Fatalf("❌ Failed to run shop migrations: %v", err)
+	}
+
+	// Daily and weekly challenges
+	if err := db.AutoMigrate(
+		&models.DailyChallenge{},
+		&models.DailyChallengeAttempt{},
+	); err != nil {
+		log.Fatalf("❌ Failed to run daily challenge migrations: %v", err)
+	}
+
+	// Seasons and archived standings
+	if err := db.AutoMigrate(
+		&models.Season{},
+		&models.SeasonStat{},
+		&models.SeasonStanding{},
+	); err != nil {
+		log.Fatalf("❌ Failed to run season migrations: %v", err)
+	}
+
+	// Notifications and mute preferences
+	if err := db.AutoMigrate(
+		&models.Notification{},
+		&models.NotificationMute{},
+	); err != nil {
+		log.Fatalf("❌ Failed to run notification migrations: %v", err)
+	}
+
+	// Bible text store (all translations)
+	if err := MigrateBibleVerses(db); err != nil {
+		log.Fatalf("❌ Failed to run Bible text migrations: %v", err)
+	}
+
 	// Run Team Portal migrations
 	if err := RunTeamMigrations(db); err != nil {
 		log.Fatalf("❌ Failed to run team migrations: %v", err)
 	}
 
+	// Friendships are stored once per direction
+	if err := createUniqueIndex(db, "idx_friends_pair", "friends", "user_id", "friend_id"); err != nil {
+		log.Fatalf("❌ Failed to create friendship index: %v", err)
+	}
+
+	// At most one pending request per pair of users, whoever sent it
+	if err := createPendingRequestIndex(db); err != nil {
+		log.Fatalf("❌ Failed to create friend request index: %v", err)
+	}
+
 	// Create indexes for core tables
 	createCoreIndexes()
 
 	log.Println("✅ All migrations completed successfully")
 }
 
+// createUniqueIndex builds a unique index on table(columns). Rows that would
+// break it are deleted first, keeping the oldest of each duplicate group.
+func createUniqueIndex(db *gorm.DB, name, table string, columns ...string) error {
+	if db.Migrator().HasIndex(table, name) {
+		return nil
+	}
+
+	match := make([]string, len(columns))
+	for i, c := range columns {
+		match[i] = fmt.Sprintf("a.%s = b.%s", c, c)
+	}
+	if err := db.Exec(fmt.Sprintf("DELETE FROM %s a USING %s b WHERE a.id > b.id AND %s",
+		table, table, strings.Join(match, " AND "))).Error; err != nil {
+		return fmt.Errorf("failed to remove duplicates from %s: %w", table, err)
+	}
+
+	return db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s(%s)",
+		name, table, strings.Join(columns, ", "))).Error
+}
+
+// createPendingRequestIndex builds the unique index over the unordered pair of
+// a pending friend request. Older duplicates are cancelled first, keeping the
+// first request sent.
+func createPendingRequestIndex(db *gorm.DB) error {
+	const name = "idx_friend_requests_pending_pair"
+	if db.Migrator().HasIndex(&models.FriendRequest{}, name) {
+		return nil
+	}
+
+	if err := db.Exec(`UPDATE friend_requests a SET status = 'cancelled'
+		FROM friend_requests b
+		WHERE a.status = 'pending' AND b.status = 'pending' AND a.id > b.id
+		AND LEAST(a.from_user_id, a.to_user_id) = LEAST(b.from_user_id, b.to_user_id)
+		AND GREATEST(a.from_user_id, a.to_user_id) = GREATEST(b.from_user_id, b.to_user_id)`).Error; err != nil {
+		return fmt.Errorf("failed to cancel duplicate friend requests: %w", err)
+	}
+
+	return db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS ` + name + ` ON friend_requests
+		(LEAST(from_user_id, to_user_id), GREATEST(from_user_id, to_user_id)) WHERE status = 'pending'`).Error
+}
+
 // createCoreIndexes creates indexes for core tables
 func createCoreIndexes() {
 	db := GetDB()
@@ -120,5 +231,8 @@ func createMultiplayerIndexes() {
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_mp_events_sequence ON multiplayer_game_events(game_id, sequence_num)")
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_mp_events_composite ON multiplayer_game_events(game_id, event_type, timestamp)")
 
+	// ActiveGameState indexes
+	db.Exec("CREATE INDEX IF NOT EXISTS idx_active_game_states_status ON active_game_states(status, expires_at)")
+
 	log.Println("✅ Multiplayer tracking indexes created successfully")
 }
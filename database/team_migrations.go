@@ -67,6 +67,7 @@ func createTeamIndexes(db *gorm.DB) error {
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_challenge_participants_challenge ON challenge_participants(challenge_id)")
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_challenge_participants_user ON challenge_participants(user_id)")
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_challenge_participants_score ON challenge_participants(score DESC)")
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_challenge_participants_unique ON challenge_participants(challenge_id, user_id)")
 
 	log.Println("✅ Team Portal indexes created successfully")
 	return nil
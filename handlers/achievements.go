@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"log"
+	"ubible/models"
+	"ubible/services"
+)
+
+// awardAchievements evaluates achievement rules for a user after an event and
+// notifies them of anything newly unlocked
+func awardAchievements(userID uint, event services.AchievementEvent) {
+	unlocked, err := services.Achievements.Evaluate(userID, event)
+	if err != nil {
+		log.Printf("⚠️  Achievement check for user %d: %v", userID, err)
+		return
+	}
+
+	for _, a := range unlocked {
+		payload := map[string]interface{}{
+			"achievement_id":   a.ID,
+			"name":             a.Name,
+			"description":      a.Description,
+			"category":         a.Category,
+			"tier":             a.Tier,
+			"icon":             a.Icon,
+			"xp_reward":        a.XPReward,
+			"fp_reward":        a.FPReward,
+			"powerup_reward":   a.PowerUpReward,
+			"powerup_quantity": a.PowerUpQuantity,
+		}
+		services.Notifications.Notify(userID, models.NotificationCategoryAchievement, "achievement_unlocked",
+			"Achievement unlocked: "+a.Name, a.Description, payload)
+	}
+}
+
+// notifyUser sends a message to every WebSocket connection a signed-in user has open
+func notifyUser(userID uint, msgType string, payload interface{}) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, p := range players {
+		p.mu.RLock()
+		match := p.UserID != nil && *p.UserID == userID
+		p.mu.RUnlock()
+		if match {
+			p.sendMessage(msgType, payload)
+		}
+	}
+}
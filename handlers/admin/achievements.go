@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"ubible/database"
 	"ubible/models"
+	"ubible/services"
 	"ubible/utils"
 )
 
@@ -30,6 +31,11 @@ func CreateAchievement(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, err := services.Achievements.ParseCriteria(achievement.Criteria); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	if err := db.Create(&achievement).Error; err != nil {
 		utils.JSONError(w, http.StatusInternalServerError, "Failed to create achievement")
 		return
@@ -54,6 +60,11 @@ func UpdateAchievement(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, err := services.Achievements.ParseCriteria(achievement.Criteria); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	if err := db.Save(&achievement).Error; err != nil {
 		utils.JSONError(w, http.StatusInternalServerError, "Failed to update achievement")
 		return
@@ -1,7 +1,12 @@
 package admin
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"ubible/database"
+	"ubible/middleware"
+	"ubible/models"
 	"ubible/services"
 	"ubible/utils"
 )
@@ -19,18 +24,124 @@ func GetCleanupStats(w http.ResponseWriter, r *http.Request) {
 	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "stats": map[string]interface{}{}})
 }
 
+// GetChallenges returns team challenges with pagination, optionally filtered by status
 func GetChallenges(w http.ResponseWriter, r *http.Request) {
-	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "challenges": []interface{}{}})
+	db := database.GetDB()
+
+	page, err := strconv.Atoi(utils.Query(r, "page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(utils.Query(r, "limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+
+	query := db.Model(&models.Challenge{})
+	if status := utils.Query(r, "status", ""); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var challenges []models.Challenge
+	if err := query.Preload("Team").Preload("Theme").Order("start_date DESC").
+		Offset((page - 1) * limit).Limit(limit).Find(&challenges).Error; err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch challenges")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"challenges": challenges,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+	})
 }
 
+// CreateChallenge schedules a challenge for any team
 func CreateChallenge(w http.ResponseWriter, r *http.Request) {
-	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true})
+	adminID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req struct {
+		TeamID uint `json:"team_id"`
+		services.ChallengeInput
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var team models.Team
+	if err := database.GetDB().Where("id = ? AND is_active = ?", req.TeamID, true).First(&team).Error; err != nil {
+		utils.JSONError(w, http.StatusNotFound, "Team not found")
+		return
+	}
+
+	challenge, err := services.Challenges.Create(team.ID, adminID, req.ChallengeInput)
+	if err != nil {
+		writeChallengeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusCreated, challenge)
 }
 
+// UpdateChallenge edits a challenge that hasn't started
 func UpdateChallenge(w http.ResponseWriter, r *http.Request) {
-	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true})
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid challenge ID")
+		return
+	}
+
+	var req services.ChallengeInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	challenge, err := services.Challenges.Update(uint(id), req)
+	if err != nil {
+		writeChallengeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, challenge)
 }
 
+// DeleteChallenge cancels a pending or active challenge. Finished challenges
+// are kept for their results.
 func DeleteChallenge(w http.ResponseWriter, r *http.Request) {
-	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true})
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid challenge ID")
+		return
+	}
+
+	if err := services.Challenges.Cancel(uint(id), "cancelled by an administrator"); err != nil {
+		writeChallengeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "message": "Challenge cancelled"})
+}
+
+func writeChallengeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrChallengeNotFound):
+		utils.JSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidChallenge):
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrChallengeStarted), errors.Is(err, services.ErrChallengeClosed):
+		utils.JSONError(w, http.StatusConflict, err.Error())
+	default:
+		utils.JSONError(w, http.StatusInternalServerError, "Challenge request failed")
+	}
 }
@@ -0,0 +1,83 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"ubible/middleware"
+	"ubible/services"
+	"ubible/utils"
+)
+
+// GetDailyChallenges returns stored daily and weekly challenges, newest first,
+// optionally filtered by `period`
+func GetDailyChallenges(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(utils.Query(r, "page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(utils.Query(r, "limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+
+	challenges, total, err := services.DailyChallenges.List(utils.Query(r, "period", ""), limit, (page-1)*limit)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch challenges")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"challenges": challenges,
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+	})
+}
+
+// PinDailyChallenge fixes the question set for a daily or weekly period ahead
+// of time, or replaces one nobody has played yet
+func PinDailyChallenge(w http.ResponseWriter, r *http.Request) {
+	adminID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req services.DailyPin
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	challenge, err := services.DailyChallenges.Pin(r.PathValue("period"), r.PathValue("key"), adminID, req)
+	if err != nil {
+		writeDailyChallengeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, challenge)
+}
+
+// UnpinDailyChallenge drops an unplayed override so the period's set is
+// derived from its seed again
+func UnpinDailyChallenge(w http.ResponseWriter, r *http.Request) {
+	if err := services.DailyChallenges.Unpin(r.PathValue("period"), r.PathValue("key")); err != nil {
+		writeDailyChallengeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "message": "Challenge unpinned"})
+}
+
+func writeDailyChallengeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrUnknownPeriod), errors.Is(err, services.ErrInvalidPeriodKey),
+		errors.Is(err, services.ErrPeriodBeforeLaunch), errors.Is(err, services.ErrNoDailyQuestions):
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrDailyChallengePlayed):
+		utils.JSONError(w, http.StatusConflict, err.Error())
+	default:
+		utils.JSONError(w, http.StatusInternalServerError, "Challenge request failed")
+	}
+}
@@ -0,0 +1,35 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"ubible/services"
+	"ubible/utils"
+)
+
+// GetGameReplay rebuilds any stored multiplayer game, finished or not, with
+// its raw event log, for investigating reports
+func GetGameReplay(w http.ResponseWriter, r *http.Request) {
+	gameID := r.PathValue("id")
+
+	replay, err := services.Replays.Build(gameID)
+	if err != nil {
+		if errors.Is(err, services.ErrReplayNotFound) {
+			utils.JSONError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to build replay")
+		return
+	}
+
+	events, err := services.MultiplayerDB.GetGameEvents(gameID)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch events")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"replay": replay,
+		"events": events,
+	})
+}
@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"ubible/middleware"
+	"ubible/models"
+	"ubible/services"
+	"ubible/utils"
+)
+
+// GetTeamThemeReviews returns team themes by publish status (default pending)
+func GetTeamThemeReviews(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(utils.Query(r, "page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(utils.Query(r, "limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	status := utils.Query(r, "status", models.ThemePublishPending)
+
+	themes, total, err := services.TeamThemes.ListForReview(status, limit, (page-1)*limit)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch team themes")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"themes": themes,
+		"total":  total,
+		"page":   page,
+		"limit":  limit,
+	})
+}
+
+// ApproveTeamTheme publishes a pending team theme into the global catalog
+func ApproveTeamTheme(w http.ResponseWriter, r *http.Request) {
+	reviewTeamTheme(w, r, true)
+}
+
+// RejectTeamTheme declines a pending team theme with an optional `note`
+func RejectTeamTheme(w http.ResponseWriter, r *http.Request) {
+	reviewTeamTheme(w, r, false)
+}
+
+func reviewTeamTheme(w http.ResponseWriter, r *http.Request, approve bool) {
+	adminID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid theme ID")
+		return
+	}
+
+	var req struct {
+		Note string `json:"note"`
+	}
+	utils.ParseJSON(r, &req)
+
+	theme, err := services.TeamThemes.Review(uint(id), adminID, approve, req.Note)
+	switch {
+	case err == nil:
+		utils.JSON(w, http.StatusOK, theme)
+	case errors.Is(err, services.ErrTeamThemeNotFound):
+		utils.JSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidTeamTheme):
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrThemeNotInReview):
+		utils.JSONError(w, http.StatusConflict, err.Error())
+	default:
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to review team theme")
+	}
+}
@@ -1,13 +1,18 @@
 package admin
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"ubible/database"
+	"ubible/middleware"
 	"ubible/models"
+	"ubible/services"
 	"ubible/utils"
 
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // GetUsers returns all users with pagination
@@ -72,8 +77,8 @@ func GetUser(w http.ResponseWriter, r *http.Request) {
 	utils.JSON(w, http.StatusOK, user)
 }
 
-// UpdateUser updates a user's information
-func UpdateUser(w http.ResponseWriter, r *http.Request) {
+// GetUserLedger returns a user's Faith Points ledger so support can explain their balance
+func GetUserLedger(w http.ResponseWriter, r *http.Request) {
 	db := database.GetDB()
 	id := r.PathValue("id")
 
@@ -83,15 +88,47 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, err := strconv.Atoi(utils.Query(r, "page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(utils.Query(r, "limit", "100"))
+	if err != nil || limit < 1 {
+		limit = 100
+	}
+
+	entries, total, err := services.FaithPoints.History(user.ID, limit, (page-1)*limit)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch ledger")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"user_id":      user.ID,
+		"username":     user.Username,
+		"faith_points": user.FaithPoints,
+		"transactions": entries,
+		"total":        total,
+		"page":         page,
+		"limit":        limit,
+	})
+}
+
+// UpdateUser updates a user's information. Only the fields present in the
+// request are changed; a Faith Points edit is applied as a ledger adjustment.
+func UpdateUser(w http.ResponseWriter, r *http.Request) {
+	db := database.GetDB()
+	id := r.PathValue("id")
+
 	// Parse update data
 	var updateData struct {
 		Username    string `json:"username"`
 		Email       string `json:"email"`
-		Level       int    `json:"level"`
-		XP          int    `json:"xp"`
-		FaithPoints int    `json:"faith_points"`
-		IsAdmin     bool   `json:"is_admin"`
-		IsBanned    bool   `json:"is_banned"`
+		Level       *int   `json:"level"`
+		XP          *int   `json:"xp"`
+		FaithPoints *int   `json:"faith_points"`
+		IsAdmin     *bool  `json:"is_admin"`
+		IsBanned    *bool  `json:"is_banned"`
 	}
 
 	if err := utils.ParseJSON(r, &updateData); err != nil {
@@ -99,27 +136,60 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update fields
+	// Collect the edited columns
+	updates := map[string]interface{}{}
 	if updateData.Username != "" {
-		user.Username = updateData.Username
+		updates["username"] = updateData.Username
 	}
 	if updateData.Email != "" {
-		email := updateData.Email
-		user.Email = &email
+		updates["email"] = updateData.Email
 	}
-	if updateData.Level > 0 {
-		user.Level = updateData.Level
+	if updateData.Level != nil && *updateData.Level > 0 {
+		updates["level"] = *updateData.Level
 	}
-	if updateData.XP >= 0 {
-		user.XP = updateData.XP
+	if updateData.XP != nil && *updateData.XP >= 0 {
+		updates["xp"] = *updateData.XP
 	}
-	if updateData.FaithPoints >= 0 {
-		user.FaithPoints = updateData.FaithPoints
+	if updateData.IsAdmin != nil {
+		updates["is_admin"] = *updateData.IsAdmin
+	}
+	if updateData.IsBanned != nil {
+		updates["is_banned"] = *updateData.IsBanned
 	}
-	user.IsAdmin = updateData.IsAdmin
-	user.IsBanned = updateData.IsBanned
 
-	if err := db.Save(&user).Error; err != nil {
+	// Manual balance edits go through the ledger like any other FP change
+	reference := ""
+	if adminID, err := middleware.GetUserID(r); err == nil {
+		reference = "admin:" + strconv.FormatUint(uint64(adminID), 10)
+	}
+
+	var user models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, id).Error; err != nil {
+			return err
+		}
+
+		if len(updates) > 0 {
+			if err := tx.Model(&user).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		if updateData.FaithPoints != nil && *updateData.FaithPoints >= 0 {
+			if delta := *updateData.FaithPoints - user.FaithPoints; delta != 0 {
+				if _, err := services.FaithPoints.Adjust(tx, user.ID, delta, models.FPReasonAdminAdjustment, reference); err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.First(&user, user.ID).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.JSONError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
 		utils.JSONError(w, http.StatusInternalServerError, "Failed to update user")
 		return
 	}
@@ -0,0 +1,61 @@
+// handlers/bible.go - Bible translations and choosing one per request
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"ubible/database"
+	"ubible/middleware"
+	"ubible/models"
+	"ubible/services"
+	"ubible/utils"
+)
+
+// GetBibleTranslations lists the translations questions can be played in
+// GET /api/bible/translations
+func GetBibleTranslations(w http.ResponseWriter, r *http.Request) {
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":      true,
+		"translations": services.Bible.Translations(),
+		"default":      services.DefaultTranslation,
+	})
+}
+
+// requestTranslation picks the translation to play questions in: the one
+// asked for, else the signed-in user's preferred translation, else "" to play
+// questions as they were written
+func requestTranslation(r *http.Request, asked string) (string, error) {
+	asked = strings.ToUpper(strings.TrimSpace(asked))
+	if asked != "" {
+		if !services.Bible.Has(asked) {
+			return "", services.ErrUnknownTranslation
+		}
+		return asked, nil
+	}
+
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		return "", nil
+	}
+	var user models.User
+	if err := database.GetDB().Select("preferred_translation").First(&user, userID).Error; err != nil {
+		return "", nil
+	}
+	if user.PreferredTranslation != "" && !services.Bible.Has(user.PreferredTranslation) {
+		// The translation was removed from the store since it was chosen
+		return "", nil
+	}
+	return user.PreferredTranslation, nil
+}
+
+func writeBibleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrUnknownTranslation):
+		utils.JSONError(w, http.StatusBadRequest, "Unknown translation")
+	default:
+		log.Printf("⚠️  Bible request failed: %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Bible request failed")
+	}
+}
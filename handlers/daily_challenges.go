@@ -0,0 +1,243 @@
+// handlers/daily_challenges.go - Daily and weekly challenge HTTP handlers
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"ubible/database"
+	"ubible/middleware"
+	"ubible/models"
+	"ubible/services"
+	"ubible/utils"
+
+	"github.com/google/uuid"
+)
+
+// GetDailyChallenge returns the current (or `key`) challenge for a period with
+// the user's attempt and daily streak
+// GET /api/daily-challenges/{period}?key=
+func GetDailyChallenge(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	challenge, ok := dailyChallengeParam(w, r)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	database.GetDB().Select("id", "daily_streak", "best_daily_streak", "last_daily_challenge").First(&user, userID)
+
+	response := dailyChallengeSummary(*challenge)
+	response["success"] = true
+	response["daily_streak"] = services.DailyChallenges.DailyStreak(user, time.Now())
+	response["best_daily_streak"] = user.BestDailyStreak
+	if attempt := services.DailyChallenges.Attempt(challenge.ID, userID); attempt != nil {
+		response["my_attempt"] = attempt
+		if attempt.Status == "completed" {
+			response["my_rank"] = services.DailyChallenges.Rank(*attempt)
+		}
+	}
+
+	utils.JSON(w, http.StatusOK, response)
+}
+
+// StartDailyChallenge opens the user's one attempt at the current period's
+// challenge as a quiz session, or resumes it. Answers and finishing go
+// through the /api/quiz/sessions endpoints.
+// POST /api/daily-challenges/{period}/start
+func StartDailyChallenge(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	period := r.PathValue("period")
+	challenge, err := services.DailyChallenges.Current(period, time.Now())
+	if err != nil {
+		writeDailyChallengeError(w, err)
+		return
+	}
+
+	sessionID := uuid.NewString()
+	attempt, started, err := services.DailyChallenges.BeginAttempt(challenge.ID, userID, sessionID)
+	if err != nil {
+		writeDailyChallengeError(w, err)
+		return
+	}
+
+	if !started {
+		session, err := services.GameState.GetSoloSession(attempt.SessionID, userID)
+		if err != nil || session.Status != "active" || time.Now().After(session.ExpiresAt) {
+			writeDailyChallengeError(w, services.ErrDailyAttemptUsed)
+			return
+		}
+		utils.JSON(w, http.StatusOK, quizSessionView(session))
+		return
+	}
+
+	questions, err := challenge.GetQuestions()
+	if err != nil || len(questions) == 0 {
+		services.DailyChallenges.AbandonAttempt(attempt.ID)
+		writeDailyChallengeError(w, services.ErrNoDailyQuestions)
+		return
+	}
+
+	// The session can't outlive the period
+	expiresAt := time.Now().Add(soloSessionTTL)
+	if _, end, err := services.DailyChallenges.PeriodBounds(challenge.Period, challenge.PeriodKey); err == nil && end.Before(expiresAt) {
+		expiresAt = end
+	}
+
+	session, err := createSoloSession(userID, sessionID, questions, challenge.TimeLimit, nil, nil, &challenge.ID, expiresAt)
+	if err != nil {
+		log.Printf("⚠️  Failed to create %s challenge session for user %d: %v", period, userID, err)
+		services.DailyChallenges.AbandonAttempt(attempt.ID)
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to start challenge")
+		return
+	}
+
+	log.Printf("📅 User %d started %s challenge %s (session %s)", userID, period, challenge.PeriodKey, sessionID)
+	utils.JSON(w, http.StatusOK, quizSessionView(session))
+}
+
+// GetDailyChallengeLeaderboard ranks a period's completed attempts by score,
+// then time taken
+// GET /api/daily-challenges/{period}/leaderboard?key=&page=&limit=
+func GetDailyChallengeLeaderboard(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	challenge, ok := dailyChallengeParam(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := strconv.Atoi(utils.Query(r, "page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(utils.Query(r, "limit", "50"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	attempts, total, err := services.DailyChallenges.Leaderboard(challenge.ID, limit, (page-1)*limit)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch leaderboard")
+		return
+	}
+
+	entries := make([]map[string]interface{}, len(attempts))
+	for i, a := range attempts {
+		entry := map[string]interface{}{
+			"rank":            (page-1)*limit + i + 1,
+			"user_id":         a.UserID,
+			"score":           a.Score,
+			"correct_answers": a.CorrectAnswers,
+			"total_questions": a.TotalQuestions,
+			"time_spent":      a.TimeSpent,
+			"completed_at":    a.CompletedAt,
+		}
+		if a.User != nil {
+			entry["username"] = a.User.Username
+			entry["avatar"] = a.User.Avatar
+		}
+		entries[i] = entry
+	}
+
+	response := map[string]interface{}{
+		"success":     true,
+		"challenge":   dailyChallengeSummary(*challenge),
+		"leaderboard": entries,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+	}
+	if attempt := services.DailyChallenges.Attempt(challenge.ID, userID); attempt != nil && attempt.Status == "completed" {
+		response["my_rank"] = services.DailyChallenges.Rank(*attempt)
+		response["my_score"] = attempt.Score
+	}
+
+	utils.JSON(w, http.StatusOK, response)
+}
+
+// ================== HELPERS ==================
+
+// dailyChallengeParam loads the {period} challenge named by `key`, or the
+// current one. Future periods stay hidden until they open.
+func dailyChallengeParam(w http.ResponseWriter, r *http.Request) (*models.DailyChallenge, bool) {
+	period := r.PathValue("period")
+	now := time.Now()
+
+	key := utils.Query(r, "key", "")
+	if key == "" {
+		challenge, err := services.DailyChallenges.Current(period, now)
+		if err != nil {
+			writeDailyChallengeError(w, err)
+			return nil, false
+		}
+		return challenge, true
+	}
+
+	start, _, err := services.DailyChallenges.PeriodBounds(period, key)
+	if err != nil {
+		writeDailyChallengeError(w, err)
+		return nil, false
+	}
+	if start.After(now) {
+		utils.JSONError(w, http.StatusNotFound, "Challenge not available yet")
+		return nil, false
+	}
+
+	challenge, err := services.DailyChallenges.Get(period, key)
+	if err != nil {
+		writeDailyChallengeError(w, err)
+		return nil, false
+	}
+	return challenge, true
+}
+
+// dailyChallengeSummary is a challenge without its question set
+func dailyChallengeSummary(c models.DailyChallenge) map[string]interface{} {
+	summary := map[string]interface{}{
+		"id":             c.ID,
+		"period":         c.Period,
+		"period_key":     c.PeriodKey,
+		"title":          c.Title,
+		"description":    c.Description,
+		"time_limit":     c.TimeLimit,
+		"question_count": c.QuestionCount,
+		"is_pinned":      c.IsPinned,
+	}
+	if start, end, err := services.DailyChallenges.PeriodBounds(c.Period, c.PeriodKey); err == nil {
+		summary["starts_at"] = start
+		summary["ends_at"] = end
+	}
+	return summary
+}
+
+func writeDailyChallengeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrUnknownPeriod), errors.Is(err, services.ErrInvalidPeriodKey):
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrNoDailyQuestions), errors.Is(err, services.ErrDailyAttemptNotFound),
+		errors.Is(err, services.ErrPeriodBeforeLaunch):
+		utils.JSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrDailyAttemptUsed), errors.Is(err, services.ErrDailyChallengePlayed):
+		utils.JSONError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("⚠️  Daily challenge request failed: %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Challenge request failed")
+	}
+}
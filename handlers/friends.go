@@ -0,0 +1,609 @@
+// handlers/friends.go - Friends, blocking, presence and room invites
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"ubible/database"
+	"ubible/middleware"
+	"ubible/models"
+	"ubible/services"
+	"ubible/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	presenceWindow = 5 * time.Minute // Recent HTTP activity counts as online, as in /api/stats/players
+	roomInviteTTL  = 2 * time.Minute
+)
+
+// Presence statuses
+const (
+	PresenceOffline = "offline"
+	PresenceOnline  = "online"
+	PresenceInGame  = "in_game"
+)
+
+// ================== FRIENDS ENDPOINTS ==================
+
+// GetFriends lists the user's friends with presence and mutual friend counts
+// GET /api/friends
+func GetFriends(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	friends, err := services.Friends.List(userID)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch friends")
+		return
+	}
+
+	connected := connectedUsers()
+	online := 0
+	entries := make([]map[string]interface{}, len(friends))
+	for i, f := range friends {
+		status := presenceOf(f.UserID, f.LastActivity, connected)
+		if status != PresenceOffline {
+			online++
+		}
+		entries[i] = map[string]interface{}{
+			"user_id":        f.UserID,
+			"username":       f.Username,
+			"display_name":   f.DisplayName,
+			"avatar":         f.Avatar,
+			"level":          f.Level,
+			"rating":         f.Rating,
+			"presence":       status,
+			"last_activity":  f.LastActivity,
+			"mutual_friends": f.MutualFriends,
+			"friends_since":  f.FriendsSince,
+		}
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"friends": entries,
+		"total":   len(entries),
+		"online":  online,
+	})
+}
+
+// GetFriendRequests returns the user's pending incoming and outgoing requests
+// GET /api/friends/requests
+func GetFriendRequests(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	incoming, outgoing, err := services.Friends.Requests(userID)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch friend requests")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":  true,
+		"incoming": incoming,
+		"outgoing": outgoing,
+	})
+}
+
+// SendFriendRequest asks another user to be friends, by `friend_id` or
+// `username`. A request the other user already sent is accepted instead.
+// POST /api/friends/request
+func SendFriendRequest(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req struct {
+		FriendID uint   `json:"friend_id"`
+		Username string `json:"username"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+
+	db := database.GetDB()
+
+	var sender models.User
+	if err := db.Select("id", "username", "avatar", "is_guest").First(&sender, userID).Error; err != nil {
+		utils.JSONError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	if sender.IsGuest {
+		writeFriendError(w, services.ErrFriendGuest)
+		return
+	}
+	if req.FriendID == 0 && req.Username != "" {
+		var target models.User
+		if err := db.Select("id").Where("username = ?", req.Username).First(&target).Error; err != nil {
+			writeFriendError(w, services.ErrFriendUserNotFound)
+			return
+		}
+		req.FriendID = target.ID
+	}
+
+	request, accepted, err := services.Friends.SendRequest(userID, req.FriendID)
+	if err != nil {
+		writeFriendError(w, err)
+		return
+	}
+
+	if accepted {
+		notifyFriendAccepted(request, sender)
+		utils.JSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Friend request accepted",
+			"request": request,
+		})
+		return
+	}
+
+	payload := map[string]interface{}{
+		"request_id":    request.ID,
+		"from_user_id":  sender.ID,
+		"from_username": sender.Username,
+		"from_avatar":   sender.Avatar,
+	}
+	services.Notifications.Notify(req.FriendID, models.NotificationCategorySocial, "friend_request",
+		sender.Username+" sent you a friend request", "", payload)
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Friend request sent",
+		"request": request,
+	})
+}
+
+// AcceptFriendRequest accepts a request sent to the user
+// POST /api/friends/accept
+func AcceptFriendRequest(w http.ResponseWriter, r *http.Request) {
+	userID, requestID, ok := friendRequestParams(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := services.Friends.Accept(requestID, userID)
+	if err != nil {
+		writeFriendError(w, err)
+		return
+	}
+
+	var user models.User
+	database.GetDB().Select("id", "username", "avatar").First(&user, userID)
+	notifyFriendAccepted(request, user)
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "message": "Friend request accepted"})
+}
+
+// RejectFriendRequest declines a request sent to the user
+// POST /api/friends/reject
+func RejectFriendRequest(w http.ResponseWriter, r *http.Request) {
+	userID, requestID, ok := friendRequestParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := services.Friends.Reject(requestID, userID); err != nil {
+		writeFriendError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "message": "Friend request rejected"})
+}
+
+// CancelFriendRequest withdraws a request the user sent
+// POST /api/friends/cancel
+func CancelFriendRequest(w http.ResponseWriter, r *http.Request) {
+	userID, requestID, ok := friendRequestParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := services.Friends.Cancel(requestID, userID); err != nil {
+		writeFriendError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "message": "Friend request cancelled"})
+}
+
+// RemoveFriend unfriends {id}
+// POST /api/friends/{id}/remove
+func RemoveFriend(w http.ResponseWriter, r *http.Request) {
+	userID, friendID, ok := friendUserParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := services.Friends.Remove(userID, friendID); err != nil {
+		writeFriendError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "message": "Friend removed"})
+}
+
+// GetMutualFriends lists the friends the user shares with {id}
+// GET /api/friends/{id}/mutual
+func GetMutualFriends(w http.ResponseWriter, r *http.Request) {
+	userID, otherID, ok := friendUserParams(w, r)
+	if !ok {
+		return
+	}
+
+	users, err := services.Friends.Mutual(userID, otherID)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch mutual friends")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"friends": users,
+		"total":   len(users),
+	})
+}
+
+// GetBlockedUsers lists the users the current user has blocked
+// GET /api/friends/blocked
+func GetBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	blocks, err := services.Friends.Blocked(userID)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch blocked users")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "blocked": blocks})
+}
+
+// BlockUser blocks {id}, removing any friendship and pending requests
+// POST /api/friends/{id}/block
+func BlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := friendUserParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := services.Friends.Block(userID, targetID); err != nil {
+		writeFriendError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "message": "User blocked"})
+}
+
+// UnblockUser lifts a block on {id}
+// POST /api/friends/{id}/unblock
+func UnblockUser(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := friendUserParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := services.Friends.Unblock(userID, targetID); err != nil {
+		writeFriendError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "message": "User unblocked"})
+}
+
+// ================== PRESENCE ==================
+
+// connectedUsers maps each signed-in user with an open WebSocket to their
+// presence: in_game while their room is playing, otherwise online
+func connectedUsers() map[uint]string {
+	mu.RLock()
+	conns := make([]*Player, 0, len(players))
+	for _, p := range players {
+		conns = append(conns, p)
+	}
+	mu.RUnlock()
+
+	connected := make(map[uint]string)
+	for _, p := range conns {
+		p.mu.RLock()
+		userID, roomCode := p.UserID, p.Room
+		p.mu.RUnlock()
+		if userID == nil {
+			continue
+		}
+
+		status := PresenceOnline
+		if roomCode != "" {
+			mu.RLock()
+			room, ok := rooms[roomCode]
+			mu.RUnlock()
+			if ok {
+				room.mu.RLock()
+				if room.State == "playing" || room.State == "starting" {
+					status = PresenceInGame
+				}
+				room.mu.RUnlock()
+			}
+		}
+		if connected[*userID] != PresenceInGame {
+			connected[*userID] = status
+		}
+	}
+	return connected
+}
+
+// presenceOf combines WebSocket connections with recent HTTP activity
+func presenceOf(userID uint, lastActivity *time.Time, connected map[uint]string) string {
+	if status, ok := connected[userID]; ok {
+		return status
+	}
+	if lastActivity != nil && time.Since(*lastActivity) < presenceWindow {
+		return PresenceOnline
+	}
+	return PresenceOffline
+}
+
+// ================== ROOM INVITES (WEBSOCKET) ==================
+
+// roomInvite is a pending invitation to join a private room
+type roomInvite struct {
+	ID           string
+	RoomCode     string
+	FromUserID   uint
+	FromPlayerID string
+	ToUserID     uint
+	ExpiresAt    time.Time
+}
+
+var (
+	roomInvites   = make(map[string]*roomInvite) // invite ID → invite
+	roomInvitesMu sync.Mutex
+)
+
+// handleInviteFriend sends a friend an invitation to the inviter's room
+func handleInviteFriend(player *Player, payload interface{}) {
+	player.mu.RLock()
+	userID, roomCode, username := player.UserID, player.Room, player.Username
+	spectating := player.IsSpectator
+	player.mu.RUnlock()
+
+	if userID == nil || player.IsGuest {
+		player.sendMessage("error", map[string]interface{}{"error": "Sign in to invite friends"})
+		return
+	}
+
+	mu.RLock()
+	room, exists := rooms[roomCode]
+	mu.RUnlock()
+	if !exists || spectating {
+		player.sendMessage("error", map[string]interface{}{"error": "Create or join a room first"})
+		return
+	}
+	room.mu.RLock()
+	state, playerCount, maxPlayers := room.State, len(room.Players), room.MaxPlayers
+	room.mu.RUnlock()
+	if state != "waiting" {
+		player.sendMessage("error", map[string]interface{}{"error": "The game has already started"})
+		return
+	}
+
+	friendID := uint(getInt(parsePayload(payload), "user_id", 0))
+	if !services.Friends.AreFriends(*userID, friendID) {
+		player.sendMessage("error", map[string]interface{}{"error": services.ErrNotFriends.Error()})
+		return
+	}
+	if _, online := connectedUsers()[friendID]; !online {
+		player.sendMessage("error", map[string]interface{}{"error": "Friend is not online"})
+		return
+	}
+
+	invite := &roomInvite{
+		ID:           uuid.NewString(),
+		RoomCode:     roomCode,
+		FromUserID:   *userID,
+		FromPlayerID: player.ID,
+		ToUserID:     friendID,
+		ExpiresAt:    time.Now().Add(roomInviteTTL),
+	}
+	roomInvitesMu.Lock()
+	for id, inv := range roomInvites {
+		if time.Now().After(inv.ExpiresAt) {
+			delete(roomInvites, id)
+		}
+	}
+	roomInvites[invite.ID] = invite
+	roomInvitesMu.Unlock()
+
+	services.Notifications.Push(friendID, models.NotificationCategorySocial, "room_invite", map[string]interface{}{
+		"invite_id":     invite.ID,
+		"from_user_id":  *userID,
+		"from_username": username,
+		"room_code":     roomCode,
+		"player_count":  playerCount,
+		"max_players":   maxPlayers,
+		"expires_at":    invite.ExpiresAt,
+	})
+	player.sendMessage("invite_sent", map[string]interface{}{
+		"invite_id":  invite.ID,
+		"user_id":    friendID,
+		"expires_at": invite.ExpiresAt,
+	})
+
+	log.Printf("✉️  User %d invited user %d to room %s", *userID, friendID, roomCode)
+}
+
+// handleAcceptInvite joins the room an invitation was for
+func handleAcceptInvite(player *Player, payload interface{}) {
+	invite, ok := takeRoomInvite(player, payload)
+	if !ok {
+		return
+	}
+
+	player.mu.RLock()
+	currentRoom := player.Room
+	player.mu.RUnlock()
+	if currentRoom == invite.RoomCode {
+		return
+	}
+	if currentRoom != "" {
+		handleLeaveRoom(player)
+	}
+
+	mu.RLock()
+	room, exists := rooms[invite.RoomCode]
+	mu.RUnlock()
+	if exists {
+		room.mu.RLock()
+		exists = room.State == "waiting"
+		room.mu.RUnlock()
+	}
+	if !exists {
+		player.sendMessage("error", map[string]interface{}{"error": "That game is no longer open"})
+		return
+	}
+
+	handleJoinRoom(player, map[string]interface{}{"room_code": invite.RoomCode})
+
+	player.mu.RLock()
+	joined := player.Room == invite.RoomCode
+	player.mu.RUnlock()
+	if joined {
+		services.Notifications.Push(invite.FromUserID, models.NotificationCategorySocial, "invite_accepted", map[string]interface{}{
+			"invite_id": invite.ID,
+			"user_id":   invite.ToUserID,
+			"username":  player.Username,
+		})
+	}
+}
+
+// handleDeclineInvite drops an invitation and tells the inviter
+func handleDeclineInvite(player *Player, payload interface{}) {
+	invite, ok := takeRoomInvite(player, payload)
+	if !ok {
+		return
+	}
+
+	services.Notifications.Push(invite.FromUserID, models.NotificationCategorySocial, "invite_declined", map[string]interface{}{
+		"invite_id": invite.ID,
+		"user_id":   invite.ToUserID,
+		"username":  player.Username,
+	})
+}
+
+// takeRoomInvite removes and returns the player's unexpired invitation named
+// by the payload's invite_id
+func takeRoomInvite(player *Player, payload interface{}) (*roomInvite, bool) {
+	inviteID := getString(parsePayload(payload), "invite_id", "")
+
+	player.mu.RLock()
+	userID := player.UserID
+	player.mu.RUnlock()
+
+	roomInvitesMu.Lock()
+	invite, ok := roomInvites[inviteID]
+	if ok && userID != nil && invite.ToUserID == *userID {
+		delete(roomInvites, inviteID)
+	} else {
+		ok = false
+	}
+	roomInvitesMu.Unlock()
+
+	if !ok || time.Now().After(invite.ExpiresAt) {
+		player.sendMessage("error", map[string]interface{}{"error": "Invite not found or expired"})
+		return nil, false
+	}
+	return invite, true
+}
+
+// ================== HELPERS ==================
+
+// friendRequestParams reads the current user and the body's request_id
+func friendRequestParams(w http.ResponseWriter, r *http.Request) (uint, uint, bool) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return 0, 0, false
+	}
+
+	var req struct {
+		RequestID uint `json:"request_id"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil || req.RequestID == 0 {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request")
+		return 0, 0, false
+	}
+	return userID, req.RequestID, true
+}
+
+// friendUserParams reads the current user and the {id} path value
+func friendUserParams(w http.ResponseWriter, r *http.Request) (uint, uint, bool) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return 0, 0, false
+	}
+
+	otherID, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid user ID")
+		return 0, 0, false
+	}
+	return userID, uint(otherID), true
+}
+
+// notifyFriendAccepted tells the requester their request was accepted and
+// checks both users' social achievements
+func notifyFriendAccepted(request *models.FriendRequest, accepter models.User) {
+	other := request.FromUserID
+	if other == accepter.ID {
+		other = request.ToUserID
+	}
+
+	payload := map[string]interface{}{
+		"request_id": request.ID,
+		"user_id":    accepter.ID,
+		"username":   accepter.Username,
+		"avatar":     accepter.Avatar,
+	}
+	services.Notifications.Notify(other, models.NotificationCategorySocial, "friend_request_accepted",
+		accepter.Username+" accepted your friend request", "", payload)
+
+	go awardAchievements(accepter.ID, services.AchievementEvent{Type: services.AchievementEventSocial})
+	go awardAchievements(other, services.AchievementEvent{Type: services.AchievementEventSocial})
+}
+
+func writeFriendError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrFriendUserNotFound), errors.Is(err, services.ErrFriendRequestNotFound),
+		errors.Is(err, services.ErrNotFriends):
+		utils.JSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrFriendSelf), errors.Is(err, services.ErrFriendGuest):
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrUserBlocked):
+		utils.JSONError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrAlreadyFriends), errors.Is(err, services.ErrFriendRequestExists),
+		errors.Is(err, services.ErrAlreadyBlocked):
+		utils.JSONError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("⚠️  Friends request failed: %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Friends request failed")
+	}
+}
@@ -3,12 +3,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"ubible/database"
+	"ubible/middleware"
 	"ubible/models"
+	"ubible/services"
 )
 
 // writeJSON is a small helper for this file.
@@ -19,242 +21,222 @@ func writeJSON(w http.ResponseWriter, status int, payload any) {
 }
 
 // GetLeaderboardHTTP returns the global leaderboard
-// GET /api/leaderboard?category=level&limit=100&offset=0
+// GET /api/leaderboard?window=all&category=level&theme_id=&limit=100&offset=0
 func GetLeaderboardHTTP(w http.ResponseWriter, r *http.Request) {
-	q := r.URL.Query()
-	category := q.Get("category")
-	if category == "" {
-		category = "level"
-	}
-	limit := clampInt(parseIntDefault(q.Get("limit"), 100), 1, 100)
-	offset := maxInt(parseIntDefault(q.Get("offset"), 0), 0)
-
-	db := database.GetDB()
-	var users []models.User
+	writeLeaderboardPage(w, r, leaderboardQuery(r))
+}
 
-	var orderBy string
-	switch category {
-	case "xp":
-		orderBy = "xp DESC, wins DESC, total_games ASC"
-	case "level":
-		orderBy = "level DESC, xp DESC"
-	case "wins":
-		orderBy = "wins DESC"
-	case "streak":
-		orderBy = "best_streak DESC"
-	case "accuracy":
-		orderBy = "perfect_games DESC, wins DESC"
-	default:
-		orderBy = "xp DESC, wins DESC, total_games ASC"
+// GetSeasonLeaderboardHTTP returns the current season's leaderboard, or a
+// closed season's archived final standings
+// GET /api/leaderboard/season?season=&category=xp&limit=100&offset=0
+func GetSeasonLeaderboardHTTP(w http.ResponseWriter, r *http.Request) {
+	if number := r.URL.Query().Get("season"); number != "" {
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Invalid season number"})
+			return
+		}
+		season, err := services.Seasons.Get(n)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+			return
+		}
+		if season.Status == models.SeasonStatusClosed {
+			limit := clampInt(parseIntDefault(r.URL.Query().Get("limit"), 100), 1, 100)
+			offset := maxInt(parseIntDefault(r.URL.Query().Get("offset"), 0), 0)
+			standings, total, err := services.Seasons.Standings(season.ID, limit, offset)
+			if err != nil {
+				writeLeaderboardError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{
+				"success":    true,
+				"window":     services.WindowSeason,
+				"category":   "rating",
+				"season":     season,
+				"period_key": "S" + strconv.Itoa(season.Number),
+				"starts_at":  season.StartsAt,
+				"ends_at":    season.EndsAt,
+				"entries":    standings,
+				"total":      total,
+				"limit":      limit,
+				"offset":     offset,
+			})
+			return
+		}
 	}
 
-	if err := db.Where("is_guest = ?", false).
-		Order(orderBy).
-		Limit(limit).
-		Offset(offset).
-		Find(&users).Error; err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]any{
-			"error": "Failed to fetch leaderboard",
-		})
+	q := leaderboardQuery(r)
+	q.Window = services.WindowSeason
+	writeLeaderboardPage(w, r, q)
+}
+
+// GetThemeLeaderboardHTTP ranks players on one theme
+// GET /api/leaderboard/themes/{id}?window=weekly&category=score&limit=100&offset=0
+func GetThemeLeaderboardHTTP(w http.ResponseWriter, r *http.Request) {
+	themeID, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil || themeID == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Invalid theme ID"})
 		return
 	}
 
-	// Remove sensitive data
-	for i := range users {
-		users[i].Password = ""
-		users[i].Email = nil
+	q := leaderboardQuery(r)
+	q.ThemeID = uint(themeID)
+	if r.URL.Query().Get("category") == "" {
+		q.Metric = "score"
 	}
-
-	var total int64
-	db.Model(&models.User{}).Where("is_guest = ?", false).Count(&total)
-
-	writeJSON(w, http.StatusOK, map[string]any{
-		"success":  true,
-		"users":    users,
-		"category": category,
-		"total":    total,
-		"limit":    limit,
-		"offset":   offset,
-	})
+	writeLeaderboardPage(w, r, q)
 }
 
-// GetSeasonLeaderboardHTTP returns the seasonal leaderboard
-// GET /api/leaderboard/season?limit=100&offset=0
-func GetSeasonLeaderboardHTTP(w http.ResponseWriter, r *http.Request) {
-	q := r.URL.Query()
-	limit := clampInt(parseIntDefault(q.Get("limit"), 100), 1, 100)
-	offset := maxInt(parseIntDefault(q.Get("offset"), 0), 0)
-
-	db := database.GetDB()
-
-	type LeaderboardEntry struct {
-		UserID        uint    `json:"user_id"`
-		Username      string  `json:"username"`
-		Avatar        string  `json:"avatar"`
-		Level         int     `json:"level"`
-		TotalGames    int     `json:"total_games"`
-		Wins          int     `json:"wins"`
-		WinRate       float64 `json:"win_rate"`
-		CurrentStreak int     `json:"current_streak"`
+// GetFriendsLeaderboardHTTP ranks the current user among their friends
+// GET /api/leaderboard/friends?window=weekly&category=xp&theme_id=
+func GetFriendsLeaderboardHTTP(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "Unauthorized"})
+		return
 	}
 
-	var entries []LeaderboardEntry
-
-	db.Raw(`
-		SELECT 
-			id as user_id,
-			username,
-			avatar,
-			level,
-			total_games,
-			wins,
-			CASE WHEN total_games > 0 THEN (CAST(wins AS FLOAT) / total_games * 100) ELSE 0 END as win_rate,
-			current_streak
-		FROM users
-		WHERE is_guest = false
-		ORDER BY wins DESC, level DESC
-		LIMIT ? OFFSET ?
-	`, limit, offset).Scan(&entries)
-
-	var total int64
-	db.Model(&models.User{}).Where("is_guest = ?", false).Count(&total)
+	board, err := services.Leaderboards.Friends(leaderboardQuery(r), userID)
+	if err != nil {
+		writeLeaderboardError(w, err)
+		return
+	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
 		"success": true,
-		"entries": entries,
-		"total":   total,
-		"limit":   limit,
-		"offset":  offset,
+		"board":   board,
+		"entries": board.Entries,
+		"total":   len(board.Entries),
 	})
 }
 
 // GetUserRankHTTP returns a user's rank in the leaderboard
-// GET /api/leaderboard/user/{id}?category=level
+// GET /api/leaderboard/user/{id}?window=all&category=level
 func GetUserRankHTTP(w http.ResponseWriter, r *http.Request) {
-	// naive param extraction: trim prefix
-	path := strings.TrimPrefix(r.URL.Path, "/api/leaderboard/user/")
-	userID := path
-	if userID == "" || userID == "/api/leaderboard/user" {
-		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "missing user id"})
+	user, ok := leaderboardUser(w, r)
+	if !ok {
 		return
 	}
-	category := r.URL.Query().Get("category")
-	if category == "" {
-		category = "level"
-	}
-
-	db := database.GetDB()
-	var user models.User
 
-	if err := db.Where("id = ? OR username = ?", userID, userID).First(&user).Error; err != nil {
-		writeJSON(w, http.StatusNotFound, map[string]any{"error": "User not found"})
+	q := leaderboardQuery(r)
+	board, err := services.Leaderboards.Get(q)
+	if err != nil {
+		writeLeaderboardError(w, err)
 		return
 	}
 
-	var rank int64
-	var query string
-
-	switch category {
-	case "xp":
-		query = "SELECT COUNT(*) + 1 FROM users WHERE is_guest = false AND (xp > ? OR (xp = ? AND wins > ?) OR (xp = ? AND wins = ? AND total_games < ?))"
-		db.Raw(query, user.XP, user.XP, user.Wins, user.XP, user.Wins, user.TotalGames).Scan(&rank)
-	case "level":
-		query = "SELECT COUNT(*) + 1 FROM users WHERE is_guest = false AND (level > ? OR (level = ? AND xp > ?))"
-		db.Raw(query, user.Level, user.Level, user.XP).Scan(&rank)
-	case "wins":
-		query = "SELECT COUNT(*) + 1 FROM users WHERE is_guest = false AND wins > ?"
-		db.Raw(query, user.Wins).Scan(&rank)
-	case "streak":
-		query = "SELECT COUNT(*) + 1 FROM users WHERE is_guest = false AND best_streak > ?"
-		db.Raw(query, user.BestStreak).Scan(&rank)
-	case "accuracy":
-		query = "SELECT COUNT(*) + 1 FROM users WHERE is_guest = false AND (perfect_games > ? OR (perfect_games = ? AND wins > ?))"
-		db.Raw(query, user.PerfectGames, user.PerfectGames, user.Wins).Scan(&rank)
-	default:
-		query = "SELECT COUNT(*) + 1 FROM users WHERE is_guest = false AND (xp > ? OR (xp = ? AND wins > ?) OR (xp = ? AND wins = ? AND total_games < ?))"
-		db.Raw(query, user.XP, user.XP, user.Wins, user.XP, user.Wins, user.TotalGames).Scan(&rank)
-	}
-
-	writeJSON(w, http.StatusOK, map[string]any{
+	response := map[string]any{
 		"success":  true,
 		"user_id":  user.ID,
 		"username": user.Username,
-		"rank":     rank,
-		"category": category,
-	})
+		"window":   board.Window,
+		"category": board.Metric,
+		"rank":     nil, // Not on the board
+	}
+	if entry, found := board.Find(user.ID); found {
+		response["rank"] = entry.Rank
+		response["entry"] = entry
+	} else if board.Window == services.WindowAll && board.ThemeID == 0 && !user.IsGuest {
+		response["rank"] = services.Leaderboards.AllTimeRank(*user, board.Metric)
+	}
+
+	writeJSON(w, http.StatusOK, response)
 }
 
 // GetLeaderboardAroundUserHTTP returns entries around a specific user
-// GET /api/leaderboard/around/{id}?category=level&context=5
+// GET /api/leaderboard/around/{id}?window=all&category=level&context=5
 func GetLeaderboardAroundUserHTTP(w http.ResponseWriter, r *http.Request) {
-	// naive param extraction
-	path := strings.TrimPrefix(r.URL.Path, "/api/leaderboard/around/")
-	userID := path
-	if userID == "" || userID == "/api/leaderboard/around" {
-		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "missing user id"})
+	user, ok := leaderboardUser(w, r)
+	if !ok {
 		return
 	}
-	q := r.URL.Query()
-	category := q.Get("category")
-	if category == "" {
-		category = "level"
-	}
-	contextN := clampInt(parseIntDefault(q.Get("context"), 5), 1, 20)
+	contextN := clampInt(parseIntDefault(r.URL.Query().Get("context"), 5), 1, 20)
 
-	db := database.GetDB()
-	var user models.User
-
-	if err := db.Where("id = ? OR username = ?", userID, userID).First(&user).Error; err != nil {
-		writeJSON(w, http.StatusNotFound, map[string]any{"error": "User not found"})
+	board, err := services.Leaderboards.Get(leaderboardQuery(r))
+	if err != nil {
+		writeLeaderboardError(w, err)
 		return
 	}
 
-	var users []models.User
-	var orderBy string
+	writeJSON(w, http.StatusOK, map[string]any{
+		"success":     true,
+		"entries":     board.Around(user.ID, contextN),
+		"target_user": user.ID,
+		"window":      board.Window,
+		"category":    board.Metric,
+		"context":     contextN,
+	})
+}
 
-	switch category {
-	case "xp":
-		orderBy = "xp DESC, wins DESC, total_games ASC"
-	case "level":
-		orderBy = "level DESC, xp DESC"
-	case "wins":
-		orderBy = "wins DESC"
-	case "streak":
-		orderBy = "best_streak DESC"
-	case "accuracy":
-		orderBy = "perfect_games DESC, wins DESC"
-	default:
-		orderBy = "xp DESC, wins DESC, total_games ASC"
+// leaderboardQuery reads the window, category and theme from the query string
+func leaderboardQuery(r *http.Request) services.LeaderboardQuery {
+	q := r.URL.Query()
+	return services.LeaderboardQuery{
+		Window:  q.Get("window"),
+		Metric:  q.Get("category"),
+		ThemeID: uint(maxInt(parseIntDefault(q.Get("theme_id"), 0), 0)),
 	}
+}
 
-	db.Raw(`
-		WITH ranked_users AS (
-			SELECT *, ROW_NUMBER() OVER (ORDER BY `+orderBy+`) as rank
-			FROM users
-			WHERE is_guest = false
-		),
-		target_rank AS (
-			SELECT rank FROM ranked_users WHERE id = ?
-		)
-		SELECT * FROM ranked_users
-		WHERE rank BETWEEN (SELECT rank FROM target_rank) - ? AND (SELECT rank FROM target_rank) + ?
-		ORDER BY rank
-	`, user.ID, contextN, contextN).Scan(&users)
+// writeLeaderboardPage writes a page of the global board for q
+func writeLeaderboardPage(w http.ResponseWriter, r *http.Request, q services.LeaderboardQuery) {
+	limit := clampInt(parseIntDefault(r.URL.Query().Get("limit"), 100), 1, 100)
+	offset := maxInt(parseIntDefault(r.URL.Query().Get("offset"), 0), 0)
 
-	for i := range users {
-		users[i].Password = ""
-		users[i].Email = nil
+	board, err := services.Leaderboards.Get(q)
+	if err != nil {
+		writeLeaderboardError(w, err)
+		return
 	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
-		"success":     true,
-		"users":       users,
-		"target_user": user.ID,
-		"category":    category,
-		"context":     contextN,
+		"success":      true,
+		"window":       board.Window,
+		"category":     board.Metric,
+		"theme_id":     board.ThemeID,
+		"period_key":   board.PeriodKey,
+		"starts_at":    board.StartsAt,
+		"ends_at":      board.EndsAt,
+		"generated_at": board.GeneratedAt,
+		"entries":      board.Page(limit, offset),
+		"total":        len(board.Entries),
+		"limit":        limit,
+		"offset":       offset,
 	})
 }
 
+// leaderboardUser loads the {id} path value as a user ID or username
+func leaderboardUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	id := r.PathValue("id")
+	if id == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "missing user id"})
+		return nil, false
+	}
+
+	var user models.User
+	query := database.GetDB()
+	if n, err := strconv.ParseUint(id, 10, 32); err == nil {
+		query = query.Where("id = ?", n)
+	} else {
+		query = query.Where("username = ?", id)
+	}
+	if err := query.First(&user).Error; err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "User not found"})
+		return nil, false
+	}
+	return &user, true
+}
+
+func writeLeaderboardError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrUnknownWindow) || errors.Is(err, services.ErrUnknownMetric) ||
+		errors.Is(err, services.ErrUnknownTheme) {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Failed to fetch leaderboard"})
+}
+
 // helpers
 func parseIntDefault(s string, def int) int {
 	if s == "" {
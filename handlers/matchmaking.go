@@ -0,0 +1,432 @@
+// handlers/matchmaking.go - Rating-based matchmaking queue for find_match
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"ubible/database"
+	"ubible/models"
+	"ubible/services"
+)
+
+const (
+	// Matchmaking loop cadence
+	matchTickInterval = 1 * time.Second
+
+	// Rating band (Glicko-2 points)
+	matchBaseBand    = 100.0            // Initial +/- rating window
+	matchBandStep    = 50.0             // Window growth per widen interval
+	matchBandWiden   = 10 * time.Second // How often the window grows
+	matchDefaultWait = 60 * time.Second // Max wait before offering a fallback
+
+	// Group sizes
+	matchMinGroup = 2
+	matchMaxGroup = 10
+
+	// Game settings, matching the choices the room UI offers
+	matchMinQuestions = 10
+	matchMaxQuestions = 30
+	matchMinTimeLimit = 5  // Seconds per question
+	matchMaxTimeLimit = 20 // Seconds per question
+)
+
+// matchRequest is one player's place in the matchmaking queue
+type matchRequest struct {
+	player         *Player
+	rating         float64
+	selectedThemes []int
+	questionCount  int
+	timeLimit      int
+	groupSize      int
+	joinedAt       time.Time
+
+	// Last values sent to the client, to avoid redundant updates
+	lastPosition int
+	lastWaiting  int
+	fallbackSent bool
+}
+
+// key groups requests that can play together (same themes and settings)
+func (r *matchRequest) key() string {
+	themes := make([]string, len(r.selectedThemes))
+	for i, id := range r.selectedThemes {
+		themes[i] = strconv.Itoa(id)
+	}
+	return fmt.Sprintf("%s|%d|%d|%d", strings.Join(themes, ","), r.questionCount, r.timeLimit, r.groupSize)
+}
+
+// band returns the rating window for this request, widening the longer it waits
+func (r *matchRequest) band(now time.Time) float64 {
+	steps := float64(now.Sub(r.joinedAt) / matchBandWiden)
+	return matchBaseBand + steps*matchBandStep
+}
+
+// Matchmaker pairs or groups queued players by settings and rating
+type Matchmaker struct {
+	queues  map[string][]*matchRequest // key -> requests in join order
+	byID    map[string]*matchRequest   // playerID -> request
+	maxWait time.Duration
+	once    sync.Once
+	mu      sync.Mutex
+}
+
+// NewMatchmaker creates a matchmaker; MATCHMAKING_MAX_WAIT (seconds) overrides the fallback delay
+func NewMatchmaker() *Matchmaker {
+	maxWait := matchDefaultWait
+	if v := os.Getenv("MATCHMAKING_MAX_WAIT"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			maxWait = time.Duration(secs) * time.Second
+		}
+	}
+
+	return &Matchmaker{
+		queues:  make(map[string][]*matchRequest),
+		byID:    make(map[string]*matchRequest),
+		maxWait: maxWait,
+	}
+}
+
+var matchmaker = NewMatchmaker()
+
+// enqueue adds (or replaces) a player's request and starts the loop on first use
+func (m *Matchmaker) enqueue(req *matchRequest) {
+	m.once.Do(func() {
+		go m.run()
+	})
+
+	m.mu.Lock()
+	m.removeLocked(req.player.ID)
+	key := req.key()
+	m.queues[key] = append(m.queues[key], req)
+	m.byID[req.player.ID] = req
+	m.mu.Unlock()
+}
+
+// remove takes a player out of the queue; returns true if they were queued
+func (m *Matchmaker) remove(player *Player) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.removeLocked(player.ID)
+}
+
+func (m *Matchmaker) removeLocked(playerID string) bool {
+	req, ok := m.byID[playerID]
+	if !ok {
+		return false
+	}
+	delete(m.byID, playerID)
+
+	key := req.key()
+	queue := m.queues[key]
+	for i, r := range queue {
+		if r == req {
+			queue = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(queue) == 0 {
+		delete(m.queues, key)
+	} else {
+		m.queues[key] = queue
+	}
+	return true
+}
+
+func (m *Matchmaker) run() {
+	ticker := time.NewTicker(matchTickInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.tick()
+	}
+}
+
+// tick forms every match it can, then sends queue updates to whoever is left
+func (m *Matchmaker) tick() {
+	now := time.Now()
+
+	m.mu.Lock()
+	var groups [][]*matchRequest
+	for key := range m.queues {
+		for {
+			group := m.findGroupLocked(key, now)
+			if group == nil {
+				break
+			}
+			for _, r := range group {
+				m.removeLocked(r.player.ID)
+			}
+			groups = append(groups, group)
+		}
+	}
+
+	type update struct {
+		req     *matchRequest
+		payload map[string]interface{}
+		msgType string
+	}
+	var updates []update
+	for _, queue := range m.queues {
+		for i, r := range queue {
+			waited := now.Sub(r.joinedAt)
+			if !r.fallbackSent && waited >= m.maxWait {
+				r.fallbackSent = true
+				updates = append(updates, update{r, map[string]interface{}{
+					"waited_seconds": int(waited.Seconds()),
+					"options":        []string{"solo", "keep_waiting"},
+				}, "match_fallback"})
+			}
+			if r.lastPosition != i+1 || r.lastWaiting != len(queue) {
+				r.lastPosition = i + 1
+				r.lastWaiting = len(queue)
+				updates = append(updates, update{r, map[string]interface{}{
+					"position":        i + 1,
+					"players_waiting": len(queue),
+					"group_size":      r.groupSize,
+					"waited_seconds":  int(waited.Seconds()),
+					"rating_band":     r.band(now),
+				}, "searching"})
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	for _, u := range updates {
+		u.req.player.sendMessage(u.msgType, u.payload)
+	}
+
+	for _, group := range groups {
+		startMatch(group)
+	}
+}
+
+// findGroupLocked returns the first complete group in a queue, preferring the
+// longest waiter and the closest ratings. Caller must hold m.mu.
+func (m *Matchmaker) findGroupLocked(key string, now time.Time) []*matchRequest {
+	queue := m.queues[key]
+	if len(queue) == 0 || len(queue) < queue[0].groupSize {
+		return nil
+	}
+	groupSize := queue[0].groupSize
+
+	for _, anchor := range queue {
+		candidates := make([]*matchRequest, 0, len(queue))
+		for _, other := range queue {
+			if other == anchor {
+				continue
+			}
+			window := math.Max(anchor.band(now), other.band(now))
+			if math.Abs(other.rating-anchor.rating) <= window {
+				candidates = append(candidates, other)
+			}
+		}
+		if len(candidates) < groupSize-1 {
+			continue
+		}
+
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return math.Abs(candidates[i].rating-anchor.rating) < math.Abs(candidates[j].rating-anchor.rating)
+		})
+		return append([]*matchRequest{anchor}, candidates[:groupSize-1]...)
+	}
+
+	return nil
+}
+
+// startMatch builds a room for a formed group and starts the game. Players
+// who disconnected or joined a room since the group formed are dropped and
+// the rest go back into the queue.
+func startMatch(group []*matchRequest) {
+	ready := make([]*matchRequest, 0, len(group))
+	for _, r := range group {
+		r.player.mu.RLock()
+		inRoom := r.player.Room != ""
+		r.player.mu.RUnlock()
+		if r.player.isClosed() || inRoom {
+			log.Printf("⚠️  Player %s left before their match started", r.player.ID)
+			continue
+		}
+		ready = append(ready, r)
+	}
+	if len(ready) < len(group) {
+		for _, r := range ready {
+			r.lastPosition = 0
+			matchmaker.enqueue(r)
+		}
+		return
+	}
+
+	host := group[0]
+	room := createRoom(host.player, len(group), host.selectedThemes, host.questionCount, host.timeLimit, true)
+
+	for _, r := range group[1:] {
+		if !addPlayerToRoom(room, r.player) {
+			log.Printf("⚠️  Matchmaking room %s full, player %s dropped", room.Code, r.player.ID)
+			continue
+		}
+		r.player.mu.Lock()
+		r.player.IsReady = true
+		r.player.mu.Unlock()
+	}
+
+	// A player who disconnected while being seated missed the disconnect
+	// cleanup's room check, so take them back out here
+	for _, r := range group {
+		if r.player.isClosed() {
+			handleLeaveRoom(r.player)
+		}
+	}
+
+	log.Printf("🤝 Match formed in room %s with %d players", room.Code, len(group))
+
+	players := getPlayerList(room)
+	for _, r := range group {
+		r.player.sendMessage("match_found", map[string]interface{}{
+			"room_code":       room.Code,
+			"game_url":        room.GameURL,
+			"game_token":      room.GameToken,
+			"players":         players,
+			"selected_themes": room.SelectedThemes,
+			"question_count":  room.QuestionCount,
+			"time_limit":      room.TimeLimit,
+			"waited_seconds":  int(time.Since(r.joinedAt).Seconds()),
+		})
+	}
+
+	startGame(room)
+}
+
+// clampSetting keeps a requested game setting within [lo, hi]
+func clampSetting(n, lo, hi int) int {
+	if n < lo {
+		return lo
+	}
+	if n > hi {
+		return hi
+	}
+	return n
+}
+
+// playerRating returns the rating used for matchmaking (guests get the default)
+func playerRating(player *Player) float64 {
+	if player.UserID == nil {
+		return services.DefaultRating
+	}
+
+	db := database.GetDB()
+	if db == nil {
+		return services.DefaultRating
+	}
+
+	var user models.User
+	if err := db.Select("id", "rating").First(&user, *player.UserID).Error; err != nil {
+		return services.DefaultRating
+	}
+	return user.Rating
+}
+
+// handleFindMatch queues the player for a rated match with the given settings
+func handleFindMatch(player *Player, payload interface{}) {
+	player.mu.RLock()
+	inRoom := player.Room != ""
+	player.mu.RUnlock()
+
+	if inRoom {
+		player.sendMessage("error", map[string]interface{}{"error": "Leave your current room before searching"})
+		return
+	}
+
+	data := parsePayload(payload)
+	selectedThemes := getIntArray(data, "selected_themes")
+	if len(selectedThemes) == 0 {
+		selectedThemes = getIntArray(data, "theme_ids")
+	}
+	sort.Ints(selectedThemes)
+	if !checkThemeAccess(player, selectedThemes) {
+		return
+	}
+
+	groupSize := clampSetting(getInt(data, "group_size", matchMinGroup), matchMinGroup, matchMaxGroup)
+
+	req := &matchRequest{
+		player:         player,
+		rating:         playerRating(player),
+		selectedThemes: selectedThemes,
+		questionCount:  clampSetting(getInt(data, "question_count", 10), matchMinQuestions, matchMaxQuestions),
+		timeLimit:      clampSetting(getInt(data, "time_limit", 10), matchMinTimeLimit, matchMaxTimeLimit),
+		groupSize:      groupSize,
+		joinedAt:       time.Now(),
+	}
+	matchmaker.enqueue(req)
+
+	log.Printf("🔍 Player %s queued for matchmaking (rating %.0f, key %s)", player.ID, req.rating, req.key())
+
+	player.sendMessage("searching", map[string]interface{}{
+		"position":        0,
+		"players_waiting": 0,
+		"group_size":      groupSize,
+		"waited_seconds":  0,
+		"rating_band":     matchBaseBand,
+	})
+}
+
+// handleCancelMatchmaking removes the player from the queue
+func handleCancelMatchmaking(player *Player, _ interface{}) {
+	if matchmaker.remove(player) {
+		log.Printf("🔍 Player %s left matchmaking", player.ID)
+	}
+	player.sendMessage("matchmaking_cancelled", map[string]interface{}{})
+}
+
+// handleAcceptFallback starts a solo game for a player who has waited too long
+func handleAcceptFallback(player *Player, payload interface{}) {
+	data := parsePayload(payload)
+	mode := getString(data, "mode", "solo")
+
+	matchmaker.mu.Lock()
+	req, queued := matchmaker.byID[player.ID]
+	if queued && !req.fallbackSent {
+		matchmaker.mu.Unlock()
+		player.sendMessage("error", map[string]interface{}{"error": "Fallback not available yet"})
+		return
+	}
+	if queued {
+		matchmaker.removeLocked(player.ID)
+	}
+	matchmaker.mu.Unlock()
+
+	if !queued {
+		player.sendMessage("error", map[string]interface{}{"error": "Not in matchmaking"})
+		return
+	}
+
+	switch mode {
+	case "keep_waiting":
+		// Back into the queue with the band already widened; solo stays on offer
+		req.lastPosition = 0
+		matchmaker.enqueue(req)
+	case "solo":
+		room := createRoom(player, 1, req.selectedThemes, req.questionCount, req.timeLimit, true)
+		log.Printf("🧍 Player %s took solo fallback in room %s", player.ID, room.Code)
+		player.sendMessage("match_found", map[string]interface{}{
+			"room_code":       room.Code,
+			"game_url":        room.GameURL,
+			"game_token":      room.GameToken,
+			"players":         getPlayerList(room),
+			"selected_themes": room.SelectedThemes,
+			"question_count":  room.QuestionCount,
+			"time_limit":      room.TimeLimit,
+			"solo":            true,
+		})
+		startGame(room)
+	default:
+		player.sendMessage("error", map[string]interface{}{"error": "Unknown fallback mode"})
+	}
+}
@@ -4,13 +4,11 @@ package handlers
 import (
 	"context"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/base64"
-	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	mathrand "math/rand"
 	"net/http"
 	"os"
 	"strings"
@@ -18,9 +16,12 @@ import (
 	"time"
 	"ubible/database"
 	"ubible/models"
+	"ubible/services"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"nhooyr.io/websocket"
 	"nhooyr.io/websocket/wsjson"
 )
@@ -32,22 +33,30 @@ const (
 
 	// Send channel buffer size
 	sendBufferSize = 256
+
+	// Scoring
+	basePoints         = 100             // Points for a correct answer
+	timeBonusPerSecond = 10              // Bonus per whole second left on the clock
+	answerLatencyGrace = 1 * time.Second // Allowance for answers sent right at a player's own deadline
 )
 
 type Player struct {
-	ID        string          // UUID for in-game identity
-	UserID    *uint           // Database user ID (nil for guests)
-	Username  string
-	IsGuest   bool            // True for unauthenticated players
-	Conn      *websocket.Conn
-	Room      string
-	IsReady   bool
-	IsHost    bool
-	IsPlaying bool
-	send      chan Message   // Buffered channel for outbound messages
-	ctx       context.Context
-	cancel    context.CancelFunc
-	mu        sync.RWMutex
+	ID          string          // UUID for in-game identity
+	UserID      *uint           // Database user ID (nil for guests)
+	Username    string
+	IsGuest     bool            // True for unauthenticated players
+	Conn        *websocket.Conn
+	Room        string
+	IsReady     bool
+	IsHost      bool
+	IsPlaying   bool
+	IsSpectator bool            // Watching Room rather than playing in it
+	send        chan Message    // Buffered channel for outbound messages
+	sendMu      sync.RWMutex    // Guards closed
+	closed      bool            // send is closed; later messages are dropped
+	ctx         context.Context
+	cancel      context.CancelFunc
+	mu          sync.RWMutex
 }
 
 type Room struct {
@@ -69,21 +78,79 @@ type Room struct {
 	QuestionTimer     *time.Timer     `json:"-"`                // Timer for current question
 	PlayersAnswered   map[string]bool `json:"players_answered"` // playerID → has answered current Q
 	PlayerScores      map[string]int  `json:"player_scores"`    // playerID → current score
+	PlayerCorrect     map[string]int  `json:"player_correct"`   // playerID → correct answers so far
+	PlayerWrong       map[string]int  `json:"player_wrong"`     // playerID → wrong answers so far
+
+	// Server-side question set (includes correct answers - never sent to clients as-is)
+	Questions       []models.QuestionData   `json:"-"`
+	QuestionResults map[string]AnswerResult `json:"-"` // playerID → graded answer for current Q
+
+	// Power-up effects
+	PowerUpsUsed  map[string]map[string]bool `json:"-"` // playerID → power-ups used on current Q
+	TimeBonus     map[string]int             `json:"-"` // playerID → extra seconds on current Q (time freeze)
+	DoublePending map[string]bool            `json:"-"` // playerID → next graded answer scores double
+
+	StartedAt time.Time `json:"-"` // When the game left the lobby
+
+	// Spectators watch without a seat; see multiplayer_spectators.go
+	Spectators      map[string]*Player `json:"-"`
+	AllowSpectators bool               `json:"allow_spectators"`
+	MaxSpectators   int                `json:"max_spectators"`
+	spectatorQueue  []Message          // Open question's traffic, held back from spectators until it closes
+
+	recorder      *gameRecorder // Write-through persistence (multiplayer_games tables)
+	paused        bool          // Restored from a checkpoint, waiting for a reconnect
+	pausedElapsed time.Duration // Time already used on the current question when paused
 
 	mu sync.RWMutex
 }
 
+// AnswerResult is the server-graded outcome of a player's answer to one question
+type AnswerResult struct {
+	PlayerID       string `json:"player_id"`
+	Username       string `json:"username"`
+	Answer         string `json:"answer"`
+	Correct        bool   `json:"correct"`
+	PointsEarned   int    `json:"points_earned"`
+	ResponseTimeMs int64  `json:"response_time_ms"`
+	Score          int    `json:"score"`           // Running total after this question
+	CorrectAnswers int    `json:"correct_answers"` // Running correct count after this question
+	Skipped        bool   `json:"skipped,omitempty"`
+	Doubled        bool   `json:"doubled,omitempty"`
+	TimedOut       bool   `json:"timed_out,omitempty"` // Arrived after the player's own deadline
+}
+
 // GameSession stores secure game session data
 type GameSession struct {
 	GameID            string
 	RoomCode          string
 	Token             string
 	AuthorizedPlayers map[string]bool // playerID -> authorized
+	SeatUsers         map[string]uint // playerID -> user the seat belongs to, 0 for guests
 	CreatedAt         time.Time
 	ExpiresAt         time.Time
 	mu                sync.RWMutex
 }
 
+// authorize lets a player into the game. Their seat stays bound to the user
+// who first took it, so a reconnect can't hand it to someone else.
+func (s *GameSession) authorize(p *Player) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.AuthorizedPlayers[p.ID] = true
+	if _, taken := s.SeatUsers[p.ID]; !taken {
+		s.SeatUsers[p.ID] = seatUser(p.UserID, p.IsGuest)
+	}
+}
+
+// seatUser is the user ID a seat is bound to: 0 for guests
+func seatUser(userID *uint, isGuest bool) uint {
+	if isGuest || userID == nil {
+		return 0
+	}
+	return *userID
+}
+
 type Message struct {
 	Type    string      `json:"type"`
 	Payload interface{} `json:"payload"`
@@ -222,11 +289,16 @@ func handleWebSocket(w http.ResponseWriter, r *http.Request, userID *uint, usern
 	delete(players, conn)
 	mu.Unlock()
 
+	matchmaker.remove(player)
+
+	// Close first so a match formed concurrently sees the player is gone
+	player.closeSend()
+
 	if player.Room != "" {
+		recordDisconnect(player)
 		handleLeaveRoom(player)
 	}
 
-	close(player.send)
 	log.Printf("🔌 Player disconnected: %s (ID: %s, UserID: %v)", player.Username, player.ID, player.UserID)
 }
 
@@ -282,11 +354,16 @@ func HandleFiberWebSocketWithAuth(conn *websocket.Conn, playerID, username strin
 	delete(players, conn)
 	mu.Unlock()
 
+	matchmaker.remove(player)
+
+	// Close first so a match formed concurrently sees the player is gone
+	player.closeSend()
+
 	if player.Room != "" {
+		recordDisconnect(player)
 		handleLeaveRoom(player)
 	}
 
-	close(player.send)
 	log.Printf("🔌 Player disconnected: %s (ID: %s, UserID: %v)", player.Username, player.ID, player.UserID)
 }
 
@@ -429,8 +506,20 @@ func (p *Player) sendMessage(msgType string, payload interface{}) {
 }
 */
 
-// sendMessage queues a message to be sent to the player via WebSocket
+// sendMessage queues a message to be sent to the player via WebSocket. It is
+// safe to call after the player disconnected; the message is dropped.
 func (p *Player) sendMessage(msgType string, payload interface{}) {
+	if p.send == nil {
+		// Placeholder seat from a restored room - owner hasn't reconnected yet
+		return
+	}
+
+	p.sendMu.RLock()
+	defer p.sendMu.RUnlock()
+	if p.closed {
+		return
+	}
+
 	msg := Message{Type: msgType, Payload: payload}
 
 	select {
@@ -442,6 +531,23 @@ func (p *Player) sendMessage(msgType string, payload interface{}) {
 	}
 }
 
+// isClosed reports whether the player's connection has gone away
+func (p *Player) isClosed() bool {
+	p.sendMu.RLock()
+	defer p.sendMu.RUnlock()
+	return p.closed
+}
+
+// closeSend closes the outbound channel once the connection is gone
+func (p *Player) closeSend() {
+	p.sendMu.Lock()
+	defer p.sendMu.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.send)
+	}
+}
+
 func handleMessage(player *Player, msg Message) {
 	switch msg.Type {
 	case "create_room":
@@ -452,6 +558,10 @@ func handleMessage(player *Player, msg Message) {
 		handlePlayerReady(player)
 	case "find_match":
 		handleFindMatch(player, msg.Payload)
+	case "cancel_matchmaking":
+		handleCancelMatchmaking(player, msg.Payload)
+	case "accept_fallback":
+		handleAcceptFallback(player, msg.Payload)
 	case "leave_room":
 		handleLeaveRoom(player)
 	case "start_game":
@@ -462,6 +572,18 @@ func handleMessage(player *Player, msg Message) {
 		handlePlayerQuit(player, msg.Payload)
 	case "submit_answer":
 		handleSubmitAnswer(player, msg.Payload)
+	case "use_powerup":
+		handleUsePowerUp(player, msg.Payload)
+	case "invite_friend":
+		handleInviteFriend(player, msg.Payload)
+	case "accept_invite":
+		handleAcceptInvite(player, msg.Payload)
+	case "decline_invite":
+		handleDeclineInvite(player, msg.Payload)
+	case "spectate":
+		handleSpectate(player, msg.Payload)
+	case "spectator_settings":
+		handleSpectatorSettings(player, msg.Payload)
 	case "opponent_answered":
 		// Legacy event - treat same as submit_answer
 		handleSubmitAnswer(player, msg.Payload)
@@ -558,16 +680,65 @@ func handleCreateRoom(player *Player, payload interface{}) {
 	questionCount := getInt(data, "question_count", 10)
 	timeLimit := getInt(data, "time_limit", 10)
 
+	// The host's unlocks cover everyone who joins the room
+	if !checkThemeAccess(player, selectedThemes) {
+		return
+	}
+
 	log.Printf("🏠 [CREATE_ROOM] Settings: maxPlayers=%d, themes=%v, questions=%d, timeLimit=%d, hostPlaying=%v",
 		maxPlayers, selectedThemes, questionCount, timeLimit, hostIsPlaying)
 
+	// Creating a private room takes the player out of matchmaking
+	matchmaker.remove(player)
+	leaveIfSpectating(player)
+
+	room := createRoom(player, maxPlayers, selectedThemes, questionCount, timeLimit, hostIsPlaying)
+	room.mu.Lock()
+	room.AllowSpectators = getBool(data, "allow_spectators", true)
+	room.MaxSpectators = clampSpectators(getInt(data, "max_spectators", defaultMaxSpectators))
+	room.mu.Unlock()
+
+	player.sendMessage("room_created", map[string]interface{}{
+		"room_code":   room.Code,
+		"host":        room.Host,
+		"players":     getPlayerList(room),
+		"max_players": maxPlayers,
+		"game_url":    room.GameURL,
+		"game_token":  room.GameToken,
+	})
+
+	broadcastRoomUpdate(room)
+}
+
+// checkThemeAccess sends an error and returns false when the themes include a
+// paid theme the player hasn't unlocked
+func checkThemeAccess(player *Player, themeIDs []int) bool {
+	var userID uint
+	if player.UserID != nil && !player.IsGuest {
+		userID = *player.UserID
+	}
+	if err := services.Shop.CheckThemeAccess(userID, themeIDs); err != nil {
+		message := "Failed to check theme access"
+		if errors.Is(err, services.ErrThemeLocked) {
+			message = err.Error()
+		} else {
+			log.Printf("⚠️  %v", err)
+		}
+		player.sendMessage("error", map[string]interface{}{"error": message})
+		return false
+	}
+	return true
+}
+
+// createRoom registers a new room and game session with the given player as host
+func createRoom(host *Player, maxPlayers int, selectedThemes []int, questionCount, timeLimit int, hostIsPlaying bool) *Room {
 	roomCode := generateRoomCode()
 	gameID := generateID()
 	gameToken := generateSecureToken()
 
 	room := &Room{
 		Code:            roomCode,
-		Host:            player.ID,
+		Host:            host.ID,
 		Players:         make(map[string]*Player),
 		MaxPlayers:      maxPlayers,
 		State:           "waiting",
@@ -580,6 +751,16 @@ func handleCreateRoom(player *Player, payload interface{}) {
 		CurrentQuestion: 0,
 		PlayersAnswered: make(map[string]bool),
 		PlayerScores:    make(map[string]int),
+		PlayerCorrect:   make(map[string]int),
+		PlayerWrong:     make(map[string]int),
+		QuestionResults: make(map[string]AnswerResult),
+		PowerUpsUsed:    make(map[string]map[string]bool),
+		TimeBonus:       make(map[string]int),
+		DoublePending:   make(map[string]bool),
+		Spectators:      make(map[string]*Player),
+		AllowSpectators: true,
+		MaxSpectators:   defaultMaxSpectators,
+		recorder:        newGameRecorder(gameID),
 	}
 
 	// Create game session for access control
@@ -588,10 +769,11 @@ func handleCreateRoom(player *Player, payload interface{}) {
 		RoomCode:          roomCode,
 		Token:             gameToken,
 		AuthorizedPlayers: make(map[string]bool),
+		SeatUsers:         make(map[string]uint),
 		CreatedAt:         time.Now(),
 		ExpiresAt:         time.Now().Add(24 * time.Hour), // 24 hour expiry
 	}
-	gameSession.AuthorizedPlayers[player.ID] = true
+	gameSession.authorize(host)
 
 	mu.Lock()
 	gameSessions[gameID] = gameSession
@@ -606,28 +788,34 @@ func handleCreateRoom(player *Player, payload interface{}) {
 	log.Printf("📊 [ROOM_STATS] Total active rooms: %d", totalRooms)
 
 	room.mu.Lock()
-	room.Players[player.ID] = player
+	room.Players[host.ID] = host
 	room.mu.Unlock()
 
-	player.mu.Lock()
-	player.Room = roomCode
-	player.IsHost = true
-	player.IsReady = true
-	player.IsPlaying = hostIsPlaying
-	player.mu.Unlock()
+	host.mu.Lock()
+	host.Room = roomCode
+	host.IsHost = true
+	host.IsReady = true
+	host.IsPlaying = hostIsPlaying
+	host.mu.Unlock()
 
-	log.Printf("✅ [CREATE_ROOM] Host %s added to room %s", player.Username, roomCode)
+	log.Printf("✅ [CREATE_ROOM] Host %s added to room %s", host.Username, roomCode)
 
-	player.sendMessage("room_created", map[string]interface{}{
-		"room_code":   roomCode,
-		"host":        room.Host,
-		"players":     getPlayerList(room),
-		"max_players": maxPlayers,
-		"game_url":    room.GameURL,
-		"game_token":  room.GameToken,
+	recorder := room.recorder
+	recorder.enqueue(func() {
+		if _, err := services.MultiplayerDB.CreateGame(gameID, roomCode, room.GameURL, host.ID, maxPlayers, questionCount, timeLimit, selectedThemes); err != nil {
+			log.Printf("⚠️  Failed to persist game %s: %v", gameID, err)
+		}
 	})
+	recorder.logEvent("room_created", host.ID, nil, map[string]interface{}{
+		"room_code":       roomCode,
+		"max_players":     maxPlayers,
+		"question_count":  questionCount,
+		"time_limit":      timeLimit,
+		"selected_themes": selectedThemes,
+	})
+	recorder.recordPlayerJoined(host)
 
-	broadcastRoomUpdate(room)
+	return room
 }
 
 func handleJoinRoom(player *Player, payload interface{}) {
@@ -643,32 +831,14 @@ func handleJoinRoom(player *Player, payload interface{}) {
 		return
 	}
 
-	room.mu.Lock()
-	if len(room.Players) >= room.MaxPlayers {
-		room.mu.Unlock()
+	// Joining a private room takes the player out of matchmaking
+	matchmaker.remove(player)
+	leaveIfSpectating(player)
+
+	if !addPlayerToRoom(room, player) {
 		player.sendMessage("error", map[string]interface{}{"error": "Room is full"})
 		return
 	}
-	room.Players[player.ID] = player
-	room.mu.Unlock()
-
-	player.mu.Lock()
-	player.Room = roomCode
-	player.IsReady = false
-	player.IsPlaying = true
-	player.mu.Unlock()
-
-	// Authorize player for game session
-	gameID := extractGameIDFromURL(room.GameURL)
-	mu.RLock()
-	gameSession, exists := gameSessions[gameID]
-	mu.RUnlock()
-
-	if exists {
-		gameSession.mu.Lock()
-		gameSession.AuthorizedPlayers[player.ID] = true
-		gameSession.mu.Unlock()
-	}
 
 	player.sendMessage("room_joined", map[string]interface{}{
 		"room_code":       roomCode,
@@ -690,8 +860,46 @@ func handleJoinRoom(player *Player, payload interface{}) {
 	broadcastRoomUpdate(room)
 }
 
+// addPlayerToRoom seats a player and authorizes them for the room's game session.
+// Returns false if the room is full.
+func addPlayerToRoom(room *Room, player *Player) bool {
+	room.mu.Lock()
+	if len(room.Players) >= room.MaxPlayers {
+		room.mu.Unlock()
+		return false
+	}
+	room.Players[player.ID] = player
+	roomCode := room.Code
+	recorder := room.recorder
+	room.mu.Unlock()
+
+	player.mu.Lock()
+	player.Room = roomCode
+	player.IsReady = false
+	player.IsPlaying = true
+	player.mu.Unlock()
+
+	recorder.recordPlayerJoined(player)
+
+	// Authorize player for game session
+	gameID := extractGameIDFromURL(room.GameURL)
+	mu.RLock()
+	gameSession, exists := gameSessions[gameID]
+	mu.RUnlock()
+
+	if exists {
+		gameSession.authorize(player)
+	}
+
+	return true
+}
+
 func handlePlayerReady(player *Player) {
 	player.mu.Lock()
+	if player.IsSpectator {
+		player.mu.Unlock()
+		return
+	}
 	player.IsReady = true
 	roomCode := player.Room
 	isHost := player.IsHost
@@ -705,6 +913,17 @@ func handlePlayerReady(player *Player) {
 		return
 	}
 
+	room.mu.RLock()
+	recorder := room.recorder
+	room.mu.RUnlock()
+	gameID := recorder.gameIDOrEmpty()
+	recorder.enqueue(func() {
+		if err := services.MultiplayerDB.UpdatePlayerReady(gameID, player.ID, true); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	})
+	recorder.logEvent("player_ready", player.ID, nil, nil)
+
 	broadcastToRoom(room, "player_ready_update", map[string]interface{}{
 		"player_id": player.ID,
 		"players":   getPlayerList(room),
@@ -733,9 +952,6 @@ func handlePlayerReady(player *Player) {
 	}
 }
 
-func handleFindMatch(player *Player, _ interface{}) {
-	player.sendMessage("searching", map[string]interface{}{"players_waiting": 0})
-}
 
 // handleReconnect allows players to rejoin their game after navigating to the game page
 func handleReconnect(player *Player, payload interface{}) {
@@ -776,12 +992,18 @@ func handleReconnect(player *Player, payload interface{}) {
 		return
 	}
 
+	// Player IDs are visible to everyone in the room, so the seat must also
+	// belong to the same user (or be a guest's seat taken by a guest)
+	player.mu.RLock()
+	caller := seatUser(player.UserID, player.IsGuest)
+	player.mu.RUnlock()
 	session.mu.RLock()
 	authorized := session.AuthorizedPlayers[player.ID]
+	owner, known := session.SeatUsers[player.ID]
 	session.mu.RUnlock()
 
-	if !authorized {
-		log.Printf("⚠️  Player %s not authorized for game %s", player.ID, gameID)
+	if !authorized || !known || owner != caller {
+		log.Printf("⚠️  Player %s (user %d) not authorized for game %s", player.ID, caller, gameID)
 		player.sendMessage("error", map[string]interface{}{"error": "Not authorized for this game"})
 		return
 	}
@@ -791,13 +1013,41 @@ func handleReconnect(player *Player, payload interface{}) {
 	player.Room = targetRoom.Code
 	player.mu.Unlock()
 
-	// Update player in room's player map
+	// Update player in room's player map, carrying over the seat they held
 	targetRoom.mu.Lock()
+	previous, hadSeat := targetRoom.Players[player.ID]
+	_, hadScore := targetRoom.PlayerScores[player.ID]
+	isPlaying := hadScore
+	isHost := targetRoom.Host == player.ID
+	if hadSeat && previous != player {
+		previous.mu.RLock()
+		isPlaying = previous.IsPlaying
+		previous.mu.RUnlock()
+	}
 	targetRoom.Players[player.ID] = player
 	targetRoom.mu.Unlock()
 
+	player.mu.Lock()
+	player.IsPlaying = isPlaying
+	player.IsHost = isHost
+	player.IsReady = true
+	player.mu.Unlock()
+
+	// A room restored from a checkpoint starts its clock on the first reconnect
+	resumeRestoredRoom(targetRoom)
+
 	log.Printf("✅ Player %s reconnected to game %s (room %s)", player.ID, gameID, targetRoom.Code)
 
+	targetRoom.mu.RLock()
+	recorder := targetRoom.recorder
+	targetRoom.mu.RUnlock()
+	recorder.enqueue(func() {
+		if err := services.MultiplayerDB.RecordPlayerReconnect(gameID, player.ID); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	})
+	recorder.logEvent("player_reconnected", player.ID, nil, nil)
+
 	// Prepare current game state
 	targetRoom.mu.RLock()
 	currentQuestion := targetRoom.CurrentQuestion
@@ -807,8 +1057,13 @@ func handleReconnect(player *Player, payload interface{}) {
 	for pid, score := range targetRoom.PlayerScores {
 		playerScores[pid] = score
 	}
+	questions := clientQuestions(targetRoom.Questions)
+	alreadyAnswered := targetRoom.PlayersAnswered[player.ID]
+	timeRemaining := questionTimeRemaining(targetRoom)
 	targetRoom.mu.RUnlock()
 
+	checkpointRoom(targetRoom)
+
 	isGameStarted := roomState == "playing"
 
 	// Send confirmation with full game state
@@ -821,6 +1076,9 @@ func handleReconnect(player *Player, payload interface{}) {
 		"question_count":   questionCount,
 		"player_scores":    playerScores,
 		"players":          getPlayerList(targetRoom),
+		"questions":        questions,
+		"already_answered": alreadyAnswered,
+		"time_remaining":   timeRemaining,
 	})
 
 	// If game is in progress, send current question to help client sync
@@ -829,11 +1087,14 @@ func handleReconnect(player *Player, payload interface{}) {
 		player.sendMessage("question_sync", map[string]interface{}{
 			"question_index": currentQuestion,
 			"total":          questionCount,
+			"time_remaining": timeRemaining,
 		})
 	}
 }
 
-// handleSubmitAnswer processes player answers and broadcasts to room
+// handleSubmitAnswer grades a player's chosen option against the room's question set.
+// Clients only send the option they picked; correctness and points are decided here
+// and revealed to everyone once the question closes.
 func handleSubmitAnswer(player *Player, payload interface{}) {
 	player.mu.RLock()
 	roomCode := player.Room
@@ -853,26 +1114,29 @@ func handleSubmitAnswer(player *Player, payload interface{}) {
 		return
 	}
 
-	// Verify player is in this room and playing
-	room.mu.RLock()
-	_, inRoom := room.Players[player.ID]
-	room.mu.RUnlock()
-
-	if !inRoom {
-		log.Printf("⚠️  Player %s not authorized for room %s", player.ID, roomCode)
-		return
-	}
-
 	// Parse answer data - accept both snake_case and camelCase
 	data := parsePayload(payload)
 	questionIndex := getInt(data, "questionIndex", getInt(data, "question_index", -1))
-	isCorrect := getBool(data, "correct", getBool(data, "is_correct", false))
-	score := getInt(data, "score", 0)
+	answer := strings.TrimSpace(getString(data, "answer", ""))
+	answerIndex := getInt(data, "answer_index", -1)
 
 	room.mu.Lock()
 
+	// Verify player is in this room and playing
+	if _, inRoom := room.Players[player.ID]; !inRoom {
+		room.mu.Unlock()
+		log.Printf("⚠️  Player %s not authorized for room %s", player.ID, roomCode)
+		return
+	}
+
+	if room.State != "playing" {
+		room.mu.Unlock()
+		log.Printf("⚠️  Player %s submitted answer but room %s is %s", player.ID, roomCode, room.State)
+		return
+	}
+
 	// Validate question index matches current question
-	if questionIndex != room.CurrentQuestion {
+	if questionIndex != room.CurrentQuestion || questionIndex >= len(room.Questions) {
 		log.Printf("⚠️  Player %s submitted answer for Q%d but room is on Q%d", player.ID, questionIndex, room.CurrentQuestion)
 		room.mu.Unlock()
 		return
@@ -885,82 +1149,235 @@ func handleSubmitAnswer(player *Player, payload interface{}) {
 		return
 	}
 
+	question := room.Questions[questionIndex]
+	if answer == "" && answerIndex >= 0 && answerIndex < len(question.Options) {
+		answer = question.Options[answerIndex]
+	}
+
+	timeLimit := room.TimeLimit
+	if timeLimit == 0 {
+		timeLimit = 10
+	}
+	// The room clock runs until the longest time freeze; everyone else is
+	// still held to their own deadline. The freeze only extends the deadline,
+	// the speed bonus is still measured against the base limit.
+	playerLimit := timeLimit + room.TimeBonus[player.ID]
+	elapsed := time.Since(room.QuestionStartTime)
+	timedOut := elapsed > time.Duration(playerLimit)*time.Second+answerLatencyGrace
+	isCorrect, points := false, 0
+	if !timedOut {
+		isCorrect, points = gradeAnswer(question, answer, elapsed, timeLimit)
+	}
+
+	doubled := room.DoublePending[player.ID]
+	if doubled {
+		points *= 2
+		delete(room.DoublePending, player.ID)
+	}
+
 	// Mark player as answered for current question
 	room.PlayersAnswered[player.ID] = true
+	room.PlayerScores[player.ID] += points
+	if isCorrect {
+		room.PlayerCorrect[player.ID]++
+	} else {
+		room.PlayerWrong[player.ID]++
+	}
 
-	// Update player score (add points earned this question)
-	if _, exists := room.PlayerScores[player.ID]; !exists {
-		room.PlayerScores[player.ID] = 0
+	result := AnswerResult{
+		PlayerID:       player.ID,
+		Username:       player.Username,
+		Answer:         answer,
+		Correct:        isCorrect,
+		PointsEarned:   points,
+		ResponseTimeMs: elapsed.Milliseconds(),
+		Score:          room.PlayerScores[player.ID],
+		CorrectAnswers: room.PlayerCorrect[player.ID],
+		Doubled:        doubled,
+		TimedOut:       timedOut,
 	}
-	room.PlayerScores[player.ID] += score
-	playerTotalScore := room.PlayerScores[player.ID] // Store total for broadcast
+	room.QuestionResults[player.ID] = result
+	room.recorder.recordAnswer(questionIndex, question.ID, result, room.PlayerWrong[player.ID])
 
-	// Count playing players who have answered
-	playingCount := 0
-	answeredCount := 0
+	answeredCount, playingCount := countAnswered(room)
+	allAnswered := playingCount > 0 && answeredCount == playingCount
+
+	submitted := map[string]interface{}{
+		"player_id":      player.ID,
+		"username":       player.Username,
+		"question_index": questionIndex,
+		"answered_count": answeredCount,
+		"playing_count":  playingCount,
+		"all_answered":   allAnswered,
+	}
+	queueForSpectators(room, "answer_submitted", submitted)
+
+	room.mu.Unlock()
+
+	checkpointRoom(room)
+
+	// Let the room know this player has locked in. Correctness and scores are
+	// withheld until the reveal so nobody can relay the answer mid-question.
+	broadcastToRoom(room, "answer_submitted", submitted)
+
+	log.Printf("📝 Player %s answered Q%d (correct: %v, points: %d) - %d/%d answered, allAnswered=%v",
+		player.ID, questionIndex, isCorrect, points, answeredCount, playingCount, allAnswered)
+
+	// If all players have answered, close the question and move on
+	if allAnswered {
+		log.Printf("✅ All players answered Q%d, advancing to next question", questionIndex)
+		closeQuestion(room, questionIndex, "all_answered")
+	}
+}
+
+// countAnswered returns how many playing players have answered the current
+// question, and how many are playing. Caller must hold room.mu.
+func countAnswered(room *Room) (answered, playing int) {
 	for _, p := range room.Players {
 		p.mu.RLock()
 		if p.IsPlaying {
-			playingCount++
+			playing++
 			if room.PlayersAnswered[p.ID] {
-				answeredCount++
+				answered++
 			}
 		}
 		p.mu.RUnlock()
 	}
+	return answered, playing
+}
 
-	allAnswered := playingCount > 0 && answeredCount == playingCount
+// gradeAnswer checks an answer against the question and returns the points earned.
+// A correct answer is worth basePoints plus a bonus for every whole second left
+// of the base timeLimit; answers given in time-freeze seconds get no bonus.
+func gradeAnswer(question models.QuestionData, answer string, elapsed time.Duration, timeLimit int) (bool, int) {
+	if answer == "" || answer != strings.TrimSpace(question.CorrectAnswer) {
+		return false, 0
+	}
 
-	room.mu.Unlock()
+	if timeLimit <= 0 {
+		timeLimit = 10
+	}
 
-	// Broadcast this player's answer to all players in room
-	// Send TOTAL score so other players can see individual scores
-	broadcastToRoom(room, "answer_submitted", map[string]interface{}{
-		"player_id":      player.ID,
-		"username":       player.Username,
-		"question_index": questionIndex,
-		"correct":        isCorrect,
-		"score":          playerTotalScore,     // Player's total score
-		"points_earned":  score,                // Points earned this question
-		"all_answered":   allAnswered,
-	})
+	if limit := time.Duration(timeLimit) * time.Second; elapsed > limit {
+		elapsed = limit
+	}
+	remaining := timeLimit - int(elapsed/time.Second)
 
-	log.Printf("📝 Player %s answered Q%d (correct: %v, score: %d) - %d/%d answered, allAnswered=%v",
-		player.ID, questionIndex, isCorrect, score, answeredCount, playingCount, allAnswered)
+	return true, basePoints + remaining*timeBonusPerSecond
+}
 
-	// If all players have answered, prepare for next question
-	if allAnswered {
-		log.Printf("✅ All players answered Q%d, advancing to next question", questionIndex)
+// closeQuestion reveals the answer for the given question and advances the room.
+// It is safe to call from both the answer path and the timer; only the first
+// caller for a given question index has any effect.
+func closeQuestion(room *Room, questionIndex int, reason string) {
+	room.mu.Lock()
 
-		room.mu.Lock()
-		room.CurrentQuestion++
+	// Verify we're still on the same question (prevent race conditions)
+	if room.State != "playing" || room.CurrentQuestion != questionIndex {
+		room.mu.Unlock()
+		return
+	}
 
-		// Clear answered flags for next question
-		room.PlayersAnswered = make(map[string]bool)
+	if room.QuestionTimer != nil {
+		room.QuestionTimer.Stop()
+		room.QuestionTimer = nil
+	}
 
-		nextQuestion := room.CurrentQuestion
-		totalQuestions := room.QuestionCount
-		gameComplete := nextQuestion >= totalQuestions
+	reveal := map[string]interface{}{
+		"question_index": questionIndex,
+		"reason":         reason,
+	}
+	if questionIndex < len(room.Questions) {
+		q := room.Questions[questionIndex]
+		reveal["correct_answer"] = q.CorrectAnswer
+		reveal["reference"] = q.Reference
+	}
 
-		room.mu.Unlock()
+	results := make([]AnswerResult, 0, len(room.Players))
+	for pid, p := range room.Players {
+		p.mu.RLock()
+		isPlaying := p.IsPlaying
+		p.mu.RUnlock()
+		if !isPlaying {
+			continue
+		}
+		result, answered := room.QuestionResults[pid]
+		if !answered {
+			// No answer before the clock ran out
+			result = AnswerResult{
+				PlayerID:       pid,
+				Username:       p.Username,
+				Score:          room.PlayerScores[pid],
+				CorrectAnswers: room.PlayerCorrect[pid],
+			}
+		}
+		results = append(results, result)
+	}
+	reveal["results"] = results
 
-		if gameComplete {
-			log.Printf("🏁 Game complete in room %s", roomCode)
-			handleGameComplete(room)
-		} else {
-			log.Printf("➡️  Room %s advancing to Q%d/%d", roomCode, nextQuestion+1, totalQuestions)
+	playerScores := make(map[string]int, len(room.PlayerScores))
+	for pid, score := range room.PlayerScores {
+		playerScores[pid] = score
+	}
+	reveal["player_scores"] = playerScores
 
-			// Broadcast ready for next question
-			log.Printf("📢 Broadcasting next_question event: Q%d/%d to room %s", nextQuestion, totalQuestions, roomCode)
-			broadcastToRoom(room, "next_question", map[string]interface{}{
-				"question_index": nextQuestion,
-				"total":          totalQuestions,
-			})
+	recorder := room.recorder
+	closedIndex := questionIndex
+	recorder.logEvent("question_closed", "", &closedIndex, map[string]interface{}{
+		"reason":         reason,
+		"correct_answer": reveal["correct_answer"],
+		"results":        results,
+	})
+
+	spectators := spectatorList(room)
+	spectatorMessages := flushSpectatorQueue(room, questionIndex, reveal)
 
-			// Start timer for next question
-			startQuestionTimer(room)
+	// Clear answered flags for next question
+	room.PlayersAnswered = make(map[string]bool)
+	room.QuestionResults = make(map[string]AnswerResult)
+	room.PowerUpsUsed = make(map[string]map[string]bool)
+	room.TimeBonus = make(map[string]int)
+
+	room.CurrentQuestion++
+	nextQuestion := room.CurrentQuestion
+	totalQuestions := room.QuestionCount
+	gameComplete := nextQuestion >= totalQuestions
+	roomCode := room.Code
+
+	room.mu.Unlock()
+
+	broadcastToRoom(room, "question_reveal", reveal)
+	for _, s := range spectators {
+		for _, msg := range spectatorMessages {
+			s.sendMessage(msg.Type, msg.Payload)
 		}
 	}
+
+	if gameComplete {
+		log.Printf("🏁 Game complete in room %s (%s on last question)", roomCode, reason)
+		handleGameComplete(room)
+		return
+	}
+
+	log.Printf("➡️  Room %s advancing to Q%d/%d (%s)", roomCode, nextQuestion+1, totalQuestions, reason)
+
+	gameID := recorder.gameIDOrEmpty()
+	recorder.enqueue(func() {
+		if err := services.MultiplayerDB.UpdateCurrentQuestion(gameID, nextQuestion); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	})
+
+	// Broadcast ready for next question
+	broadcastToRoom(room, "next_question", map[string]interface{}{
+		"question_index": nextQuestion,
+		"total":          totalQuestions,
+		"reason":         reason,
+	})
+
+	// Start timer for next question
+	startQuestionTimer(room)
+	checkpointRoom(room)
 }
 
 func handleLeaveRoom(player *Player) {
@@ -980,9 +1397,18 @@ func handleLeaveRoom(player *Player) {
 		return
 	}
 
+	player.mu.RLock()
+	spectating := player.IsSpectator
+	player.mu.RUnlock()
+	if spectating {
+		removeSpectator(room, player)
+		return
+	}
+
 	room.mu.Lock()
 	delete(room.Players, player.ID)
 	playerCount := len(room.Players)
+	recorder := room.recorder
 
 	// Reassign host if host left
 	if room.Host == player.ID && playerCount > 0 {
@@ -1000,16 +1426,87 @@ func handleLeaveRoom(player *Player) {
 	player.Room = ""
 	player.mu.Unlock()
 
+	recorder.recordPlayerLeft(player.ID, "left")
+
 	// Delete room if empty
 	if playerCount == 0 {
-		mu.Lock()
-		delete(rooms, roomCode)
-		mu.Unlock()
+		closeRoom(room)
 	} else {
+		checkpointRoom(room)
 		broadcastRoomUpdate(room)
 	}
 }
 
+// closeRoom removes a room nobody is playing in any more: its question clock
+// stops, spectators are sent away, an unfinished game is recorded as abandoned
+// (so its checkpoint isn't restored as a ghost room) and the recorder is
+// released. Only the first call for a room does anything.
+func closeRoom(room *Room) {
+	room.mu.Lock()
+	if room.QuestionTimer != nil {
+		room.QuestionTimer.Stop()
+		room.QuestionTimer = nil
+	}
+	roomCode := room.Code
+	roomState := room.State
+	recorder := room.recorder
+	room.mu.Unlock()
+
+	mu.Lock()
+	current := rooms[roomCode] == room
+	if current {
+		delete(rooms, roomCode)
+	}
+	mu.Unlock()
+	if !current {
+		return
+	}
+
+	endSpectating(room, "room_closed")
+
+	if roomState != "completed" {
+		gameID := recorder.gameIDOrEmpty()
+		recorder.enqueue(func() {
+			if err := services.MultiplayerDB.AbandonGame(gameID); err != nil {
+				log.Printf("⚠️  %v", err)
+			}
+		})
+		markCheckpoint(recorder, "abandoned")
+	}
+	recorder.close()
+}
+
+// recordDisconnect persists a dropped connection for a player still in a room
+func recordDisconnect(player *Player) {
+	player.mu.RLock()
+	roomCode, spectating := player.Room, player.IsSpectator
+	player.mu.RUnlock()
+
+	if spectating {
+		return
+	}
+
+	mu.RLock()
+	room, exists := rooms[roomCode]
+	mu.RUnlock()
+
+	if !exists {
+		return
+	}
+
+	room.mu.RLock()
+	recorder := room.recorder
+	room.mu.RUnlock()
+
+	gameID := recorder.gameIDOrEmpty()
+	recorder.enqueue(func() {
+		if err := services.MultiplayerDB.RecordPlayerDisconnect(gameID, player.ID); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	})
+	recorder.logEvent("player_disconnected", player.ID, nil, nil)
+}
+
 // handlePlayerQuit handles when a player quits mid-game
 func handlePlayerQuit(player *Player, _ interface{}) {
 	player.mu.RLock()
@@ -1030,8 +1527,16 @@ func handlePlayerQuit(player *Player, _ interface{}) {
 		return
 	}
 
+	if leaveIfSpectating(player) {
+		return
+	}
+
 	log.Printf("🚪 Player %s (%s) quit from room %s", player.ID, player.Username, roomCode)
 
+	room.mu.RLock()
+	room.recorder.logEvent("player_quit", player.ID, nil, nil)
+	room.mu.RUnlock()
+
 	// Mark player as not playing (but keep in room for stats)
 	player.mu.Lock()
 	player.IsPlaying = false
@@ -1061,32 +1566,43 @@ func handlePlayerQuit(player *Player, _ interface{}) {
 	}
 	room.mu.RUnlock()
 
-	switch playingCount {
-	case 0:
-		// No players left playing, end the game
-		log.Printf("🏁 Game in room %s ended - all players quit", roomCode)
-		mu.Lock()
-		delete(rooms, roomCode)
-		mu.Unlock()
-	case 1:
+	if playingCount == 1 {
 		// Only one player left, they can continue solo
 		log.Printf("🎯 Game in room %s continues with 1 player", roomCode)
 	}
 
 	// Player stays connected but leaves the room
 	handleLeaveRoom(player)
+
+	if playingCount == 0 {
+		// No players left playing, end the game even if seats remain
+		log.Printf("🏁 Game in room %s ended - all players quit", roomCode)
+		closeRoom(room)
+	}
+}
+
+// fetchQuestionsForRoom retrieves questions from database for multiplayer game.
+// The returned set includes correct answers and must stay server-side; use
+// clientQuestions to build what players receive.
+func fetchQuestionsForRoom(room *Room) []models.QuestionData {
+	// Use deterministic seed based on GameID (fallback to room.Code if empty)
+	seedString := room.GameID
+	if seedString == "" {
+		seedString = room.Code
+	}
+	return fetchQuestions(room.SelectedThemes, room.QuestionCount, seedString, "")
 }
 
-// fetchQuestionsForRoom retrieves questions from database for multiplayer game
-func fetchQuestionsForRoom(room *Room) []map[string]interface{} {
+// fetchQuestions picks questionCount questions from the given themes (all themes
+// if none), shuffled deterministically from seedString, in a Bible translation
+// ("" for the themes as written)
+func fetchQuestions(themeIDs []int, questionCount int, seedString, translation string) []models.QuestionData {
 	db := database.GetDB()
 	if db == nil {
-		log.Printf("⚠️  Database not available for room %s", room.Code)
-		return []map[string]interface{}{}
+		log.Printf("⚠️  Database not available for game %s", seedString)
+		return []models.QuestionData{}
 	}
 
-	// Get question count and theme settings from room
-	questionCount := room.QuestionCount
 	if questionCount == 0 {
 		questionCount = 10 // default
 	}
@@ -1094,86 +1610,56 @@ func fetchQuestionsForRoom(room *Room) []map[string]interface{} {
 	// Build query
 	query := db.Model(&models.Question{}).Preload("Theme")
 
-	// Filter by selected themes if any
-	if len(room.SelectedThemes) > 0 {
-		query = query.Where("theme_id IN ?", room.SelectedThemes)
+	// Filter by selected themes if any; mixed games only draw from free themes
+	if len(themeIDs) > 0 {
+		query = query.Where("theme_id IN ?", themeIDs)
+	} else {
+		query = services.Shop.ExcludeLockedThemes(query, 0)
 	}
 
 	// Fetch all matching questions
 	var questions []models.Question
 	if err := query.Find(&questions).Error; err != nil {
-		log.Printf("⚠️  Error fetching questions for room %s: %v", room.Code, err)
-		return []map[string]interface{}{}
+		log.Printf("⚠️  Error fetching questions for game %s: %v", seedString, err)
+		return []models.QuestionData{}
 	}
-
-	// Use deterministic seed based on GameID (fallback to room.Code if empty)
-	seedString := room.GameID
-	if seedString == "" {
-		seedString = room.Code
+	if translated, err := services.Bible.TranslateQuestions(questions, translation); err == nil {
+		questions = translated
+	} else {
+		log.Printf("⚠️  Playing game %s untranslated: %v", seedString, err)
 	}
-	sum := sha256.Sum256([]byte(seedString))
-	seed := int64(binary.BigEndian.Uint64(sum[:8]))
-	rng := mathrand.New(mathrand.NewSource(seed))
-
-	// Shuffle questions deterministically
-	rng.Shuffle(len(questions), func(i, j int) {
-		questions[i], questions[j] = questions[j], questions[i]
-	})
 
-	log.Printf("🎲 Shuffled %d questions for game %s with deterministic seed", len(questions), seedString)
+	result := services.PickQuestions(questions, questionCount, seedString)
+	log.Printf("🎲 Picked %d of %d questions for game %s with deterministic seed", len(result), len(questions), seedString)
 
-	// Take only the requested number of questions
-	if len(questions) > questionCount {
-		questions = questions[:questionCount]
-	}
+	return result
+}
 
-	// Convert to response format
+// clientQuestions converts a question set into the payload sent to players.
+// Correct answers and references (often the answer itself) are stripped; they
+// are only revealed per question once it has been answered.
+func clientQuestions(questions []models.QuestionData) []map[string]interface{} {
 	result := make([]map[string]interface{}, 0, len(questions))
 	for _, q := range questions {
-		var wrongAnswers []string
-		if q.WrongAnswers != "" {
-			if err := json.Unmarshal([]byte(q.WrongAnswers), &wrongAnswers); err != nil {
-				log.Printf("⚠️  Error unmarshaling wrong answers: %v", err)
-				wrongAnswers = []string{}
-			}
-		}
-
-		// Build 4 options (1 correct + 3 wrong)
-		options := make([]string, 0, 4)
-		options = append(options, q.CorrectAnswer)
-
-		// Add wrong answers
-		for i := 0; i < 3 && i < len(wrongAnswers); i++ {
-			options = append(options, wrongAnswers[i])
-		}
-
-		// Shuffle options
-		rng.Shuffle(len(options), func(i, j int) {
-			options[i], options[j] = options[j], options[i]
-		})
-
-		themeName := ""
-		if q.Theme.ID != 0 {
-			themeName = q.Theme.Name
-		}
-
 		result = append(result, map[string]interface{}{
-			"id":             q.ID,
-			"theme_id":       q.ThemeID,
-			"theme_name":     themeName,
-			"text":           q.Text,
-			"correct_answer": q.CorrectAnswer,
-			"options":        options,
-			"reference":      q.Reference,
-			"difficulty":     q.Difficulty,
+			"id":         q.ID,
+			"theme_id":   q.ThemeID,
+			"theme_name": q.ThemeName,
+			"text":       q.Text,
+			"options":    q.Options,
+			"difficulty": q.Difficulty,
 		})
 	}
-
 	return result
 }
 
 // startQuestionTimer starts a timer for the current question
 func startQuestionTimer(room *Room) {
+	startQuestionTimerAt(room, 0)
+}
+
+// startQuestionTimerAt starts the current question's timer with some time already used
+func startQuestionTimerAt(room *Room, elapsed time.Duration) {
 	room.mu.Lock()
 
 	// Stop any existing timer
@@ -1186,32 +1672,31 @@ func startQuestionTimer(room *Room) {
 		timeLimit = 10 // default 10 seconds
 	}
 
-	room.QuestionStartTime = time.Now()
+	remaining := time.Duration(timeLimit)*time.Second - elapsed
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	room.QuestionStartTime = time.Now().Add(-elapsed)
 	currentQuestion := room.CurrentQuestion
-	room.mu.Unlock()
 
 	// Create timer for question timeout
-	room.mu.Lock()
-	room.QuestionTimer = time.AfterFunc(time.Duration(timeLimit)*time.Second, func() {
+	room.QuestionTimer = time.AfterFunc(remaining, func() {
 		handleQuestionTimeout(room, currentQuestion)
 	})
 	room.mu.Unlock()
 
-	log.Printf("⏱️  Started timer for Q%d in room %s (%ds)", currentQuestion+1, room.Code, timeLimit)
+	log.Printf("⏱️  Started timer for Q%d in room %s (%s left)", currentQuestion+1, room.Code, remaining.Round(time.Second))
 }
 
 // handleQuestionTimeout handles when time runs out for a question
 func handleQuestionTimeout(room *Room, expectedQuestion int) {
-	room.mu.Lock()
-
-	// Verify we're still on the same question (prevent race conditions)
+	room.mu.RLock()
 	if room.CurrentQuestion != expectedQuestion {
-		room.mu.Unlock()
+		room.mu.RUnlock()
 		return
 	}
 
-	log.Printf("⏰ Question %d timed out in room %s", expectedQuestion+1, room.Code)
-
 	// Count how many players haven't answered
 	unansweredCount := 0
 	playingCount := 0
@@ -1225,39 +1710,12 @@ func handleQuestionTimeout(room *Room, expectedQuestion int) {
 		}
 		p.mu.RUnlock()
 	}
+	room.mu.RUnlock()
 
+	log.Printf("⏰ Question %d timed out in room %s", expectedQuestion+1, room.Code)
 	log.Printf("⏰ Timeout: %d/%d players didn't answer", unansweredCount, playingCount)
 
-	// Clear PlayersAnswered for next question
-	room.PlayersAnswered = make(map[string]bool)
-
-	// Check if there are more questions
-	questionsRemaining := room.QuestionCount - (room.CurrentQuestion + 1)
-
-	if questionsRemaining > 0 {
-		// Advance to next question
-		room.CurrentQuestion++
-		nextQuestion := room.CurrentQuestion
-		totalQuestions := room.QuestionCount
-		room.mu.Unlock()
-
-		log.Printf("➡️  Room %s auto-advancing to Q%d/%d (timeout)", room.Code, nextQuestion+1, totalQuestions)
-
-		// Broadcast next question
-		broadcastToRoom(room, "next_question", map[string]interface{}{
-			"question_index": nextQuestion,
-			"total":          totalQuestions,
-			"reason":         "timeout",
-		})
-
-		// Start timer for next question
-		startQuestionTimer(room)
-	} else {
-		// Game complete
-		room.mu.Unlock()
-		log.Printf("🏁 Game complete in room %s (timeout on last question)", room.Code)
-		handleGameComplete(room)
-	}
+	closeQuestion(room, expectedQuestion, "timeout")
 }
 
 // handleGameComplete handles end-of-game logic and persists results
@@ -1275,12 +1733,22 @@ func handleGameComplete(room *Room) {
 	maxScore := 0
 	winnerID := ""
 
+	room.State = "completed"
+
+	// Snapshot scores so the persistence goroutine doesn't race the live maps
+	scores := make(map[string]int, len(room.PlayerScores))
+	correctCounts := make(map[string]int, len(room.PlayerScores))
+	participants := make(map[string]*Player, len(room.PlayerScores))
 	for pid, score := range room.PlayerScores {
 		if p, exists := room.Players[pid]; exists {
+			scores[pid] = score
+			correctCounts[pid] = room.PlayerCorrect[pid]
+			participants[pid] = p
 			finalScores = append(finalScores, map[string]interface{}{
-				"player_id": pid,
-				"username":  p.Username,
-				"score":     score,
+				"player_id":       pid,
+				"username":        p.Username,
+				"score":           score,
+				"correct_answers": room.PlayerCorrect[pid],
 			})
 
 			if score > maxScore {
@@ -1290,18 +1758,77 @@ func handleGameComplete(room *Room) {
 		}
 	}
 
+	// Placements drive ratings; equal score and correct count share a place
+	placements := services.RankPlacements(scores, correctCounts)
+	for _, entry := range finalScores {
+		entry["placement"] = placements[entry["player_id"].(string)]
+	}
+
+	// A game with one player left (solo fallback, or everyone else gone) has
+	// no winner: no win bonus or streak, no stored placement and no rating change
+	contested := len(scores) >= 2
+	recordedPlacements := placements
+	if !contested {
+		winnerID = ""
+		recordedPlacements = nil
+	}
+
 	roomCode := room.Code
 	questionCount := room.QuestionCount
 	timeLimit := room.TimeLimit
+	recorder := room.recorder
+	wrongCounts := make(map[string]int, len(scores))
+	for pid := range scores {
+		wrongCounts[pid] = room.PlayerWrong[pid]
+	}
 	room.mu.Unlock()
 
+	// Rewards only apply to registered players
+	gameID := recorder.gameIDOrEmpty()
+	outcomes := make(map[string]services.GameOutcome, len(scores))
+	for pid, score := range scores {
+		p := participants[pid]
+		if p.IsGuest || p.UserID == nil {
+			continue
+		}
+		outcomes[pid] = services.GameOutcome{
+			Mode:           services.ModeMultiplayer,
+			Reference:      gameID,
+			Difficulty:     "multiplayer",
+			Score:          score,
+			CorrectAnswers: correctCounts[pid],
+			TotalQuestions: questionCount,
+			TimeElapsed:    questionCount * timeLimit,
+			Won:            pid == winnerID,
+		}
+	}
+
+	// Final totals, then the placements the ratings are computed from
+	recorder.enqueue(func() {
+		for pid, score := range scores {
+			if err := services.MultiplayerDB.UpdatePlayerScore(gameID, pid, score, correctCounts[pid], wrongCounts[pid]); err != nil {
+				log.Printf("⚠️  %v", err)
+			}
+		}
+		if err := services.MultiplayerDB.CompleteGame(gameID, recordedPlacements); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	})
+	markCheckpoint(recorder, "completed")
+	recorder.logEvent("game_completed", "", nil, map[string]interface{}{
+		"final_scores": finalScores,
+		"winner_id":    winnerID,
+	})
+
 	log.Printf("🏁 Game complete in room %s - Final scores: %+v, Winner: %s", roomCode, finalScores, winnerID)
 
 	// Broadcast game complete
-	broadcastToRoom(room, "game_complete", map[string]interface{}{
+	complete := map[string]interface{}{
 		"final_scores": finalScores,
 		"winner_id":    winnerID,
-	})
+	}
+	broadcastToRoom(room, "game_complete", complete)
+	broadcastToSpectators(room, "game_complete", complete)
 
 	// Persist game results to database (async)
 	go func() {
@@ -1311,139 +1838,139 @@ func handleGameComplete(room *Room) {
 			return
 		}
 
-		// Process results for each player
-		for pid, score := range room.PlayerScores {
-			room.mu.RLock()
-			p, exists := room.Players[pid]
-			room.mu.RUnlock()
-
-			if !exists {
-				continue
-			}
-
-			// Skip guests - they don't have persistent accounts
-			if p.IsGuest || p.UserID == nil {
-				log.Printf("⏭️  Skipping result persistence for guest player %s (%s)", pid, p.Username)
-				continue
-			}
-
-			userID := *p.UserID
-			won := pid == winnerID
-			isPerfect := score == (questionCount * 100) // Assuming 100 points per question
-			correctAnswers := score / 100               // Rough estimate based on 100 points per correct answer
-
-			// Calculate XP and FP
-			xp := score / 10
-			faithPoints := score / 20
-			if won {
-				xp += 50 // Bonus for winning
-				faithPoints += 25
-			}
-			if isPerfect {
-				xp += 100 // Bonus for perfect game
-				faithPoints += 50
-			}
-
-			// Start transaction for atomicity
-			tx := db.Begin()
-
-			// Create attempt record
-			attempt := models.Attempt{
-				UserID:         userID,
-				ThemeID:        0, // Multiplayer games may use multiple themes
-				Score:          score,
-				CorrectAnswers: correctAnswers,
-				TotalQuestions: questionCount,
-				TimeElapsed:    questionCount * timeLimit,
-				IsPerfect:      isPerfect,
-				Difficulty:     "multiplayer",
-				XPEarned:       xp,
-				FPEarned:       faithPoints,
+		// Progression and ratings commit together. Rated players' rows are
+		// locked first (in ID order) so the ratings read here are the ones
+		// written back, and a concurrent season reset or admin edit isn't lost.
+		rewards := make(map[string]*services.RewardBreakdown, len(outcomes))
+		ratingBefore := make(map[string]float64, len(scores))
+		ratingResults := map[string]services.RatingResult{}
+		ratedUsers := make(map[uint]*models.User)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var userIDs []uint
+			for pid := range outcomes {
+				userIDs = append(userIDs, *participants[pid].UserID)
 			}
-
-			if err := tx.Create(&attempt).Error; err != nil {
-				tx.Rollback()
-				log.Printf("⚠️  Failed to persist game result for player %s (%s): %v", pid, p.Username, err)
-				continue
-			}
-
-			// Update user progression (wins, losses, XP, FP, rating)
-			var user models.User
-			if err := tx.First(&user, userID).Error; err != nil {
-				tx.Rollback()
-				log.Printf("⚠️  Failed to load user %d for progression update: %v", userID, err)
-				continue
-			}
-
-			oldLevel := user.Level
-			user.TotalGames++
-
-			if won {
-				user.Wins++
-				user.CurrentStreak++
-				if user.CurrentStreak > user.BestStreak {
-					user.BestStreak = user.CurrentStreak
+			if len(userIDs) > 0 {
+				var users []models.User
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					Select("id", "rating", "rating_deviation", "rating_volatility", "rated_games").
+					Where("id IN ?", userIDs).Order("id").Find(&users).Error; err != nil {
+					return err
+				}
+				for i := range users {
+					ratedUsers[users[i].ID] = &users[i]
 				}
-			} else {
-				user.Losses++
-				user.CurrentStreak = 0
 			}
 
-			if isPerfect {
-				user.PerfectGames++
+			// Rate the game from final placements. Guests count as opponents at
+			// the default rating but are never updated themselves.
+			ratingInputs := make([]services.RatingParticipant, 0, len(scores))
+			for pid := range scores {
+				p := participants[pid]
+				rp := services.RatingParticipant{
+					Key:        pid,
+					Rating:     services.DefaultRating,
+					Deviation:  services.DefaultDeviation,
+					Volatility: services.DefaultVolatility,
+					Placement:  placements[pid],
+				}
+				if !p.IsGuest && p.UserID != nil {
+					if u, ok := ratedUsers[*p.UserID]; ok {
+						rp.Rating, rp.Deviation, rp.Volatility, rp.Rated = u.Rating, u.RatingDeviation, u.RatingVolatility, true
+					}
+				}
+				ratingBefore[pid] = rp.Rating
+				ratingInputs = append(ratingInputs, rp)
 			}
-
-			user.XP += xp
-			user.FaithPoints += faithPoints
-
-			// Simple rating adjustment (ELO-like)
-			if won {
-				user.Rating += 0.1
-			} else {
-				user.Rating -= 0.05
+			if contested {
+				ratingResults = services.Ratings.RateGame(ratingInputs)
 			}
 
-			// Keep rating in bounds
-			if user.Rating > 10.0 {
-				user.Rating = 10.0
-			} else if user.Rating < 0.0 {
-				user.Rating = 0.0
-			}
+			for pid, outcome := range outcomes {
+				userID := *participants[pid].UserID
+				r, err := services.Progression.Apply(tx, userID, outcome)
+				if err != nil {
+					return fmt.Errorf("player %s: %w", pid, err)
+				}
+				rewards[pid] = r
 
-			// Handle level ups
-			for {
-				xpNeeded := (user.Level * user.Level * 100) // Simple level formula
-				if user.XP >= xpNeeded && user.Level < 100 {
-					user.Level++
-					user.XP -= xpNeeded
-					levelReward := 50 + (user.Level * 10)
-					user.FaithPoints += levelReward
-					log.Printf("🎉 Player %s leveled up to %d!", p.Username, user.Level)
-				} else {
-					break
+				// Glicko-2 rating update
+				rating, rated := ratingResults[pid]
+				if !rated {
+					continue
+				}
+				user := ratedUsers[userID]
+				user.Rating = rating.Rating
+				user.RatingDeviation = rating.Deviation
+				user.RatingVolatility = rating.Volatility
+				user.RatedGames++
+				if err := tx.Model(user).
+					Select("rating", "rating_deviation", "rating_volatility", "rated_games").
+					Updates(user).Error; err != nil {
+					return fmt.Errorf("player %s: %w", pid, err)
 				}
 			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("⚠️  Failed to persist game results for room %s: %v", roomCode, err)
+			return
+		}
 
-			if err := tx.Save(&user).Error; err != nil {
-				tx.Rollback()
-				log.Printf("⚠️  Failed to update user progression for %s: %v", p.Username, err)
-				continue
+		// Process results for each player
+		for pid, outcome := range outcomes {
+			p := participants[pid]
+			userID := *p.UserID
+			reward := rewards[pid]
+			rating, rated := ratingResults[pid]
+
+			// p may have disconnected or reconnected on a new socket by now
+			notifyUser(userID, "rewards", reward)
+			notifyLevelUp(userID, reward)
+			if err := services.MultiplayerDB.RecordPlayerRewards(gameID, pid, reward.XPEarned, reward.FPEarned); err != nil {
+				log.Printf("⚠️  %v", err)
 			}
-
-			if err := tx.Commit().Error; err != nil {
-				log.Printf("⚠️  Failed to commit game results for %s: %v", p.Username, err)
-				continue
+			if rated {
+				user := ratedUsers[userID]
+				if err := services.MultiplayerDB.RecordPlayerRating(gameID, pid, ratingBefore[pid], rating); err != nil {
+					log.Printf("⚠️  %v", err)
+				}
+				notifyUser(userID, "rating_update", map[string]interface{}{
+					"rating":           user.Rating,
+					"rating_change":    rating.Change,
+					"rating_deviation": user.RatingDeviation,
+					"provisional":      services.Ratings.IsProvisional(user.RatedGames, user.RatingDeviation),
+				})
 			}
 
-			log.Printf("✅ Persisted game for %s (UserID: %d) - Score: %d, Won: %v, XP: +%d, FP: +%d, Level: %d→%d",
-				p.Username, userID, score, won, xp, faithPoints, oldLevel, user.Level)
+			log.Printf("✅ Persisted game for %s (UserID: %d) - Score: %d, Won: %v, XP: +%d, FP: +%d, Level: %d→%d, Rating: %.0f→%.0f",
+				p.Username, userID, outcome.Score, outcome.Won, reward.XPEarned, reward.FPEarned,
+				reward.LevelBefore, reward.LevelAfter, ratingBefore[pid], rating.Rating)
+
+			awardAchievements(userID, services.AchievementEvent{
+				Type:           services.AchievementEventMultiplayer,
+				Score:          outcome.Score,
+				CorrectAnswers: outcome.CorrectAnswers,
+				TotalQuestions: outcome.TotalQuestions,
+				IsPerfect:      outcome.IsPerfect(),
+				Won:            outcome.Won,
+				Placement:      placements[pid],
+				Players:        len(scores),
+				TimeElapsed:    questionCount * timeLimit,
+				Reference:      gameID,
+			})
 		}
 	}()
 }
 
 func startGame(room *Room) {
-	room.mu.RLock()
-	defer room.mu.RUnlock()
+	room.mu.Lock()
+	if room.State == "starting" || room.State == "playing" {
+		room.mu.Unlock()
+		return
+	}
+	// Claim the start so a concurrent ready/start can't launch the game twice
+	room.State = "starting"
 
 	playingPlayersList := make([]map[string]interface{}, 0)
 	for _, p := range room.Players {
@@ -1461,6 +1988,7 @@ func startGame(room *Room) {
 
 	// Use the existing GameID from room (already stored in gameSessions)
 	gameID := room.GameID
+	room.mu.Unlock()
 
 	log.Printf("🎮 Starting game %s for room %s with %d players", gameID, room.Code, len(playingPlayersList))
 
@@ -1468,8 +1996,61 @@ func startGame(room *Room) {
 	questions := fetchQuestionsForRoom(room)
 	log.Printf("📚 Fetched %d questions for game %s", len(questions), gameID)
 
-	// Non-blocking broadcast of game start
+	room.mu.Lock()
+	room.Questions = questions
+	if len(questions) > 0 && len(questions) < room.QuestionCount {
+		// Small theme pools: only play as many questions as we can grade
+		room.QuestionCount = len(questions)
+	}
+	room.State = "playing"
+	room.StartedAt = time.Now()
+	room.CurrentQuestion = 0
+	room.PlayersAnswered = make(map[string]bool)
+	room.QuestionResults = make(map[string]AnswerResult)
+	room.PowerUpsUsed = make(map[string]map[string]bool)
+	room.TimeBonus = make(map[string]int)
+	room.DoublePending = make(map[string]bool)
+	for _, p := range room.Players {
+		p.mu.RLock()
+		if p.IsPlaying {
+			room.PlayerScores[p.ID] = 0
+			room.PlayerCorrect[p.ID] = 0
+			room.PlayerWrong[p.ID] = 0
+		}
+		p.mu.RUnlock()
+	}
+	questionsPayload := clientQuestions(questions)
+	recipients := make([]*Player, 0, len(room.Players))
 	for _, p := range room.Players {
+		recipients = append(recipients, p)
+	}
+	recorder := room.recorder
+	questionCount := room.QuestionCount
+	room.mu.Unlock()
+
+	// Spectators learn who is playing now, but see each question only once it closes
+	broadcastToSpectators(room, "game_start", map[string]interface{}{
+		"room_code":      room.Code,
+		"players":        playingPlayersList,
+		"game_id":        gameID,
+		"is_playing":     false,
+		"is_spectating":  true,
+		"question_count": questionCount,
+	})
+
+	recorder.enqueue(func() {
+		if err := services.MultiplayerDB.StartGame(gameID); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	})
+	recorder.logEvent("game_started", "", nil, map[string]interface{}{
+		"players":        playingPlayersList,
+		"question_count": questionCount,
+		"questions":      questions,
+	})
+
+	// Non-blocking broadcast of game start
+	for _, p := range recipients {
 		p.mu.RLock()
 		isPlaying := p.IsPlaying
 		p.mu.RUnlock()
@@ -1479,12 +2060,13 @@ func startGame(room *Room) {
 			"players":    playingPlayersList,
 			"game_id":    gameID,
 			"is_playing": isPlaying,
-			"questions":  questions, // ✅ Include synchronized questions
+			"questions":  questionsPayload, // ✅ Synchronized questions, answers withheld
 		})
 	}
 
 	// Start timer for first question
 	startQuestionTimer(room)
+	checkpointRoom(room)
 }
 
 // broadcastToRoom sends a message to all players in a room (non-blocking)
@@ -1507,19 +2089,25 @@ func broadcastRoomUpdate(room *Room) {
 	defer room.mu.RUnlock()
 
 	payload := map[string]interface{}{
-		"room_code":       room.Code,
-		"host":            room.Host,
-		"players":         getPlayerList(room),
-		"max_players":     room.MaxPlayers,
-		"selected_themes": room.SelectedThemes,
-		"question_count":  room.QuestionCount,
-		"time_limit":      room.TimeLimit,
+		"room_code":        room.Code,
+		"host":             room.Host,
+		"players":          getPlayerList(room),
+		"max_players":      room.MaxPlayers,
+		"selected_themes":  room.SelectedThemes,
+		"question_count":   room.QuestionCount,
+		"time_limit":       room.TimeLimit,
+		"allow_spectators": room.AllowSpectators,
+		"max_spectators":   room.MaxSpectators,
+		"spectator_count":  len(room.Spectators),
 	}
 
 	// Non-blocking broadcast
 	for _, p := range room.Players {
 		p.sendMessage("room_update", payload)
 	}
+	for _, s := range room.Spectators {
+		s.sendMessage("room_update", payload)
+	}
 }
 
 func getPlayerList(room *Room) []map[string]interface{} {
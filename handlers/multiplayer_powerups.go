@@ -0,0 +1,189 @@
+// handlers/multiplayer_powerups.go - Power-ups spent during multiplayer games
+package handlers
+
+import (
+	"errors"
+	"log"
+	"math/rand"
+	"time"
+	"ubible/services"
+)
+
+// handleUsePowerUp spends one of the player's power-ups on the current
+// question and applies its effect server-side
+func handleUsePowerUp(player *Player, payload interface{}) {
+	player.mu.RLock()
+	roomCode := player.Room
+	userID := player.UserID
+	player.mu.RUnlock()
+
+	data := parsePayload(payload)
+	kind := services.PowerUps.NormalizeType(getString(data, "type", getString(data, "powerup", "")))
+	questionIndex := getInt(data, "questionIndex", getInt(data, "question_index", -1))
+
+	if userID == nil {
+		player.sendMessage("error", map[string]interface{}{"error": "Sign in to use power-ups"})
+		return
+	}
+
+	mu.RLock()
+	room, exists := rooms[roomCode]
+	mu.RUnlock()
+
+	if !exists {
+		player.sendMessage("error", map[string]interface{}{"error": "Room not found"})
+		return
+	}
+
+	room.mu.RLock()
+	reason := powerUpBlockedReason(room, player.ID, kind, questionIndex)
+	room.mu.RUnlock()
+	if reason != "" {
+		player.sendMessage("error", map[string]interface{}{"error": reason})
+		return
+	}
+
+	remaining, err := services.PowerUps.Consume(*userID, kind)
+	if err != nil {
+		msg := "Failed to use power-up"
+		if errors.Is(err, services.ErrNoPowerUp) || errors.Is(err, services.ErrUnknownPowerUp) {
+			msg = err.Error()
+		} else {
+			log.Printf("⚠️  Power-up %s for player %s: %v", kind, player.ID, err)
+		}
+		player.sendMessage("error", map[string]interface{}{"error": msg})
+		return
+	}
+
+	room.mu.Lock()
+
+	// The question may have closed while the inventory was being updated
+	if reason := powerUpBlockedReason(room, player.ID, kind, questionIndex); reason != "" {
+		room.mu.Unlock()
+		if err := services.PowerUps.Refund(*userID, kind); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+		player.sendMessage("error", map[string]interface{}{"error": reason})
+		return
+	}
+
+	if room.PowerUpsUsed[player.ID] == nil {
+		room.PowerUpsUsed[player.ID] = make(map[string]bool)
+	}
+	room.PowerUpsUsed[player.ID][kind] = true
+
+	question := room.Questions[questionIndex]
+	result := map[string]interface{}{
+		"type":           kind,
+		"question_index": questionIndex,
+		"remaining":      remaining,
+	}
+
+	allAnswered := false
+	switch kind {
+	case services.PowerUpFiftyFifty:
+		rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+		result["removed_options"] = services.PowerUps.FiftyFifty(question.Options, question.CorrectAnswer, rng)
+
+	case services.PowerUpTimeFreeze:
+		room.TimeBonus[player.ID] += services.TimeFreezeSeconds
+		result["extra_seconds"] = room.TimeBonus[player.ID]
+		extendQuestionTimer(room)
+
+	case services.PowerUpHint:
+		result["hint"] = services.PowerUps.Hint(question.CorrectAnswer)
+
+	case services.PowerUpSkip:
+		// A skip counts as answered for zero points but not as a wrong answer
+		room.PlayersAnswered[player.ID] = true
+		skipped := AnswerResult{
+			PlayerID:       player.ID,
+			Username:       player.Username,
+			ResponseTimeMs: time.Since(room.QuestionStartTime).Milliseconds(),
+			Score:          room.PlayerScores[player.ID],
+			CorrectAnswers: room.PlayerCorrect[player.ID],
+			Skipped:        true,
+		}
+		room.QuestionResults[player.ID] = skipped
+		room.recorder.recordAnswer(questionIndex, question.ID, skipped, room.PlayerWrong[player.ID])
+		result["skipped"] = true
+
+		answeredCount, playingCount := countAnswered(room)
+		allAnswered = playingCount > 0 && answeredCount == playingCount
+
+	case services.PowerUpDouble:
+		room.DoublePending[player.ID] = true
+		result["doubled"] = true
+	}
+
+	room.recorder.logEvent("powerup_used", player.ID, &questionIndex, map[string]interface{}{
+		"type":      kind,
+		"remaining": remaining,
+	})
+
+	room.mu.Unlock()
+
+	checkpointRoom(room)
+
+	player.sendMessage("powerup_result", result)
+	broadcastToRoom(room, "powerup_used", map[string]interface{}{
+		"player_id":      player.ID,
+		"username":       player.Username,
+		"type":           kind,
+		"question_index": questionIndex,
+	})
+
+	log.Printf("✨ Player %s used %s on Q%d in room %s (%d left)", player.ID, kind, questionIndex+1, roomCode, remaining)
+
+	if allAnswered {
+		closeQuestion(room, questionIndex, "all_answered")
+	}
+}
+
+// powerUpBlockedReason returns why a power-up can't be used right now, or ""
+// if it can. Caller must hold room.mu.
+func powerUpBlockedReason(room *Room, playerID, kind string, questionIndex int) string {
+	if _, inRoom := room.Players[playerID]; !inRoom {
+		return "Not in this room"
+	}
+	if room.State != "playing" || room.paused {
+		return "Game is not in progress"
+	}
+	if questionIndex != room.CurrentQuestion || questionIndex >= len(room.Questions) {
+		return "Question is no longer active"
+	}
+	if room.PlayersAnswered[playerID] {
+		return "Already answered this question"
+	}
+	if room.PowerUpsUsed[playerID][kind] {
+		return "Power-up already used on this question"
+	}
+	return ""
+}
+
+// extendQuestionTimer keeps the question open until the longest time freeze
+// in the room runs out. The extra time is only the frozen player's: answers
+// from anyone else are graded against their own deadline. Caller must hold room.mu.
+func extendQuestionTimer(room *Room) {
+	timeLimit := room.TimeLimit
+	if timeLimit == 0 {
+		timeLimit = 10
+	}
+
+	longest := 0
+	for _, bonus := range room.TimeBonus {
+		if bonus > longest {
+			longest = bonus
+		}
+	}
+
+	deadline := room.QuestionStartTime.Add(time.Duration(timeLimit+longest) * time.Second)
+	if room.QuestionTimer != nil {
+		room.QuestionTimer.Stop()
+	}
+
+	currentQuestion := room.CurrentQuestion
+	room.QuestionTimer = time.AfterFunc(time.Until(deadline), func() {
+		handleQuestionTimeout(room, currentQuestion)
+	})
+}
@@ -0,0 +1,161 @@
+// handlers/multiplayer_recorder.go - Write-through persistence for multiplayer rooms
+package handlers
+
+import (
+	"log"
+	"sync"
+	"ubible/database"
+	"ubible/services"
+)
+
+// recorderQueueSize bounds the number of pending writes per room
+const recorderQueueSize = 512
+
+// gameRecorder serialises all database writes for one room's game.
+// Writes run in enqueue order on a single goroutine, so the game row exists
+// before its players, and events land with strictly increasing sequence numbers.
+type gameRecorder struct {
+	gameID string
+	seq    int64
+	queue  chan func()
+	closed bool
+	mu     sync.Mutex
+}
+
+// newGameRecorder starts the writer goroutine for a game
+func newGameRecorder(gameID string) *gameRecorder {
+	r := &gameRecorder{
+		gameID: gameID,
+		queue:  make(chan func(), recorderQueueSize),
+	}
+	go r.run()
+	return r
+}
+
+func (r *gameRecorder) run() {
+	for fn := range r.queue {
+		fn()
+	}
+}
+
+// enqueue schedules a write. It never blocks the game loop; if the database is
+// unavailable or the queue is full the write is dropped and logged.
+func (r *gameRecorder) enqueue(fn func()) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.push(fn)
+}
+
+// push must be called with r.mu held
+func (r *gameRecorder) push(fn func()) {
+	if r.closed || database.GetDB() == nil {
+		return
+	}
+
+	select {
+	case r.queue <- fn:
+	default:
+		log.Printf("⚠️  Recorder queue full for game %s, dropping write", r.gameID)
+	}
+}
+
+// logEvent appends an event to the game's log. The sequence number is assigned
+// under the same lock that orders the queue, so it matches insertion order.
+func (r *gameRecorder) logEvent(eventType, playerID string, questionIndex *int, data map[string]interface{}) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	seq := r.seq
+	gameID := r.gameID
+	r.push(func() {
+		if err := services.MultiplayerDB.LogEvent(gameID, eventType, playerID, questionIndex, data, seq); err != nil {
+			log.Printf("⚠️  Failed to log %s event for game %s: %v", eventType, gameID, err)
+		}
+	})
+}
+
+// close stops accepting writes; pending writes still drain
+func (r *gameRecorder) close() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+}
+
+// recordPlayerJoined persists a player joining the room
+func (r *gameRecorder) recordPlayerJoined(p *Player) {
+	p.mu.RLock()
+	playerID, username, userID := p.ID, p.Username, p.UserID
+	isGuest, isHost, isPlaying := p.IsGuest, p.IsHost, p.IsPlaying
+	p.mu.RUnlock()
+
+	gameID := r.gameIDOrEmpty()
+	r.enqueue(func() {
+		if _, err := services.MultiplayerDB.AddPlayer(gameID, playerID, username, userID, isGuest, isHost, isPlaying); err != nil {
+			log.Printf("⚠️  Failed to persist player %s for game %s: %v", playerID, gameID, err)
+		}
+	})
+	r.logEvent("player_joined", playerID, nil, map[string]interface{}{
+		"username":   username,
+		"is_guest":   isGuest,
+		"is_host":    isHost,
+		"is_playing": isPlaying,
+	})
+}
+
+// recordPlayerLeft persists a player leaving the room
+func (r *gameRecorder) recordPlayerLeft(playerID, reason string) {
+	gameID := r.gameIDOrEmpty()
+	r.enqueue(func() {
+		if err := services.MultiplayerDB.RecordPlayerLeft(gameID, playerID); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	})
+	r.logEvent("player_left", playerID, nil, map[string]interface{}{"reason": reason})
+}
+
+// recordAnswer persists a graded answer and the player's running totals
+func (r *gameRecorder) recordAnswer(questionIndex, questionID int, result AnswerResult, wrongAnswers int) {
+	gameID := r.gameIDOrEmpty()
+	qi := questionIndex
+	r.enqueue(func() {
+		if err := services.MultiplayerDB.UpdatePlayerScore(gameID, result.PlayerID, result.Score, result.CorrectAnswers, wrongAnswers); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	})
+	r.logEvent("answer_submitted", result.PlayerID, &qi, map[string]interface{}{
+		"question_id":      questionID,
+		"answer":           result.Answer,
+		"correct":          result.Correct,
+		"points_earned":    result.PointsEarned,
+		"response_time_ms": result.ResponseTimeMs,
+		"score":            result.Score,
+		"correct_answers":  result.CorrectAnswers,
+		"skipped":          result.Skipped,
+		"doubled":          result.Doubled,
+	})
+}
+
+// gameIDOrEmpty is nil-safe access to the recorder's game ID
+func (r *gameRecorder) gameIDOrEmpty() string {
+	if r == nil {
+		return ""
+	}
+	return r.gameID
+}
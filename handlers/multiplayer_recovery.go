@@ -0,0 +1,370 @@
+// handlers/multiplayer_recovery.go - Checkpointing and crash recovery for multiplayer rooms
+package handlers
+
+import (
+	"log"
+	"time"
+	"ubible/database"
+	"ubible/models"
+	"ubible/services"
+)
+
+// restoreGracePeriod is how long a rehydrated room waits for a player to reconnect
+const restoreGracePeriod = 5 * time.Minute
+
+// checkpointRoom snapshots an in-progress room into active_game_states.
+// Must be called without holding room.mu.
+func checkpointRoom(room *Room) {
+	room.mu.RLock()
+	gameID := room.GameID
+	state := room.State
+	room.mu.RUnlock()
+
+	if state != "playing" {
+		return
+	}
+
+	authorized := make(map[string]bool)
+	expiresAt := time.Now().Add(24 * time.Hour)
+	mu.RLock()
+	session, exists := gameSessions[gameID]
+	mu.RUnlock()
+	if exists {
+		session.mu.RLock()
+		for pid, ok := range session.AuthorizedPlayers {
+			authorized[pid] = ok
+		}
+		expiresAt = session.ExpiresAt
+		session.mu.RUnlock()
+	}
+
+	room.mu.RLock()
+	ags := &models.ActiveGameState{
+		GameID:               room.GameID,
+		RoomCode:             room.Code,
+		GameToken:            room.GameToken,
+		IsMultiplayer:        true,
+		CurrentQuestionIndex: room.CurrentQuestion,
+		TotalQuestions:       room.QuestionCount,
+		TimeLimit:            room.TimeLimit,
+		TimeRemaining:        questionTimeRemaining(room),
+		QuestionStartedAt:    room.QuestionStartTime,
+		Status:               "active",
+		StartedAt:            room.StartedAt,
+		ExpiresAt:            expiresAt,
+		HostPlayerID:         room.Host,
+	}
+
+	sessions := make(map[string]models.PlayerSession, len(room.Players))
+	for pid, p := range room.Players {
+		p.mu.RLock()
+		ps := models.PlayerSession{
+			PlayerID:       pid,
+			Username:       p.Username,
+			UserID:         p.UserID,
+			IsGuest:        p.IsGuest,
+			IsHost:         p.IsHost,
+			IsPlaying:      p.IsPlaying,
+			Score:          room.PlayerScores[pid],
+			CorrectAnswers: room.PlayerCorrect[pid],
+			WrongAnswers:   room.PlayerWrong[pid],
+			Answered:       room.PlayersAnswered[pid],
+			TimeBonus:      room.TimeBonus[pid],
+			DoublePending:  room.DoublePending[pid],
+		}
+		p.mu.RUnlock()
+		for kind, used := range room.PowerUpsUsed[pid] {
+			if used {
+				ps.PowerUpsUsed = append(ps.PowerUpsUsed, kind)
+			}
+		}
+		if result, ok := room.QuestionResults[pid]; ok {
+			ps.Answer = result.Answer
+			ps.AnswerCorrect = result.Correct
+			ps.PointsEarned = result.PointsEarned
+			ps.ResponseTimeMs = result.ResponseTimeMs
+		}
+		sessions[pid] = ps
+	}
+
+	err := ags.SetQuestionsData(room.Questions)
+	if err == nil {
+		err = ags.SetSelectedThemes(room.SelectedThemes)
+	}
+	if err == nil {
+		err = ags.SetAuthorizedPlayers(authorized)
+	}
+	if err == nil {
+		err = ags.SetPlayerSessions(sessions)
+	}
+	recorder := room.recorder
+	room.mu.RUnlock()
+
+	if err != nil {
+		log.Printf("⚠️  Failed to build checkpoint for game %s: %v", gameID, err)
+		return
+	}
+
+	recorder.enqueue(func() {
+		if err := services.GameState.SaveCheckpoint(ags); err != nil {
+			log.Printf("⚠️  Checkpoint for game %s: %v", gameID, err)
+		}
+	})
+}
+
+// markCheckpoint records the final status of a game's checkpoint
+func markCheckpoint(recorder *gameRecorder, status string) {
+	gameID := recorder.gameIDOrEmpty()
+	recorder.enqueue(func() {
+		if err := services.GameState.UpdateStatus(gameID, status); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	})
+}
+
+// questionTimeRemaining returns whole seconds left on the current question.
+// Caller must hold room.mu.
+func questionTimeRemaining(room *Room) int {
+	timeLimit := room.TimeLimit
+	if timeLimit == 0 {
+		timeLimit = 10
+	}
+
+	if room.paused {
+		return timeLimit - int(room.pausedElapsed/time.Second)
+	}
+
+	if room.QuestionStartTime.IsZero() {
+		return timeLimit
+	}
+
+	remaining := timeLimit - int(time.Since(room.QuestionStartTime)/time.Second)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining
+}
+
+// RestoreActiveGames rehydrates in-progress multiplayer games from their last
+// checkpoint. Restored rooms stay paused until a player reconnects.
+func RestoreActiveGames() {
+	if database.GetDB() == nil {
+		return
+	}
+
+	states, err := services.GameState.GetRecoverableGames()
+	if err != nil {
+		log.Printf("⚠️  Failed to load game checkpoints: %v", err)
+		return
+	}
+
+	restored := 0
+	for i := range states {
+		if restoreRoom(&states[i]) {
+			restored++
+		}
+	}
+
+	if len(states) > 0 {
+		log.Printf("♻️  Restored %d/%d in-progress multiplayer games", restored, len(states))
+	}
+}
+
+// restoreRoom rebuilds a room and its game session from a checkpoint
+func restoreRoom(ags *models.ActiveGameState) bool {
+	questions, err := ags.GetQuestionsData()
+	if err != nil || len(questions) == 0 || ags.CurrentQuestionIndex >= len(questions) {
+		log.Printf("⚠️  Cannot restore game %s: invalid question set (%v)", ags.GameID, err)
+		services.GameState.UpdateStatus(ags.GameID, "abandoned")
+		return false
+	}
+
+	sessions, err := ags.GetPlayerSessions()
+	if err != nil || len(sessions) == 0 {
+		log.Printf("⚠️  Cannot restore game %s: no player sessions (%v)", ags.GameID, err)
+		services.GameState.UpdateStatus(ags.GameID, "abandoned")
+		return false
+	}
+
+	themes, _ := ags.GetSelectedThemes()
+	authorized, _ := ags.GetAuthorizedPlayers()
+
+	mu.RLock()
+	_, codeTaken := rooms[ags.RoomCode]
+	mu.RUnlock()
+	if codeTaken {
+		log.Printf("⚠️  Cannot restore game %s: room code %s already in use", ags.GameID, ags.RoomCode)
+		return false
+	}
+
+	timeLimit := ags.TimeLimit
+	if timeLimit == 0 {
+		timeLimit = 10
+	}
+	elapsed := time.Duration(timeLimit-ags.TimeRemaining) * time.Second
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	room := &Room{
+		Code:            ags.RoomCode,
+		Host:            ags.HostPlayerID,
+		Players:         make(map[string]*Player),
+		MaxPlayers:      10,
+		State:           "playing",
+		SelectedThemes:  themes,
+		QuestionCount:   ags.TotalQuestions,
+		TimeLimit:       timeLimit,
+		GameID:          ags.GameID,
+		GameURL:         "/game/" + ags.GameID,
+		GameToken:       ags.GameToken,
+		CurrentQuestion: ags.CurrentQuestionIndex,
+		PlayersAnswered: make(map[string]bool),
+		PlayerScores:    make(map[string]int),
+		PlayerCorrect:   make(map[string]int),
+		PlayerWrong:     make(map[string]int),
+		Questions:       questions,
+		QuestionResults: make(map[string]AnswerResult),
+		PowerUpsUsed:    make(map[string]map[string]bool),
+		TimeBonus:       make(map[string]int),
+		DoublePending:   make(map[string]bool),
+		Spectators:      make(map[string]*Player),
+		AllowSpectators: true,
+		MaxSpectators:   defaultMaxSpectators,
+		StartedAt:       ags.StartedAt,
+		recorder:        newGameRecorder(ags.GameID),
+		paused:          true,
+		pausedElapsed:   elapsed,
+	}
+
+	// Placeholder players hold seats and scores until their owners reconnect,
+	// and only those owners may take them back
+	seatUsers := make(map[string]uint, len(sessions))
+	for pid, ps := range sessions {
+		seatUsers[pid] = seatUser(ps.UserID, ps.IsGuest)
+		room.Players[pid] = &Player{
+			ID:        pid,
+			UserID:    ps.UserID,
+			Username:  ps.Username,
+			IsGuest:   ps.IsGuest,
+			Room:      ags.RoomCode,
+			IsReady:   true,
+			IsHost:    ps.IsHost,
+			IsPlaying: ps.IsPlaying,
+		}
+		room.PlayerScores[pid] = ps.Score
+		room.PlayerCorrect[pid] = ps.CorrectAnswers
+		room.PlayerWrong[pid] = ps.WrongAnswers
+		if ps.TimeBonus > 0 {
+			room.TimeBonus[pid] = ps.TimeBonus
+		}
+		if ps.DoublePending {
+			room.DoublePending[pid] = true
+		}
+		if len(ps.PowerUpsUsed) > 0 {
+			room.PowerUpsUsed[pid] = make(map[string]bool, len(ps.PowerUpsUsed))
+			for _, kind := range ps.PowerUpsUsed {
+				room.PowerUpsUsed[pid][kind] = true
+			}
+		}
+		if ps.Answered {
+			room.PlayersAnswered[pid] = true
+			room.QuestionResults[pid] = AnswerResult{
+				PlayerID:       pid,
+				Username:       ps.Username,
+				Answer:         ps.Answer,
+				Correct:        ps.AnswerCorrect,
+				PointsEarned:   ps.PointsEarned,
+				ResponseTimeMs: ps.ResponseTimeMs,
+				Score:          ps.Score,
+				CorrectAnswers: ps.CorrectAnswers,
+			}
+		}
+	}
+
+	// Continue the event log where it left off
+	if seq, err := services.MultiplayerDB.GetLastEventSequence(ags.GameID); err == nil {
+		room.recorder.seq = seq
+	}
+
+	gameSession := &GameSession{
+		GameID:            ags.GameID,
+		RoomCode:          ags.RoomCode,
+		Token:             ags.GameToken,
+		AuthorizedPlayers: authorized,
+		SeatUsers:         seatUsers,
+		CreatedAt:         ags.StartedAt,
+		ExpiresAt:         ags.ExpiresAt,
+	}
+
+	mu.Lock()
+	rooms[ags.RoomCode] = room
+	gameSessions[ags.GameID] = gameSession
+	mu.Unlock()
+
+	questionIndex := ags.CurrentQuestionIndex
+	room.recorder.logEvent("game_restored", "", &questionIndex, map[string]interface{}{
+		"time_remaining": ags.TimeRemaining,
+		"players":        len(sessions),
+	})
+
+	log.Printf("♻️  Restored game %s (room %s) at Q%d/%d with %ds remaining",
+		ags.GameID, ags.RoomCode, questionIndex+1, ags.TotalQuestions, ags.TimeRemaining)
+
+	time.AfterFunc(restoreGracePeriod, func() {
+		abandonIfPaused(room)
+	})
+
+	return true
+}
+
+// resumeRestoredRoom restarts the question clock of a rehydrated room on the
+// first reconnect, crediting the time already used before the restart
+func resumeRestoredRoom(room *Room) {
+	room.mu.Lock()
+	if !room.paused {
+		room.mu.Unlock()
+		return
+	}
+	room.paused = false
+	elapsed := room.pausedElapsed
+	questionIndex := room.CurrentQuestion
+	recorder := room.recorder
+	room.mu.Unlock()
+
+	log.Printf("▶️  Resuming restored room %s at Q%d", room.Code, questionIndex+1)
+	recorder.logEvent("game_resumed", "", &questionIndex, nil)
+	startQuestionTimerAt(room, elapsed)
+}
+
+// abandonIfPaused tears down a restored room nobody came back to
+func abandonIfPaused(room *Room) {
+	room.mu.Lock()
+	if !room.paused {
+		room.mu.Unlock()
+		return
+	}
+	room.paused = false
+	room.State = "abandoned"
+	roomCode := room.Code
+	gameID := room.GameID
+	recorder := room.recorder
+	room.mu.Unlock()
+
+	mu.Lock()
+	if rooms[roomCode] == room {
+		delete(rooms, roomCode)
+	}
+	delete(gameSessions, gameID)
+	mu.Unlock()
+
+	log.Printf("🗑️  Restored game %s abandoned - no players reconnected", gameID)
+
+	recorder.enqueue(func() {
+		if err := services.MultiplayerDB.AbandonGame(gameID); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	})
+	markCheckpoint(recorder, "abandoned")
+	recorder.close()
+}
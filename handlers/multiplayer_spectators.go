@@ -0,0 +1,257 @@
+// handlers/multiplayer_spectators.go - Watching multiplayer rooms without playing
+package handlers
+
+import "log"
+
+const (
+	defaultMaxSpectators = 20 // Spectator seats in a new room
+	maxSpectatorsLimit   = 50 // Most spectator seats a host can open
+)
+
+// handleSpectate seats the player as a spectator in the room named by
+// room_code or game_url. Spectators never count toward the players who must
+// answer, and each question's traffic reaches them only once it has closed.
+func handleSpectate(player *Player, payload interface{}) {
+	data := parsePayload(payload)
+	room := findSpectateRoom(getString(data, "room_code", ""), getString(data, "game_url", ""))
+	if room == nil {
+		player.sendMessage("error", map[string]interface{}{"error": "Room not found"})
+		return
+	}
+
+	player.mu.RLock()
+	currentRoom, spectating := player.Room, player.IsSpectator
+	player.mu.RUnlock()
+	if currentRoom == room.Code && spectating {
+		return
+	}
+
+	// Spectating takes the player out of matchmaking and any room they were in
+	matchmaker.remove(player)
+	if currentRoom != "" {
+		handleLeaveRoom(player)
+	}
+
+	room.mu.Lock()
+	switch {
+	case room.State == "completed":
+		room.mu.Unlock()
+		player.sendMessage("error", map[string]interface{}{"error": "This game has finished"})
+		return
+	case !room.AllowSpectators:
+		room.mu.Unlock()
+		player.sendMessage("error", map[string]interface{}{"error": "Spectating is turned off for this room"})
+		return
+	case len(room.Spectators) >= room.MaxSpectators:
+		room.mu.Unlock()
+		player.sendMessage("error", map[string]interface{}{"error": "Spectator seats are full"})
+		return
+	}
+	if room.Spectators == nil {
+		room.Spectators = make(map[string]*Player)
+	}
+	room.Spectators[player.ID] = player
+
+	// Scores as of the last closed question; answers to the open one stay hidden
+	playerScores := make(map[string]int, len(room.PlayerScores))
+	for pid, score := range room.PlayerScores {
+		playerScores[pid] = score - room.QuestionResults[pid].PointsEarned
+	}
+	joined := map[string]interface{}{
+		"room_code":        room.Code,
+		"host":             room.Host,
+		"players":          getPlayerList(room),
+		"state":            room.State,
+		"game_url":         room.GameURL,
+		"question_count":   room.QuestionCount,
+		"time_limit":       room.TimeLimit,
+		"current_question": room.CurrentQuestion,
+		"player_scores":    playerScores,
+		"spectator_count":  len(room.Spectators),
+	}
+	room.mu.Unlock()
+
+	player.mu.Lock()
+	player.Room = room.Code
+	player.IsSpectator = true
+	player.IsPlaying = false
+	player.IsReady = false
+	player.IsHost = false
+	player.mu.Unlock()
+
+	log.Printf("👀 %s is spectating room %s", player.Username, room.Code)
+
+	player.sendMessage("spectate_joined", joined)
+	broadcastRoomUpdate(room)
+}
+
+// handleSpectatorSettings lets the host turn spectating on or off and cap the
+// number of spectators. Turning it off sends current spectators away; a lower
+// cap only applies to new spectators.
+func handleSpectatorSettings(player *Player, payload interface{}) {
+	player.mu.RLock()
+	roomCode, isHost := player.Room, player.IsHost
+	player.mu.RUnlock()
+
+	if !isHost {
+		player.sendMessage("error", map[string]interface{}{"error": "Only the host can change spectator settings"})
+		return
+	}
+
+	mu.RLock()
+	room, exists := rooms[roomCode]
+	mu.RUnlock()
+	if !exists {
+		player.sendMessage("error", map[string]interface{}{"error": "Room not found"})
+		return
+	}
+
+	data := parsePayload(payload)
+	room.mu.Lock()
+	room.AllowSpectators = getBool(data, "allow_spectators", room.AllowSpectators)
+	room.MaxSpectators = clampSpectators(getInt(data, "max_spectators", room.MaxSpectators))
+	allow := room.AllowSpectators
+	room.mu.Unlock()
+
+	if !allow {
+		endSpectating(room, "spectating_disabled")
+	}
+	broadcastRoomUpdate(room)
+}
+
+// findSpectateRoom looks a room up by code, or by game URL or ID
+func findSpectateRoom(roomCode, gameURL string) *Room {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if room, ok := rooms[roomCode]; ok {
+		return room
+	}
+	if gameURL == "" {
+		return nil
+	}
+	gameID := extractGameIDFromURL(gameURL)
+	if gameID == "" {
+		gameID = gameURL
+	}
+	for _, room := range rooms {
+		if room.GameID == gameID {
+			return room
+		}
+	}
+	return nil
+}
+
+// removeSpectator takes a spectator out of their room
+func removeSpectator(room *Room, player *Player) {
+	room.mu.Lock()
+	delete(room.Spectators, player.ID)
+	room.mu.Unlock()
+
+	player.mu.Lock()
+	player.Room = ""
+	player.IsSpectator = false
+	player.mu.Unlock()
+
+	broadcastRoomUpdate(room)
+}
+
+// endSpectating sends every spectator away from a room
+func endSpectating(room *Room, reason string) {
+	room.mu.Lock()
+	spectators := room.Spectators
+	room.Spectators = make(map[string]*Player)
+	room.spectatorQueue = nil
+	roomCode := room.Code
+	room.mu.Unlock()
+
+	// The copied spectators may disconnect once the lock is released;
+	// sendMessage drops messages for closed connections, and anyone already
+	// gone is skipped outright
+	for _, s := range spectators {
+		s.mu.Lock()
+		if s.Room == roomCode {
+			s.Room = ""
+			s.IsSpectator = false
+		}
+		s.mu.Unlock()
+		if s.isClosed() {
+			continue
+		}
+		s.sendMessage("spectate_ended", map[string]interface{}{
+			"room_code": roomCode,
+			"reason":    reason,
+		})
+	}
+}
+
+// spectatorList returns a room's spectators. Caller must hold room.mu.
+func spectatorList(room *Room) []*Player {
+	list := make([]*Player, 0, len(room.Spectators))
+	for _, s := range room.Spectators {
+		list = append(list, s)
+	}
+	return list
+}
+
+// broadcastToSpectators sends a message to every spectator in a room (non-blocking)
+func broadcastToSpectators(room *Room, msgType string, payload interface{}) {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+
+	for _, s := range room.Spectators {
+		s.sendMessage(msgType, payload)
+	}
+}
+
+// queueForSpectators holds a message about the open question back from
+// spectators until it closes. Caller must hold room.mu.
+func queueForSpectators(room *Room, msgType string, payload interface{}) {
+	if len(room.Spectators) == 0 {
+		return
+	}
+	room.spectatorQueue = append(room.spectatorQueue, Message{Type: msgType, Payload: payload})
+}
+
+// flushSpectatorQueue builds what spectators see once a question closes: the
+// question, the answers as they came in, then the reveal. Caller must hold room.mu.
+func flushSpectatorQueue(room *Room, questionIndex int, reveal map[string]interface{}) []Message {
+	queued := room.spectatorQueue
+	room.spectatorQueue = nil
+	if len(room.Spectators) == 0 || questionIndex >= len(room.Questions) {
+		return nil
+	}
+
+	messages := make([]Message, 0, len(queued)+2)
+	messages = append(messages, Message{Type: "spectate_question", Payload: map[string]interface{}{
+		"question_index": questionIndex,
+		"total":          room.QuestionCount,
+		"question":       clientQuestions(room.Questions[questionIndex : questionIndex+1])[0],
+	}})
+	messages = append(messages, queued...)
+	return append(messages, Message{Type: "question_reveal", Payload: reveal})
+}
+
+// clampSpectators keeps a spectator cap between 0 and maxSpectatorsLimit
+func clampSpectators(n int) int {
+	if n < 0 {
+		return 0
+	}
+	if n > maxSpectatorsLimit {
+		return maxSpectatorsLimit
+	}
+	return n
+}
+
+// leaveIfSpectating takes a spectator out of the room they're watching and
+// reports whether they were spectating
+func leaveIfSpectating(player *Player) bool {
+	player.mu.RLock()
+	spectating := player.IsSpectator
+	player.mu.RUnlock()
+
+	if spectating {
+		handleLeaveRoom(player)
+	}
+	return spectating
+}
@@ -0,0 +1,192 @@
+// handlers/notifications.go - Notification inbox and mute preferences
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"ubible/middleware"
+	"ubible/models"
+	"ubible/services"
+	"ubible/utils"
+)
+
+func init() {
+	services.Notifications.Deliver = notifyUser
+}
+
+// GetNotifications returns a page of the user's notifications, newest first
+// GET /api/notifications?unread=true&category=&page=1&limit=20
+func GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	category := utils.Query(r, "category", "")
+	if category != "" && !services.IsNotificationCategory(category) {
+		writeNotificationError(w, services.ErrUnknownNotificationCategory)
+		return
+	}
+	page, err := strconv.Atoi(utils.Query(r, "page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(utils.Query(r, "limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+	unreadOnly := utils.Query(r, "unread", "") == "true"
+
+	notifications, total, err := services.Notifications.List(userID, unreadOnly, category, limit, (page-1)*limit)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch notifications")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":       true,
+		"notifications": notifications,
+		"total":         total,
+		"unread":        services.Notifications.UnreadCount(userID),
+		"page":          page,
+		"limit":         limit,
+	})
+}
+
+// GetUnreadNotificationCount returns how many notifications are unread
+// GET /api/notifications/unread-count
+func GetUnreadNotificationCount(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"unread":  services.Notifications.UnreadCount(userID),
+	})
+}
+
+// MarkNotificationsRead marks notifications read: the listed `ids`, or every
+// unread one (in `category`, if given) when `ids` is empty
+// POST /api/notifications/read
+func MarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req struct {
+		IDs      []uint `json:"ids"`
+		Category string `json:"category"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	if req.Category != "" && !services.IsNotificationCategory(req.Category) {
+		writeNotificationError(w, services.ErrUnknownNotificationCategory)
+		return
+	}
+
+	marked, err := services.Notifications.MarkRead(userID, req.IDs, req.Category)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to mark notifications read")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"marked":  marked,
+		"unread":  services.Notifications.UnreadCount(userID),
+	})
+}
+
+// GetNotificationPreferences returns which categories the user has muted
+// GET /api/notifications/preferences
+func GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"categories": models.NotificationCategories,
+		"muted":      services.Notifications.Mutes(userID),
+	})
+}
+
+// UpdateNotificationPreferences mutes or unmutes categories, given as a map
+// of category to muted
+// POST /api/notifications/preferences/update
+func UpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req struct {
+		Muted map[string]bool `json:"muted"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	for category := range req.Muted {
+		if !services.IsNotificationCategory(category) {
+			writeNotificationError(w, services.ErrUnknownNotificationCategory)
+			return
+		}
+	}
+
+	for category, muted := range req.Muted {
+		if err := services.Notifications.SetMuted(userID, category, muted); err != nil {
+			writeNotificationError(w, err)
+			return
+		}
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"categories": models.NotificationCategories,
+		"muted":      services.Notifications.Mutes(userID),
+	})
+}
+
+// notifyLevelUp tells a user they reached a new level after a finished game
+func notifyLevelUp(userID uint, rewards *services.RewardBreakdown) {
+	if rewards == nil || rewards.LevelAfter <= rewards.LevelBefore {
+		return
+	}
+
+	fp := 0
+	for _, item := range rewards.Items {
+		if item.Type == "level_up" {
+			fp += item.FP
+		}
+	}
+	services.Notifications.Notify(userID, models.NotificationCategoryProgress, "level_up",
+		fmt.Sprintf("You reached level %d!", rewards.LevelAfter),
+		fmt.Sprintf("Level-up reward: %d Faith Points", fp),
+		map[string]interface{}{
+			"level_before": rewards.LevelBefore,
+			"level":        rewards.LevelAfter,
+			"fp_reward":    fp,
+		})
+}
+
+func writeNotificationError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrUnknownNotificationCategory) {
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.JSONError(w, http.StatusInternalServerError, "Failed to update notification preferences")
+}
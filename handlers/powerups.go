@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+	"ubible/database"
+	"ubible/middleware"
+	"ubible/models"
+	"ubible/services"
+	"ubible/utils"
+
+	"gorm.io/gorm"
+)
+
+// UsePowerUpRequest spends a power-up on a single-player quiz question. With a
+// session_id the effect applies to that quiz session's current question.
+type UsePowerUpRequest struct {
+	Type          string   `json:"type"`
+	SessionID     string   `json:"session_id"`
+	QuestionIndex int      `json:"question_index"`
+	QuestionID    uint     `json:"question_id"`
+	Options       []string `json:"options"` // Options as shown to the player (optional)
+}
+
+// GetPowerUpInventory returns the current user's power-up counts
+func GetPowerUpInventory(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	inventory, err := services.PowerUps.Inventory(userID)
+	if err != nil {
+		utils.JSONError(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"inventory": inventory,
+	})
+}
+
+// UsePowerUp spends a power-up during a single-player quiz and returns its effect
+func UsePowerUp(w http.ResponseWriter, r *http.Request) {
+	db := database.GetDB()
+
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req UsePowerUpRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	kind := services.PowerUps.NormalizeType(req.Type)
+
+	if req.SessionID != "" {
+		usePowerUpInSession(w, userID, kind, req)
+		return
+	}
+
+	// Load the question before spending anything so a bad ID costs nothing
+	var question models.Question
+	if err := db.First(&question, req.QuestionID).Error; err != nil {
+		utils.JSONError(w, http.StatusNotFound, "Question not found")
+		return
+	}
+
+	remaining, err := services.PowerUps.Consume(userID, kind)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownPowerUp):
+			utils.JSONError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrNoPowerUp):
+			utils.JSONError(w, http.StatusConflict, err.Error())
+		default:
+			log.Printf("⚠️  Power-up %s for user %d: %v", kind, userID, err)
+			utils.JSONError(w, http.StatusInternalServerError, "Failed to use power-up")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":     true,
+		"type":        kind,
+		"question_id": question.ID,
+		"remaining":   remaining,
+	}
+
+	switch kind {
+	case services.PowerUpFiftyFifty:
+		options := req.Options
+		if len(options) == 0 {
+			var wrongAnswers []string
+			if question.WrongAnswers != "" {
+				if err := json.Unmarshal([]byte(question.WrongAnswers), &wrongAnswers); err != nil {
+					log.Printf("⚠️  Failed to parse wrong answers for question %d: %v", question.ID, err)
+				}
+			}
+			options = append([]string{question.CorrectAnswer}, wrongAnswers...)
+		}
+		rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+		response["removed_options"] = services.PowerUps.FiftyFifty(options, question.CorrectAnswer, rng)
+	case services.PowerUpTimeFreeze:
+		response["extra_seconds"] = services.TimeFreezeSeconds
+	case services.PowerUpHint:
+		response["hint"] = services.PowerUps.Hint(question.CorrectAnswer)
+	case services.PowerUpSkip:
+		response["skipped"] = true
+	case services.PowerUpDouble:
+		response["doubled"] = true
+	}
+
+	utils.JSON(w, http.StatusOK, response)
+}
+
+// usePowerUpInSession spends a power-up on a quiz session's current question so
+// the server's grading honours it
+func usePowerUpInSession(w http.ResponseWriter, userID uint, kind string, req UsePowerUpRequest) {
+	response := map[string]interface{}{
+		"success":        true,
+		"type":           kind,
+		"session_id":     req.SessionID,
+		"question_index": req.QuestionIndex,
+	}
+
+	_, err := services.GameState.UpdateSoloSession(req.SessionID, userID, func(tx *gorm.DB, s *models.ActiveGameState) error {
+		questions, state, err := soloQuestion(s, req.QuestionIndex)
+		if err != nil {
+			return err
+		}
+		for _, used := range state.PowerUpsUsed {
+			if used == kind {
+				return errPowerUpRepeated
+			}
+		}
+
+		remaining, err := services.PowerUps.ConsumeTx(tx, userID, kind)
+		if err != nil {
+			return err
+		}
+		response["remaining"] = remaining
+		state.PowerUpsUsed = append(state.PowerUpsUsed, kind)
+
+		question := questions[req.QuestionIndex]
+		switch kind {
+		case services.PowerUpFiftyFifty:
+			rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+			response["removed_options"] = services.PowerUps.FiftyFifty(question.Options, question.CorrectAnswer, rng)
+		case services.PowerUpTimeFreeze:
+			state.TimeBonus += services.TimeFreezeSeconds
+			response["extra_seconds"] = services.TimeFreezeSeconds
+		case services.PowerUpHint:
+			response["hint"] = services.PowerUps.Hint(question.CorrectAnswer)
+		case services.PowerUpSkip:
+			// Skipped questions score nothing but don't count as wrong or break the streak
+			response["skipped"] = true
+			response["correct_answer"] = question.CorrectAnswer
+			response["reference"] = question.Reference
+			return advanceSoloQuestion(s, state, models.SoloAnswer{
+				QuestionIndex:  req.QuestionIndex,
+				QuestionID:     question.ID,
+				ResponseTimeMs: time.Since(s.QuestionStartedAt).Milliseconds(),
+				Skipped:        true,
+			})
+		case services.PowerUpDouble:
+			state.DoubleNext = true
+			response["doubled"] = true
+		}
+
+		return s.SetSoloState(*state)
+	})
+	if err != nil {
+		writeQuizSessionError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, response)
+}
@@ -10,6 +10,7 @@ import (
 	"ubible/database"
 	"ubible/middleware"
 	"ubible/models"
+	"ubible/services"
 	"ubible/utils"
 )
 
@@ -127,16 +128,40 @@ func GetPracticeCards(w http.ResponseWriter, r *http.Request) {
 	limit, _ := strconv.Atoi(utils.Query(r, "limit", "200"))
 	offset, _ := strconv.Atoi(utils.Query(r, "offset", "0"))
 
+	// Cards with a known passage can be shown in another translation
+	var bible *services.BibleText
+	translation, err := requestTranslation(r, utils.Query(r, "translation", ""))
+	if err != nil {
+		writeBibleError(w, err)
+		return
+	}
+	if translation != "" {
+		if bible, err = services.Bible.Text(translation); err != nil {
+			writeBibleError(w, err)
+			return
+		}
+	}
+
 	var questions []models.Question
 	query := db.Model(&models.Question{}).Preload("Theme")
+	userID, _ := middleware.GetUserID(r)
 
 	// If specific theme requested, use it
 	if themeID != "" {
-		query = query.Where("theme_id = ?", themeID)
+		id, err := strconv.Atoi(themeID)
+		if err != nil {
+			utils.JSONError(w, http.StatusBadRequest, "Invalid theme ID")
+			return
+		}
+		if !requireThemeAccess(w, userID, []int{id}) {
+			return
+		}
+		query = query.Where("theme_id = ?", id)
 	} else {
-		// Otherwise, filter by user's selected themes if user is authenticated
-		userID, err := middleware.GetUserID(r)
-		if err == nil && userID > 0 {
+		// Otherwise, filter by user's selected themes if user is authenticated,
+		// leaving out paid themes they haven't unlocked
+		query = services.Shop.ExcludeLockedThemes(query, userID)
+		if userID > 0 {
 			var user models.User
 			if err := db.First(&user, userID).Error; err == nil && user.SelectedThemes != "" {
 				var selectedThemes []int
@@ -161,10 +186,16 @@ func GetPracticeCards(w http.ResponseWriter, r *http.Request) {
 			themeName = q.Theme.Name // Fallback to relationship if needed
 		}
 		verseText, _ := reconstructVerse(q)
+		ref := q.Reference
+		if bible != nil && q.OSIS != "" {
+			if translatedRef, translatedText, err := bible.Passage(q.OSIS); err == nil {
+				ref, verseText = translatedRef, translatedText
+			}
+		}
 		if verseText == "" {
 			continue
 		}
-		key := strings.TrimSpace(q.Reference) + "||" + verseText
+		key := strings.TrimSpace(ref) + "||" + verseText
 		if _, ok := seen[key]; ok {
 			continue
 		}
@@ -174,7 +205,7 @@ func GetPracticeCards(w http.ResponseWriter, r *http.Request) {
 			ID:        q.ID,
 			ThemeID:   q.ThemeID,
 			ThemeName: themeName,
-			Reference: q.Reference,
+			Reference: ref,
 			VerseText: verseText,
 		})
 	}
@@ -4,16 +4,19 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"strings"
 	"ubible/database"
 	"ubible/middleware"
 	"ubible/models"
+	"ubible/services"
 	"ubible/utils"
 )
 
 type PreferencesRequest struct {
-	SelectedThemes    []int `json:"selected_themes"`
-	QuizTimeLimit     int   `json:"quiz_time_limit"`
-	QuizQuestionCount int   `json:"quiz_question_count"`
+	SelectedThemes       []int   `json:"selected_themes"`
+	QuizTimeLimit        int     `json:"quiz_time_limit"`
+	QuizQuestionCount    int     `json:"quiz_question_count"`
+	PreferredTranslation *string `json:"preferred_translation"` // Omitted keeps it; "" plays themes as written
 }
 
 // SavePreferences saves user's quiz preferences
@@ -48,6 +51,15 @@ func SavePreferences(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var translation string
+	if req.PreferredTranslation != nil {
+		translation = strings.ToUpper(strings.TrimSpace(*req.PreferredTranslation))
+		if translation != "" && !services.Bible.Has(translation) {
+			utils.JSONError(w, http.StatusBadRequest, "Unknown translation")
+			return
+		}
+	}
+
 	// Convert selected themes to JSON
 	themesJSON, err := json.Marshal(req.SelectedThemes)
 	if err != nil {
@@ -57,11 +69,15 @@ func SavePreferences(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update user preferences
-	result := db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+	updates := map[string]interface{}{
 		"selected_themes":     string(themesJSON),
 		"quiz_time_limit":     req.QuizTimeLimit,
 		"quiz_question_count": req.QuizQuestionCount,
-	})
+	}
+	if req.PreferredTranslation != nil {
+		updates["preferred_translation"] = translation
+	}
+	result := db.Model(&models.User{}).Where("id = ?", userID).Updates(updates)
 
 	if result.Error != nil {
 		log.Printf("Error saving preferences: %v", result.Error)
@@ -101,9 +117,10 @@ func GetPreferences(w http.ResponseWriter, r *http.Request) {
 	}
 
 	utils.JSON(w, http.StatusOK, map[string]interface{}{
-		"success":             true,
-		"selected_themes":     selectedThemes,
-		"quiz_time_limit":     user.QuizTimeLimit,
-		"quiz_question_count": user.QuizQuestionCount,
+		"success":               true,
+		"selected_themes":       selectedThemes,
+		"quiz_time_limit":       user.QuizTimeLimit,
+		"quiz_question_count":   user.QuizQuestionCount,
+		"preferred_translation": user.PreferredTranslation,
 	})
 }
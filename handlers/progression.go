@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"ubible/database"
+	"ubible/middleware"
+	"ubible/models"
+	"ubible/services"
+	"ubible/utils"
+)
+
+// GetProgression returns the current user's level, XP curve position and game stats
+func GetProgression(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var user models.User
+	if err := database.GetDB().First(&user, userID).Error; err != nil {
+		utils.JSONError(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	xpToNext := services.Progression.XPForLevel(user.Level)
+	progress := 100.0
+	if user.Level < services.MaxLevel && xpToNext > 0 {
+		progress = float64(user.XP) * 100 / float64(xpToNext)
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":          true,
+		"level":            user.Level,
+		"max_level":        services.MaxLevel,
+		"xp":               user.XP,
+		"xp_to_next_level": xpToNext,
+		"progress_percent": progress,
+		"next_level_fp":    services.Progression.LevelReward(user.Level + 1),
+		"faith_points":     user.FaithPoints,
+		"total_games":      user.TotalGames,
+		"wins":             user.Wins,
+		"losses":           user.Losses,
+		"current_streak":   user.CurrentStreak,
+		"best_streak":      user.BestStreak,
+		"perfect_games":    user.PerfectGames,
+	})
+}
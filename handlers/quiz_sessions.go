@@ -0,0 +1,484 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"ubible/database"
+	"ubible/middleware"
+	"ubible/models"
+	"ubible/services"
+	"ubible/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	soloSessionTTL   = 2 * time.Hour
+	soloRevealDelay  = 2 * time.Second // Client shows the answer this long before the next question
+	soloLatencyGrace = 2 * time.Second // Allowance for answers sent right at the deadline
+	soloWinAccuracy  = 70              // Percent correct that counts as a win for streaks
+)
+
+var (
+	errWrongQuestion   = errors.New("that question is not the current question")
+	errQuizComplete    = errors.New("all questions have been answered")
+	errPowerUpRepeated = errors.New("power-up already used on this question")
+)
+
+// StartQuizRequest configures a single-player quiz
+type StartQuizRequest struct {
+	ThemeIDs      []int  `json:"theme_ids"`
+	QuestionCount int    `json:"question_count"`
+	TimeLimit     int    `json:"time_limit"`
+	Translation   string `json:"translation"` // Defaults to the user's preferred translation
+}
+
+// SubmitQuizAnswerRequest answers the session's current question
+type SubmitQuizAnswerRequest struct {
+	QuestionIndex int    `json:"question_index"`
+	Answer        string `json:"answer"`
+	AnswerIndex   *int   `json:"answer_index"`
+}
+
+// StartQuizSession issues a server-graded single-player quiz. Questions are
+// sent without their answers.
+func StartQuizSession(w http.ResponseWriter, r *http.Request) {
+	db := database.GetDB()
+
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req StartQuizRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		utils.JSONError(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	// Fall back to the user's saved quiz preferences
+	if req.QuestionCount == 0 {
+		req.QuestionCount = user.QuizQuestionCount
+	}
+	if req.TimeLimit == 0 {
+		req.TimeLimit = user.QuizTimeLimit
+	}
+	if req.QuestionCount < 1 || req.QuestionCount > 100 {
+		utils.JSONError(w, http.StatusBadRequest, "Question count must be between 1 and 100")
+		return
+	}
+	if req.TimeLimit < 5 || req.TimeLimit > 300 {
+		utils.JSONError(w, http.StatusBadRequest, "Time limit must be between 5 and 300 seconds")
+		return
+	}
+
+	if !requireThemeAccess(w, userID, req.ThemeIDs) {
+		return
+	}
+
+	translation, err := requestTranslation(r, req.Translation)
+	if err != nil {
+		writeBibleError(w, err)
+		return
+	}
+
+	sessionID := uuid.NewString()
+	questions := fetchQuestions(req.ThemeIDs, req.QuestionCount, sessionID, translation)
+	if len(questions) == 0 {
+		utils.JSONError(w, http.StatusNotFound, "No questions available for the selected themes")
+		return
+	}
+
+	session, err := createSoloSession(userID, sessionID, questions, req.TimeLimit, req.ThemeIDs, nil, nil, time.Now().Add(soloSessionTTL))
+	if err != nil {
+		log.Printf("⚠️  Failed to create quiz session for user %d: %v", userID, err)
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to start quiz")
+		return
+	}
+
+	log.Printf("🎯 User %d started quiz session %s (%d questions, %ds each)", userID, sessionID, len(questions), req.TimeLimit)
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":                true,
+		"session_id":             session.GameID,
+		"time_limit":             session.TimeLimit,
+		"total_questions":        session.TotalQuestions,
+		"current_question_index": 0,
+		"questions":              clientQuestions(questions),
+	})
+}
+
+// createSoloSession saves a new single-player session and marks it as the
+// user's active game. challengeID ties the attempt to a team challenge and
+// dailyChallengeID to a daily or weekly challenge.
+func createSoloSession(userID uint, sessionID string, questions []models.QuestionData, timeLimit int, themeIDs []int, challengeID, dailyChallengeID *uint, expiresAt time.Time) (*models.ActiveGameState, error) {
+	now := time.Now()
+	session := &models.ActiveGameState{
+		GameID:            sessionID,
+		GameToken:         uuid.NewString(),
+		IsSinglePlayer:    true,
+		UserID:            &userID,
+		ChallengeID:       challengeID,
+		DailyChallengeID:  dailyChallengeID,
+		TotalQuestions:    len(questions),
+		TimeLimit:         timeLimit,
+		TimeRemaining:     timeLimit,
+		QuestionStartedAt: now,
+		Status:            "active",
+		StartedAt:         now,
+		ExpiresAt:         expiresAt,
+	}
+	err := session.SetQuestionsData(questions)
+	if err == nil {
+		err = session.SetSelectedThemes(themeIDs)
+	}
+	if err == nil {
+		err = session.SetSoloState(models.SoloState{Answers: []models.SoloAnswer{}})
+	}
+	if err == nil {
+		err = services.GameState.SaveCheckpoint(session)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	database.GetDB().Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"active_game_session": sessionID,
+		"game_started_at":     now,
+	})
+
+	return session, nil
+}
+
+// GetQuizSession returns a session's progress so a refreshed page can resume
+func GetQuizSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	session, err := services.GameState.GetSoloSession(r.PathValue("id"), userID)
+	if err != nil {
+		utils.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, quizSessionView(session))
+}
+
+// quizSessionView is a session's progress as sent to its player
+func quizSessionView(session *models.ActiveGameState) map[string]interface{} {
+	questions, _ := session.GetQuestionsData()
+	state, _ := session.GetSoloState()
+
+	timeRemaining := session.TimeLimit + state.TimeBonus - int(time.Since(session.QuestionStartedAt)/time.Second)
+	if timeRemaining > session.TimeLimit+state.TimeBonus {
+		timeRemaining = session.TimeLimit + state.TimeBonus // Still in the reveal delay
+	} else if timeRemaining < 0 {
+		timeRemaining = 0
+	}
+
+	return map[string]interface{}{
+		"success":                true,
+		"session_id":             session.GameID,
+		"challenge_id":           session.ChallengeID,
+		"daily_challenge_id":     session.DailyChallengeID,
+		"status":                 session.Status,
+		"time_limit":             session.TimeLimit,
+		"time_remaining":         timeRemaining,
+		"total_questions":        session.TotalQuestions,
+		"current_question_index": session.CurrentQuestionIndex,
+		"score":                  session.CurrentScore,
+		"correct_answers":        session.CorrectAnswers,
+		"wrong_answers":          session.WrongAnswers,
+		"streak":                 session.StreakCount,
+		"answers":                state.Answers,
+		"questions":              clientQuestions(questions),
+	}
+}
+
+// SubmitQuizAnswer grades the answer to the session's current question using
+// the server's clock and advances to the next question
+func SubmitQuizAnswer(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req SubmitQuizAnswerRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var graded models.SoloAnswer
+	var question models.QuestionData
+	session, err := services.GameState.UpdateSoloSession(r.PathValue("id"), userID, func(tx *gorm.DB, s *models.ActiveGameState) error {
+		questions, state, err := soloQuestion(s, req.QuestionIndex)
+		if err != nil {
+			return err
+		}
+		question = questions[req.QuestionIndex]
+
+		answer := strings.TrimSpace(req.Answer)
+		if answer == "" && req.AnswerIndex != nil && *req.AnswerIndex >= 0 && *req.AnswerIndex < len(question.Options) {
+			answer = question.Options[*req.AnswerIndex]
+		}
+
+		// A time freeze extends the deadline but not the speed bonus
+		deadline := s.TimeLimit + state.TimeBonus
+		elapsed := time.Since(s.QuestionStartedAt)
+		if elapsed < 0 {
+			elapsed = 0
+		}
+
+		graded = models.SoloAnswer{
+			QuestionIndex:  req.QuestionIndex,
+			QuestionID:     question.ID,
+			Answer:         answer,
+			ResponseTimeMs: elapsed.Milliseconds(),
+		}
+		if elapsed > time.Duration(deadline)*time.Second+soloLatencyGrace {
+			graded.TimedOut = true
+		} else {
+			graded.Correct, graded.PointsEarned = gradeAnswer(question, answer, elapsed, s.TimeLimit)
+		}
+		if state.DoubleNext {
+			graded.PointsEarned *= 2
+			graded.Doubled = true
+			state.DoubleNext = false
+		}
+
+		s.CurrentScore += graded.PointsEarned
+		if graded.Correct {
+			s.CorrectAnswers++
+			s.StreakCount++
+		} else {
+			s.WrongAnswers++
+			s.StreakCount = 0
+		}
+
+		return advanceSoloQuestion(s, state, graded)
+	})
+	if err != nil {
+		writeQuizSessionError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":             true,
+		"correct":             graded.Correct,
+		"timed_out":           graded.TimedOut,
+		"doubled":             graded.Doubled,
+		"correct_answer":      question.CorrectAnswer,
+		"reference":           question.Reference,
+		"points_earned":       graded.PointsEarned,
+		"response_time_ms":    graded.ResponseTimeMs,
+		"score":               session.CurrentScore,
+		"correct_answers":     session.CorrectAnswers,
+		"streak":              session.StreakCount,
+		"next_question_index": session.CurrentQuestionIndex,
+		"finished":            session.CurrentQuestionIndex >= session.TotalQuestions,
+	})
+}
+
+// FinishQuizSession closes a session and applies the result through the
+// progression service. Questions never answered count as wrong.
+func FinishQuizSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var outcome services.GameOutcome
+	var rewards *services.RewardBreakdown
+	var isGuest bool
+	dailyStreak := -1
+	session, err := services.GameState.UpdateSoloSession(r.PathValue("id"), userID, func(tx *gorm.DB, s *models.ActiveGameState) error {
+		var user models.User
+		if err := tx.Select("id", "is_guest").First(&user, userID).Error; err != nil {
+			return err
+		}
+		isGuest = user.IsGuest
+
+		questions, _ := s.GetQuestionsData()
+		unanswered := s.TotalQuestions - s.CurrentQuestionIndex
+		if unanswered > 0 {
+			s.WrongAnswers += unanswered
+		}
+		s.Status = "completed"
+		s.TimeRemaining = 0
+
+		outcome = services.GameOutcome{
+			Mode:           services.ModeSinglePlayer,
+			Reference:      s.GameID,
+			ThemeID:        soloThemeID(questions),
+			Difficulty:     soloDifficulty(questions),
+			Score:          s.CurrentScore,
+			CorrectAnswers: s.CorrectAnswers,
+			TotalQuestions: s.TotalQuestions,
+			TimeElapsed:    int(time.Since(s.StartedAt) / time.Second),
+			Won:            s.TotalQuestions > 0 && s.CorrectAnswers*100 >= s.TotalQuestions*soloWinAccuracy,
+		}
+
+		// A team challenge attempt counts toward the challenge standings
+		if s.ChallengeID != nil {
+			outcome.Mode = services.ModeTeamChallenge
+			if err := services.Challenges.RecordResult(tx, *s.ChallengeID, userID,
+				s.CurrentScore, s.CorrectAnswers, s.WrongAnswers, outcome.TimeElapsed); err != nil {
+				return err
+			}
+		}
+
+		// A daily or weekly attempt is scored on that period's leaderboard
+		if s.DailyChallengeID != nil {
+			outcome.Mode = services.ModeDailyChallenge
+			if dailyStreak, err = services.DailyChallenges.RecordResult(tx, *s.DailyChallengeID, userID,
+				s.CurrentScore, s.CorrectAnswers, s.TotalQuestions, outcome.TimeElapsed); err != nil {
+				return err
+			}
+		}
+
+		// Guests see their results but don't build up progression
+		if isGuest {
+			return nil
+		}
+
+		rewards, err = services.Progression.Apply(tx, userID, outcome)
+		return err
+	})
+	if err != nil {
+		writeQuizSessionError(w, err)
+		return
+	}
+
+	database.GetDB().Model(&models.User{}).
+		Where("id = ? AND active_game_session = ?", userID, session.GameID).
+		Updates(map[string]interface{}{"active_game_session": nil, "game_started_at": nil})
+
+	if !isGuest {
+		go notifyLevelUp(userID, rewards)
+		go awardAchievements(userID, services.AchievementEvent{
+			Type:           services.AchievementEventAttempt,
+			Score:          outcome.Score,
+			CorrectAnswers: outcome.CorrectAnswers,
+			TotalQuestions: outcome.TotalQuestions,
+			IsPerfect:      outcome.IsPerfect(),
+			Won:            outcome.Won,
+			TimeElapsed:    outcome.TimeElapsed,
+			ThemeID:        outcome.ThemeID,
+			Reference:      session.GameID,
+		})
+
+		log.Printf("🏁 User %d finished quiz session %s - %d/%d correct, score %d, XP +%d, FP +%d",
+			userID, session.GameID, outcome.CorrectAnswers, outcome.TotalQuestions, outcome.Score, rewards.XPEarned, rewards.FPEarned)
+	}
+
+	response := map[string]interface{}{
+		"success":         true,
+		"session_id":      session.GameID,
+		"score":           outcome.Score,
+		"correct_answers": outcome.CorrectAnswers,
+		"total_questions": outcome.TotalQuestions,
+		"is_perfect":      outcome.IsPerfect(),
+		"won":             outcome.Won,
+		"rewards":         rewards, // null for guests
+	}
+	if dailyStreak >= 0 {
+		response["daily_streak"] = dailyStreak
+	}
+	utils.JSON(w, http.StatusOK, response)
+}
+
+// soloQuestion checks that questionIndex is the session's open question and
+// returns the question set and session state. Caller holds the session lock.
+func soloQuestion(s *models.ActiveGameState, questionIndex int) ([]models.QuestionData, *models.SoloState, error) {
+	if s.CurrentQuestionIndex >= s.TotalQuestions {
+		return nil, nil, errQuizComplete
+	}
+	if questionIndex != s.CurrentQuestionIndex {
+		return nil, nil, errWrongQuestion
+	}
+
+	questions, err := s.GetQuestionsData()
+	if err != nil || questionIndex >= len(questions) {
+		return nil, nil, errQuizComplete
+	}
+	state, err := s.GetSoloState()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return questions, &state, nil
+}
+
+// advanceSoloQuestion records an answer and starts the next question's clock
+// once the client has had time to show the result
+func advanceSoloQuestion(s *models.ActiveGameState, state *models.SoloState, answer models.SoloAnswer) error {
+	state.Answers = append(state.Answers, answer)
+	state.TimeBonus = 0
+	state.PowerUpsUsed = nil
+
+	s.CurrentQuestionIndex++
+	s.QuestionStartedAt = time.Now().Add(soloRevealDelay)
+	s.TimeRemaining = s.TimeLimit
+
+	return s.SetSoloState(*state)
+}
+
+// soloThemeID returns the theme of a single-theme quiz, or 0 for a mixed one
+func soloThemeID(questions []models.QuestionData) uint {
+	var themeID uint
+	for i, q := range questions {
+		if i > 0 && q.ThemeID != themeID {
+			return 0
+		}
+		themeID = q.ThemeID
+	}
+	return themeID
+}
+
+// soloDifficulty returns the shared difficulty of the questions, or "mixed"
+func soloDifficulty(questions []models.QuestionData) string {
+	difficulty := ""
+	for i, q := range questions {
+		if i > 0 && q.Difficulty != difficulty {
+			return "mixed"
+		}
+		difficulty = q.Difficulty
+	}
+	return difficulty
+}
+
+func writeQuizSessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrSessionNotFound):
+		utils.JSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrSessionClosed), errors.Is(err, errWrongQuestion),
+		errors.Is(err, errQuizComplete), errors.Is(err, errPowerUpRepeated),
+		errors.Is(err, services.ErrChallengeNotActive), errors.Is(err, services.ErrNotChallengeParticipant),
+		errors.Is(err, services.ErrDailyAttemptNotFound):
+		utils.JSONError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrNoPowerUp):
+		utils.JSONError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrUnknownPowerUp):
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("⚠️  Quiz session: %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Quiz session update failed")
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"ubible/database"
+	"ubible/models"
+	"ubible/services"
+	"ubible/utils"
+)
+
+// GetRatingHistory returns a user's current rating and their per-game rating changes
+func GetRatingHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	limit, err := strconv.Atoi(utils.Query(r, "limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	} else if limit > 200 {
+		limit = 200
+	}
+
+	db := database.GetDB()
+	var user models.User
+	if err := db.First(&user, id).Error; err != nil {
+		utils.JSONError(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	games, err := services.MultiplayerDB.GetRatingHistory(user.ID, limit)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch rating history")
+		return
+	}
+
+	if games == nil {
+		games = []services.RatingHistoryEntry{}
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":          true,
+		"user_id":          user.ID,
+		"rating":           user.Rating,
+		"rating_deviation": user.RatingDeviation,
+		"rated_games":      user.RatedGames,
+		"provisional":      services.Ratings.IsProvisional(user.RatedGames, user.RatingDeviation),
+		"history":          games,
+	})
+}
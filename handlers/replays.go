@@ -0,0 +1,48 @@
+// handlers/replays.go - Multiplayer match replays for the players who played them
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"ubible/middleware"
+	"ubible/services"
+	"ubible/utils"
+)
+
+// GetGameReplay rebuilds a finished multiplayer game question by question:
+// each player's answer, response time and running score. Only users who
+// played in the game can see it.
+// GET /api/games/{id}/replay
+func GetGameReplay(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	replay, err := services.Replays.ForPlayer(r.PathValue("id"), userID)
+	if err != nil {
+		writeReplayError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"replay":  replay,
+	})
+}
+
+func writeReplayError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrReplayNotFound):
+		utils.JSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrReplayForbidden):
+		utils.JSONError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrReplayInProgress):
+		utils.JSONError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("⚠️  Replay failed: %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to build replay")
+	}
+}
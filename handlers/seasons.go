@@ -0,0 +1,94 @@
+// handlers/seasons.go - Competitive season HTTP handlers
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"ubible/middleware"
+	"ubible/services"
+	"ubible/utils"
+)
+
+// GetSeasons lists every season, newest first, with the reward tiers
+// GET /api/seasons
+func GetSeasons(w http.ResponseWriter, r *http.Request) {
+	seasons, err := services.Seasons.List()
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch seasons")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"seasons": seasons,
+		"tiers":   services.SeasonTiers,
+	})
+}
+
+// GetCurrentSeason returns the active season and, when signed in, the user's
+// stats in it
+// GET /api/seasons/current
+func GetCurrentSeason(w http.ResponseWriter, r *http.Request) {
+	season := services.Seasons.Active()
+	if season == nil {
+		utils.JSONError(w, http.StatusNotFound, services.ErrSeasonNotFound.Error())
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"season":  season,
+		"tiers":   services.SeasonTiers,
+	}
+	if userID, err := middleware.GetUserID(r); err == nil {
+		response["my_stats"] = services.Seasons.Stats(season.ID, userID)
+	}
+
+	utils.JSON(w, http.StatusOK, response)
+}
+
+// GetSeasonStandings returns a closed season's archived final standings
+// GET /api/seasons/{number}/standings?page=&limit=
+func GetSeasonStandings(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid season number")
+		return
+	}
+	season, err := services.Seasons.Get(number)
+	if err != nil {
+		utils.JSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	page, err := strconv.Atoi(utils.Query(r, "page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(utils.Query(r, "limit", "100"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 100
+	}
+
+	standings, total, err := services.Seasons.Standings(season.ID, limit, (page-1)*limit)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch standings")
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"season":    season,
+		"standings": standings,
+		"total":     total,
+		"page":      page,
+		"limit":     limit,
+	}
+	if userID, err := middleware.GetUserID(r); err == nil {
+		if mine := services.Seasons.Standing(season.ID, userID); mine != nil {
+			response["my_standing"] = mine
+		}
+	}
+
+	utils.JSON(w, http.StatusOK, response)
+}
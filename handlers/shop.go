@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"ubible/middleware"
+	"ubible/services"
+	"ubible/utils"
+)
+
+// PurchaseRequest buys a shop item. Type is accepted as shorthand for a power-up item.
+type PurchaseRequest struct {
+	ItemID   string `json:"item_id"`
+	Type     string `json:"type"`
+	Quantity int    `json:"quantity"`
+}
+
+// GetShopCatalog lists what can be bought with Faith Points
+func GetShopCatalog(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	items, err := services.Shop.Catalog(userID)
+	if err != nil {
+		log.Printf("⚠️  %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to load shop")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"items":   items,
+	})
+}
+
+// PurchaseShopItem spends Faith Points on a power-up or theme unlock
+func PurchaseShopItem(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req PurchaseRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	itemID := req.ItemID
+	if itemID == "" && req.Type != "" {
+		itemID = services.ShopItemPowerUp + ":" + req.Type
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	result, err := services.Shop.Purchase(userID, itemID, req.Quantity)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownShopItem):
+			utils.JSONError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrInvalidQuantity):
+			utils.JSONError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrAlreadyOwned), errors.Is(err, services.ErrInsufficientFaithPoints):
+			utils.JSONError(w, http.StatusConflict, err.Error())
+		default:
+			log.Printf("⚠️  Purchase of %s by user %d: %v", itemID, userID, err)
+			utils.JSONError(w, http.StatusInternalServerError, "Purchase failed")
+		}
+		return
+	}
+
+	log.Printf("🛍️  User %d bought %s x%d for %d FP", userID, result.Item.ID, result.Quantity, result.TotalCost)
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":  true,
+		"purchase": result,
+	})
+}
+
+// GetFaithPointHistory returns the current user's Faith Points ledger
+func GetFaithPointHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	page, err := strconv.Atoi(utils.Query(r, "page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(utils.Query(r, "limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	} else if limit > 200 {
+		limit = 200
+	}
+
+	entries, total, err := services.FaithPoints.History(userID, limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("⚠️  %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch Faith Points history")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":      true,
+		"transactions": entries,
+		"total":        total,
+		"page":         page,
+		"limit":        limit,
+	})
+}
+
+// requireThemeAccess answers 403 and returns false when the themes include a
+// paid theme the user hasn't unlocked. userID 0 is an anonymous player.
+func requireThemeAccess(w http.ResponseWriter, userID uint, themeIDs []int) bool {
+	err := services.Shop.CheckThemeAccess(userID, themeIDs)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, services.ErrThemeLocked):
+		utils.JSONError(w, http.StatusForbidden, err.Error())
+	default:
+		log.Printf("⚠️  %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to check theme access")
+	}
+	return false
+}
@@ -0,0 +1,489 @@
+// handlers/team_challenges.go - Team Challenge HTTP Handlers
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"ubible/database"
+	"ubible/middleware"
+	"ubible/models"
+	"ubible/services"
+	"ubible/utils"
+
+	"github.com/google/uuid"
+)
+
+// ================== CHALLENGE CRUD ENDPOINTS ==================
+
+// CreateTeamChallenge schedules a challenge for a team (admins only)
+// POST /api/teams/{id}/challenges/create
+func CreateTeamChallenge(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	teamID, ok := teamIDParam(w, r)
+	if !ok {
+		return
+	}
+	if !teamService.IsTeamAdmin(userID, teamID) {
+		writeTeamError(w, services.ErrTeamAdminRequired)
+		return
+	}
+
+	var req services.ChallengeInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	challenge, err := services.Challenges.Create(teamID, userID, req)
+	if err != nil {
+		writeChallengeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusCreated, map[string]interface{}{
+		"success":   true,
+		"challenge": challenge,
+	})
+}
+
+// GetTeamChallenges lists a team's challenges, newest first
+// GET /api/teams/{id}/challenges?status=&page=&limit=
+func GetTeamChallenges(w http.ResponseWriter, r *http.Request) {
+	teamID, ok := viewableTeamID(w, r)
+	if !ok {
+		return
+	}
+
+	page, limit := teamPage(r)
+	query := database.GetDB().Model(&models.Challenge{}).Where("team_id = ?", teamID)
+	if status := utils.Query(r, "status", ""); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var challenges []models.Challenge
+	if err := query.Preload("Theme").Order("start_date DESC").
+		Limit(limit).Offset((page - 1) * limit).Find(&challenges).Error; err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch challenges")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"challenges": challengeSummaries(challenges),
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+	})
+}
+
+// GetMyChallenges lists open and recent challenges across the user's teams
+// GET /api/teams/challenges
+func GetMyChallenges(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	db := database.GetDB()
+
+	var challenges []models.Challenge
+	if err := db.Joins("JOIN team_members ON team_members.team_id = challenges.team_id").
+		Where("team_members.user_id = ? AND team_members.is_active = ?", userID, true).
+		Where("challenges.status IN ? OR challenges.end_date > ?",
+			[]models.ChallengeStatus{models.ChallengeStatusPending, models.ChallengeStatusActive},
+			time.Now().AddDate(0, 0, -30)).
+		Preload("Team").Preload("Theme").
+		Order("challenges.start_date DESC").
+		Limit(100).
+		Find(&challenges).Error; err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch challenges")
+		return
+	}
+
+	// The user's own standing in each
+	ids := make([]uint, len(challenges))
+	for i, c := range challenges {
+		ids[i] = c.ID
+	}
+	var mine []models.ChallengeParticipant
+	db.Where("user_id = ? AND challenge_id IN ?", userID, ids).Find(&mine)
+	byChallenge := make(map[uint]models.ChallengeParticipant, len(mine))
+	for _, p := range mine {
+		byChallenge[p.ChallengeID] = p
+	}
+
+	summaries := challengeSummaries(challenges)
+	for i, c := range challenges {
+		if c.Team != nil {
+			summaries[i]["team_name"] = c.Team.Name
+		}
+		if p, ok := byChallenge[c.ID]; ok {
+			summaries[i]["my_status"] = p.Status
+			summaries[i]["my_score"] = p.Score
+			summaries[i]["my_rank"] = p.Rank
+		}
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"challenges": summaries,
+	})
+}
+
+// GetChallenge returns a challenge with its participant count
+// GET /api/challenges/{id}
+func GetChallenge(w http.ResponseWriter, r *http.Request) {
+	challenge, ok := viewableChallenge(w, r)
+	if !ok {
+		return
+	}
+
+	var participants int64
+	database.GetDB().Model(&models.ChallengeParticipant{}).Where("challenge_id = ?", challenge.ID).Count(&participants)
+
+	summary := challengeSummary(*challenge)
+	summary["participant_count"] = participants
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"challenge": summary,
+	})
+}
+
+// UpdateTeamChallenge edits a challenge before it starts (team admins only)
+// POST /api/challenges/{id}/update
+func UpdateTeamChallenge(w http.ResponseWriter, r *http.Request) {
+	challenge, ok := adminChallenge(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.ChallengeInput
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	updated, err := services.Challenges.Update(challenge.ID, req)
+	if err != nil {
+		writeChallengeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"challenge": updated,
+	})
+}
+
+// CancelTeamChallenge cancels a pending or active challenge (team admins only)
+// POST /api/challenges/{id}/cancel
+func CancelTeamChallenge(w http.ResponseWriter, r *http.Request) {
+	challenge, ok := adminChallenge(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	utils.ParseJSON(r, &req)
+	if req.Reason == "" {
+		req.Reason = "cancelled by a team admin"
+	}
+
+	if err := services.Challenges.Cancel(challenge.ID, req.Reason); err != nil {
+		writeChallengeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Challenge cancelled",
+	})
+}
+
+// ================== PARTICIPATION ENDPOINTS ==================
+
+// JoinChallenge signs the current user up for a challenge in one of their teams
+// POST /api/challenges/{id}/join
+func JoinChallenge(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	challengeID, ok := challengeIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	participant, err := services.Challenges.Join(challengeID, userID)
+	if err != nil {
+		writeChallengeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":     true,
+		"message":     "Joined challenge",
+		"participant": participant,
+	})
+}
+
+// LeaveChallenge withdraws from a challenge that hasn't started
+// POST /api/challenges/{id}/leave
+func LeaveChallenge(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	challengeID, ok := challengeIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := services.Challenges.Leave(challengeID, userID); err != nil {
+		writeChallengeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Left challenge",
+	})
+}
+
+// StartChallengeAttempt opens the participant's one quiz session for an
+// active challenge, or resumes it. Answers and finishing go through the
+// /api/quiz/sessions endpoints.
+// POST /api/challenges/{id}/start
+func StartChallengeAttempt(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	challengeID, ok := challengeIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	challenge, participant, questions, err := services.Challenges.BeginAttempt(challengeID, userID)
+	if err != nil {
+		writeChallengeError(w, err)
+		return
+	}
+
+	if participant.Status == models.ParticipantStatusPlaying {
+		session, err := services.GameState.GetSoloSession(participant.SessionID, userID)
+		if err != nil || session.Status != "active" || time.Now().After(session.ExpiresAt) {
+			writeChallengeError(w, services.ErrChallengeAttemptUsed)
+			return
+		}
+		utils.JSON(w, http.StatusOK, quizSessionView(session))
+		return
+	}
+
+	sessionID := uuid.NewString()
+	attached, err := services.Challenges.AttachSession(participant.ID, sessionID)
+	if err != nil {
+		writeChallengeError(w, err)
+		return
+	}
+	if !attached {
+		utils.JSONError(w, http.StatusConflict, "Challenge attempt already started")
+		return
+	}
+
+	// The session can't outlive the challenge
+	expiresAt := time.Now().Add(soloSessionTTL)
+	if challenge.EndDate.Before(expiresAt) {
+		expiresAt = challenge.EndDate
+	}
+
+	session, err := createSoloSession(userID, sessionID, questions, challenge.TimeLimit, nil, &challenge.ID, nil, expiresAt)
+	if err != nil {
+		log.Printf("⚠️  Failed to create challenge session for user %d: %v", userID, err)
+		database.GetDB().Model(participant).Updates(map[string]interface{}{
+			"status":     models.ParticipantStatusJoined,
+			"session_id": "",
+		})
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to start challenge")
+		return
+	}
+
+	log.Printf("🎯 User %d started challenge %d (session %s)", userID, challenge.ID, sessionID)
+	utils.JSON(w, http.StatusOK, quizSessionView(session))
+}
+
+// GetChallengeLeaderboard returns a challenge's standings
+// GET /api/challenges/{id}/leaderboard
+func GetChallengeLeaderboard(w http.ResponseWriter, r *http.Request) {
+	challenge, ok := viewableChallenge(w, r)
+	if !ok {
+		return
+	}
+
+	participants, err := services.Challenges.Leaderboard(challenge.ID)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch leaderboard")
+		return
+	}
+
+	entries := make([]map[string]interface{}, len(participants))
+	for i, p := range participants {
+		entry := map[string]interface{}{
+			"user_id":      p.UserID,
+			"status":       p.Status,
+			"rank":         p.Rank,
+			"score":        p.Score,
+			"correct":      p.Correct,
+			"incorrect":    p.Incorrect,
+			"time_spent":   p.TimeSpent,
+			"completed_at": p.CompletedAt,
+		}
+		if p.User != nil {
+			entry["username"] = p.User.Username
+			entry["avatar"] = p.User.Avatar
+		}
+		entries[i] = entry
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":     true,
+		"challenge":   challengeSummary(*challenge),
+		"leaderboard": entries,
+	})
+}
+
+// ================== HELPERS ==================
+
+// challengeIDParam parses the {id} path value as a challenge ID
+func challengeIDParam(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid challenge ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// viewableChallenge loads {id} if the current user belongs to its team or the
+// team is public
+func viewableChallenge(w http.ResponseWriter, r *http.Request) (*models.Challenge, bool) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return nil, false
+	}
+
+	challengeID, ok := challengeIDParam(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	var challenge models.Challenge
+	if err := database.GetDB().Preload("Team").Preload("Theme").First(&challenge, challengeID).Error; err != nil ||
+		challenge.Team == nil || !challenge.Team.IsActive ||
+		(!challenge.Team.IsPublic && !teamService.IsTeamMember(userID, challenge.TeamID)) {
+		writeChallengeError(w, services.ErrChallengeNotFound)
+		return nil, false
+	}
+
+	return &challenge, true
+}
+
+// adminChallenge loads {id} if the current user is an admin of its team
+func adminChallenge(w http.ResponseWriter, r *http.Request) (*models.Challenge, bool) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return nil, false
+	}
+
+	challengeID, ok := challengeIDParam(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	var challenge models.Challenge
+	if err := database.GetDB().First(&challenge, challengeID).Error; err != nil {
+		writeChallengeError(w, services.ErrChallengeNotFound)
+		return nil, false
+	}
+	if !teamService.IsTeamAdmin(userID, challenge.TeamID) {
+		writeTeamError(w, services.ErrTeamAdminRequired)
+		return nil, false
+	}
+
+	return &challenge, true
+}
+
+// challengeSummary is a challenge without its question set
+func challengeSummary(c models.Challenge) map[string]interface{} {
+	summary := map[string]interface{}{
+		"id":               c.ID,
+		"team_id":          c.TeamID,
+		"name":             c.Name,
+		"description":      c.Description,
+		"theme_id":         c.ThemeID,
+		"num_questions":    c.NumQuestions,
+		"time_limit":       c.TimeLimit,
+		"start_date":       c.StartDate,
+		"end_date":         c.EndDate,
+		"min_participants": c.MinParticipants,
+		"max_participants": c.MaxParticipants,
+		"status":           c.Status,
+		"cancel_reason":    c.CancelReason,
+		"started_at":       c.StartedAt,
+		"completed_at":     c.CompletedAt,
+		"created_at":       c.CreatedAt,
+	}
+	if c.Theme != nil {
+		summary["theme_name"] = c.Theme.Name
+	}
+	return summary
+}
+
+func challengeSummaries(challenges []models.Challenge) []map[string]interface{} {
+	summaries := make([]map[string]interface{}, len(challenges))
+	for i, c := range challenges {
+		summaries[i] = challengeSummary(c)
+	}
+	return summaries
+}
+
+func writeChallengeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrChallengeNotFound):
+		utils.JSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidChallenge):
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrNotTeamMember), errors.Is(err, services.ErrNotChallengeParticipant):
+		utils.JSONError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrChallengeStarted), errors.Is(err, services.ErrChallengeClosed),
+		errors.Is(err, services.ErrChallengeNotActive), errors.Is(err, services.ErrChallengeFull),
+		errors.Is(err, services.ErrAlreadyInChallenge), errors.Is(err, services.ErrChallengeAttemptUsed):
+		utils.JSONError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("⚠️  Challenge request failed: %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Challenge request failed")
+	}
+}
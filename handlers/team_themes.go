@@ -0,0 +1,474 @@
+// handlers/team_themes.go - Team Theme (verse collection) HTTP Handlers
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"ubible/database"
+	"ubible/middleware"
+	"ubible/models"
+	"ubible/services"
+	"ubible/utils"
+
+	"github.com/google/uuid"
+)
+
+// ================== TEAM THEME ENDPOINTS ==================
+
+// CreateTeamTheme adds a verse collection to a team (admins only). Accepts
+// JSON with the list in `content`, or a multipart form with a `file` upload.
+// POST /api/teams/{id}/themes/create
+func CreateTeamTheme(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	teamID, ok := teamIDParam(w, r)
+	if !ok {
+		return
+	}
+	if !teamService.IsTeamAdmin(userID, teamID) {
+		writeTeamError(w, services.ErrTeamAdminRequired)
+		return
+	}
+
+	req, ok := readTeamThemeInput(w, r)
+	if !ok {
+		return
+	}
+
+	theme, report, err := services.TeamThemes.Create(teamID, userID, req)
+	if err != nil {
+		writeTeamThemeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"theme":   teamThemeView(*theme, false),
+		"bank":    report,
+	})
+}
+
+// GetTeamThemes lists a team's themes, most played first (members only)
+// GET /api/teams/{id}/themes?tag=&page=&limit=
+func GetTeamThemes(w http.ResponseWriter, r *http.Request) {
+	teamID, ok := memberTeamID(w, r)
+	if !ok {
+		return
+	}
+
+	page, limit := teamPage(r)
+	themes, total, err := services.TeamThemes.List(teamID, utils.Query(r, "tag", ""), limit, (page-1)*limit)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch team themes")
+		return
+	}
+
+	views := make([]map[string]interface{}, len(themes))
+	for i, theme := range themes {
+		views[i] = teamThemeView(theme, false)
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"themes":  views,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// GetMyTeamThemes lists the themes of all the user's teams
+// GET /api/teams/themes?tag=
+func GetMyTeamThemes(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	themes, err := services.TeamThemes.ListForUser(userID, utils.Query(r, "tag", ""), 100)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch team themes")
+		return
+	}
+
+	views := make([]map[string]interface{}, len(themes))
+	for i, theme := range themes {
+		views[i] = teamThemeView(theme, false)
+		if theme.Team != nil {
+			views[i]["team_name"] = theme.Team.Name
+		}
+		if theme.Creator != nil {
+			views[i]["created_by"] = theme.Creator.Username
+		}
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"themes":  views,
+	})
+}
+
+// GetTeamThemeTags lists the tags used by a team's themes (members only)
+// GET /api/teams/{id}/themes/tags
+func GetTeamThemeTags(w http.ResponseWriter, r *http.Request) {
+	teamID, ok := memberTeamID(w, r)
+	if !ok {
+		return
+	}
+
+	tags, err := services.TeamThemes.Tags(teamID)
+	if err != nil {
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch tags")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"tags":    tags,
+	})
+}
+
+// GetTeamTheme returns a team theme. Team admins also get the verse list.
+// GET /api/team-themes/{id}
+func GetTeamTheme(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	theme, ok := playableTeamTheme(w, r, userID)
+	if !ok {
+		return
+	}
+
+	view := teamThemeView(*theme, teamService.IsTeamAdmin(userID, theme.TeamID))
+	if questions, err := services.TeamThemes.Questions(*theme); err == nil {
+		view["question_count"] = len(questions)
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"theme":   view,
+	})
+}
+
+// UpdateTeamTheme edits a team theme (team admins only). Same body as create;
+// omitted fields are unchanged.
+// POST /api/team-themes/{id}/update
+func UpdateTeamTheme(w http.ResponseWriter, r *http.Request) {
+	theme, ok := adminTeamTheme(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := readTeamThemeInput(w, r)
+	if !ok {
+		return
+	}
+
+	updated, report, err := services.TeamThemes.Update(theme.ID, req)
+	if err != nil {
+		writeTeamThemeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"theme":   teamThemeView(*updated, false),
+		"bank":    report,
+	})
+}
+
+// DeleteTeamTheme removes a team theme (team admins only)
+// POST /api/team-themes/{id}/delete
+func DeleteTeamTheme(w http.ResponseWriter, r *http.Request) {
+	theme, ok := adminTeamTheme(w, r)
+	if !ok {
+		return
+	}
+
+	if err := services.TeamThemes.Delete(theme.ID); err != nil {
+		writeTeamThemeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Team theme deleted",
+	})
+}
+
+// PlayTeamTheme starts a single-player quiz from a team theme's question bank.
+// Answers and finishing go through the /api/quiz/sessions endpoints.
+// POST /api/team-themes/{id}/play
+func PlayTeamTheme(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	theme, ok := playableTeamTheme(w, r, userID)
+	if !ok {
+		return
+	}
+
+	var req StartQuizRequest
+	if err := utils.ParseJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	db := database.GetDB()
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		utils.JSONError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	if req.QuestionCount == 0 {
+		req.QuestionCount = user.QuizQuestionCount
+	}
+	if req.TimeLimit == 0 {
+		req.TimeLimit = user.QuizTimeLimit
+	}
+	if req.QuestionCount < 1 || req.QuestionCount > 100 {
+		utils.JSONError(w, http.StatusBadRequest, "Question count must be between 1 and 100")
+		return
+	}
+	if req.TimeLimit < 5 || req.TimeLimit > 300 {
+		utils.JSONError(w, http.StatusBadRequest, "Time limit must be between 5 and 300 seconds")
+		return
+	}
+
+	translation, err := requestTranslation(r, req.Translation)
+	if err != nil {
+		writeBibleError(w, err)
+		return
+	}
+
+	bank, err := services.TeamThemes.Questions(*theme)
+	if err != nil {
+		writeTeamThemeError(w, err)
+		return
+	}
+	if bank, err = services.Bible.TranslateQuestions(bank, translation); err != nil {
+		writeBibleError(w, err)
+		return
+	}
+
+	sessionID := uuid.NewString()
+	questions := services.PickQuestions(bank, req.QuestionCount, sessionID)
+	for i := range questions {
+		questions[i].ID = i + 1 // Generated questions have no database ID
+	}
+
+	session, err := createSoloSession(userID, sessionID, questions, req.TimeLimit, nil, nil, nil, time.Now().Add(soloSessionTTL))
+	if err != nil {
+		log.Printf("⚠️  Failed to create team theme session for user %d: %v", userID, err)
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to start quiz")
+		return
+	}
+	if err := services.TeamThemes.RecordUse(db, theme.ID); err != nil {
+		log.Printf("⚠️  Failed to record use of team theme %d: %v", theme.ID, err)
+	}
+
+	log.Printf("🎯 User %d started team theme %d quiz %s (%d questions)", userID, theme.ID, sessionID, len(questions))
+	utils.JSON(w, http.StatusOK, quizSessionView(session))
+}
+
+// PublishTeamTheme submits a team theme for review into the global theme
+// catalog (team admins only)
+// POST /api/team-themes/{id}/publish
+func PublishTeamTheme(w http.ResponseWriter, r *http.Request) {
+	theme, ok := adminTeamTheme(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := services.TeamThemes.RequestPublish(theme.ID)
+	if err != nil {
+		writeTeamThemeError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Theme submitted for review",
+		"theme":   teamThemeView(*updated, false),
+	})
+}
+
+// ================== HELPERS ==================
+
+// readTeamThemeInput reads a team theme from JSON or from a multipart form
+// whose `file` holds the verse list and `tags` is comma-separated
+func readTeamThemeInput(w http.ResponseWriter, r *http.Request) (services.TeamThemeInput, bool) {
+	var req services.TeamThemeInput
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.JSONError(w, http.StatusBadRequest, "Invalid request body")
+			return req, false
+		}
+		return req, true
+	}
+
+	maxUpload := int64(services.MaxTeamThemeSize + 64*1024)
+	r.Body = http.MaxBytesReader(w, r.Body, maxUpload)
+	if err := r.ParseMultipartForm(maxUpload); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid or too large upload")
+		return req, false
+	}
+
+	formValue := func(key string) *string {
+		if values, ok := r.MultipartForm.Value[key]; ok && len(values) > 0 {
+			return &values[0]
+		}
+		return nil
+	}
+	req.Name = formValue("name")
+	req.Description = formValue("description")
+	req.Content = formValue("content")
+	if v := formValue("is_public"); v != nil {
+		isPublic, _ := strconv.ParseBool(*v)
+		req.IsPublic = &isPublic
+	}
+	if v := formValue("tags"); v != nil {
+		req.Tags = strings.Split(*v, ",")
+	}
+
+	if file, _, err := r.FormFile("file"); err == nil {
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			utils.JSONError(w, http.StatusBadRequest, "Failed to read uploaded file")
+			return req, false
+		}
+		content := string(data)
+		req.Content = &content
+	}
+
+	return req, true
+}
+
+// memberTeamID parses {id} and checks the current user belongs to the team
+func memberTeamID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return 0, false
+	}
+
+	teamID, ok := teamIDParam(w, r)
+	if !ok {
+		return 0, false
+	}
+	if !teamService.IsTeamMember(userID, teamID) {
+		writeTeamError(w, services.ErrNotTeamMember)
+		return 0, false
+	}
+
+	return teamID, true
+}
+
+// teamThemeIDParam parses the {id} path value as a team theme ID
+func teamThemeIDParam(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid theme ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// playableTeamTheme loads {id} if the user belongs to its team or it is shared
+// publicly
+func playableTeamTheme(w http.ResponseWriter, r *http.Request, userID uint) (*models.TeamTheme, bool) {
+	themeID, ok := teamThemeIDParam(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	theme, err := services.TeamThemes.Get(themeID)
+	if err != nil || (!theme.IsPublic && !teamService.IsTeamMember(userID, theme.TeamID)) {
+		writeTeamThemeError(w, services.ErrTeamThemeNotFound)
+		return nil, false
+	}
+
+	return theme, true
+}
+
+// adminTeamTheme loads {id} if the current user is an admin of its team
+func adminTeamTheme(w http.ResponseWriter, r *http.Request) (*models.TeamTheme, bool) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return nil, false
+	}
+
+	themeID, ok := teamThemeIDParam(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	theme, err := services.TeamThemes.Get(themeID)
+	if err != nil {
+		writeTeamThemeError(w, err)
+		return nil, false
+	}
+	if !teamService.IsTeamAdmin(userID, theme.TeamID) {
+		writeTeamError(w, services.ErrTeamAdminRequired)
+		return nil, false
+	}
+
+	return theme, true
+}
+
+// teamThemeView is a team theme; the verse list is only included for editors
+func teamThemeView(theme models.TeamTheme, withContent bool) map[string]interface{} {
+	view := map[string]interface{}{
+		"id":                 theme.ID,
+		"team_id":            theme.TeamID,
+		"name":               theme.Name,
+		"description":        theme.Description,
+		"is_public":          theme.IsPublic,
+		"tags":               theme.Tags,
+		"usage_count":        theme.UsageCount,
+		"last_used":          theme.LastUsed,
+		"created_by_user_id": theme.CreatedBy,
+		"created_at":         theme.CreatedAt,
+		"publish_status":     theme.PublishStatus,
+		"published_theme_id": theme.PublishedThemeID,
+		"review_note":        theme.ReviewNote,
+	}
+	if withContent {
+		view["content"] = theme.VerseFile
+	}
+	return view
+}
+
+func writeTeamThemeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrTeamThemeNotFound):
+		utils.JSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidTeamTheme):
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrThemeReviewPending), errors.Is(err, services.ErrThemeAlreadyPublished),
+		errors.Is(err, services.ErrThemeNotInReview):
+		utils.JSONError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("⚠️  Team theme request failed: %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Team theme request failed")
+	}
+}
@@ -0,0 +1,609 @@
+// handlers/teams.go - Team Portal HTTP Handlers
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"ubible/database"
+	"ubible/middleware"
+	"ubible/models"
+	"ubible/services"
+	"ubible/utils"
+)
+
+var teamService *services.TeamService
+
+// InitTeamHandlers initializes the team service
+func InitTeamHandlers() {
+	db := database.GetDB()
+	if db == nil {
+		panic("Database not initialized before InitTeamHandlers")
+	}
+	teamService = services.NewTeamService(db)
+}
+
+// TeamRequest is the body for creating or updating a team. Omitted fields keep
+// their current value on update.
+type TeamRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	IsPublic    *bool   `json:"is_public"`
+}
+
+// ================== TEAM CRUD ENDPOINTS ==================
+
+// CreateTeam creates a team owned by the current user
+// POST /api/teams/create
+func CreateTeam(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req TeamRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	name, description, isPublic := "", "", true
+	if req.Name != nil {
+		name = *req.Name
+	}
+	if req.Description != nil {
+		description = *req.Description
+	}
+	if req.IsPublic != nil {
+		isPublic = *req.IsPublic
+	}
+
+	team, err := teamService.CreateTeam(name, description, isPublic, userID)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+
+	log.Printf("👥 User %d created team %d (%s)", userID, team.ID, team.TeamCode)
+	utils.JSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"message": "Team created successfully",
+		"team":    teamSummary(*team, 1, models.TeamRoleOwner),
+	})
+}
+
+// GetMyTeams lists the current user's teams
+// GET /api/teams
+func GetMyTeams(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	memberships, err := teamService.GetUserMemberships(userID)
+	if err != nil {
+		log.Printf("⚠️  Failed to load teams for user %d: %v", userID, err)
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch teams")
+		return
+	}
+
+	teamIDs := make([]uint, 0, len(memberships))
+	for _, m := range memberships {
+		teamIDs = append(teamIDs, m.TeamID)
+	}
+	counts := teamService.MemberCounts(teamIDs)
+
+	teams := make([]map[string]interface{}, 0, len(memberships))
+	for _, m := range memberships {
+		if m.Team == nil {
+			continue
+		}
+		teams = append(teams, teamSummary(*m.Team, counts[m.TeamID], m.Role))
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"teams":   teams,
+	})
+}
+
+// GetTeam returns a team with its members. Private teams are only visible to members.
+// GET /api/teams/{id}
+func GetTeam(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	teamID, ok := teamIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	team, err := teamService.GetTeamByID(teamID)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+
+	role, _ := teamService.GetMemberRole(userID, teamID)
+	if !team.IsPublic && role == "" {
+		writeTeamError(w, services.ErrTeamNotFound)
+		return
+	}
+
+	members := make([]map[string]interface{}, 0, len(team.Members))
+	for _, m := range team.Members {
+		members = append(members, teamMemberView(m))
+	}
+
+	summary := teamSummary(*team, int64(len(team.Members)), role)
+	summary["members"] = members
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"team":    summary,
+	})
+}
+
+// UpdateTeam changes a team's name, description or visibility (owner/admin)
+// POST /api/teams/{id}/update
+func UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	teamID, ok := teamIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req TeamRequest
+	if err := utils.ParseJSON(r, &req); err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	team, err := teamService.GetTeamByID(teamID)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+
+	name, description, isPublic := team.Name, team.Description, team.IsPublic
+	if req.Name != nil {
+		name = *req.Name
+	}
+	if req.Description != nil {
+		description = *req.Description
+	}
+	if req.IsPublic != nil {
+		isPublic = *req.IsPublic
+	}
+
+	if err := teamService.UpdateTeam(teamID, name, description, isPublic, userID); err != nil {
+		writeTeamError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Team updated successfully",
+	})
+}
+
+// DeleteTeam deactivates a team and all its memberships (owner only)
+// POST /api/teams/{id}/delete
+func DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	teamID, ok := teamIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := teamService.DeleteTeam(teamID, userID); err != nil {
+		writeTeamError(w, err)
+		return
+	}
+
+	log.Printf("🗑️  User %d deleted team %d", userID, teamID)
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Team deleted successfully",
+	})
+}
+
+// ================== MEMBERSHIP ENDPOINTS ==================
+
+// JoinTeam joins a team by its invite code
+// POST /api/teams/join
+func JoinTeam(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req struct {
+		TeamCode string `json:"team_code"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil || req.TeamCode == "" {
+		utils.JSONError(w, http.StatusBadRequest, "team_code is required")
+		return
+	}
+
+	team, err := teamService.JoinTeam(userID, req.TeamCode)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+
+	go awardAchievements(userID, services.AchievementEvent{Type: services.AchievementEventSocial})
+
+	counts := teamService.MemberCounts([]uint{team.ID})
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Joined team successfully",
+		"team":    teamSummary(*team, counts[team.ID], models.TeamRoleMember),
+	})
+}
+
+// LeaveTeam removes the current user from a team. Owners must transfer first.
+// POST /api/teams/{id}/leave
+func LeaveTeam(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	teamID, ok := teamIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := teamService.LeaveTeam(userID, teamID); err != nil {
+		writeTeamError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Left team successfully",
+	})
+}
+
+// GetTeamMembers returns a page of a team's members, owner and admins first
+// GET /api/teams/{id}/members?page=&limit=
+func GetTeamMembers(w http.ResponseWriter, r *http.Request) {
+	teamID, ok := viewableTeamID(w, r)
+	if !ok {
+		return
+	}
+
+	page, limit := teamPage(r)
+	members, total, err := teamService.GetTeamMembers(teamID, limit, (page-1)*limit)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+
+	views := make([]map[string]interface{}, 0, len(members))
+	for _, m := range members {
+		views = append(views, teamMemberView(m))
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"members": views,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// RemoveTeamMember removes a member (owner/admin; only the owner can remove admins)
+// POST /api/teams/{id}/members/{userId}/remove
+func RemoveTeamMember(w http.ResponseWriter, r *http.Request) {
+	changeTeamMember(w, r, teamService.RemoveMember, "Member removed")
+}
+
+// PromoteTeamMember makes a member an admin (owner only)
+// POST /api/teams/{id}/members/{userId}/promote
+func PromoteTeamMember(w http.ResponseWriter, r *http.Request) {
+	changeTeamMember(w, r, teamService.PromoteMember, "Member promoted to admin")
+}
+
+// DemoteTeamMember makes an admin a regular member (owner only)
+// POST /api/teams/{id}/members/{userId}/demote
+func DemoteTeamMember(w http.ResponseWriter, r *http.Request) {
+	changeTeamMember(w, r, teamService.DemoteMember, "Admin demoted to member")
+}
+
+// TransferTeamOwnership hands the team to another member; the old owner becomes an admin
+// POST /api/teams/{id}/transfer
+func TransferTeamOwnership(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	teamID, ok := teamIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		UserID uint `json:"user_id"`
+	}
+	if err := utils.ParseJSON(r, &req); err != nil || req.UserID == 0 {
+		utils.JSONError(w, http.StatusBadRequest, "user_id is required")
+		return
+	}
+
+	if err := teamService.TransferOwnership(teamID, userID, req.UserID); err != nil {
+		writeTeamError(w, err)
+		return
+	}
+
+	log.Printf("👑 Team %d ownership transferred from user %d to %d", teamID, userID, req.UserID)
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Ownership transferred",
+	})
+}
+
+// ================== STATS & DISCOVERY ENDPOINTS ==================
+
+// GetTeamLeaderboard returns a page of members ranked by total score
+// GET /api/teams/{id}/leaderboard?page=&limit=
+func GetTeamLeaderboard(w http.ResponseWriter, r *http.Request) {
+	teamID, ok := viewableTeamID(w, r)
+	if !ok {
+		return
+	}
+
+	page, limit := teamPage(r)
+	offset := (page - 1) * limit
+	members, total, err := teamService.GetTeamLeaderboard(teamID, limit, offset)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+
+	leaderboard := make([]map[string]interface{}, 0, len(members))
+	for i, m := range members {
+		entry := teamMemberView(m)
+		entry["rank"] = offset + i + 1
+		leaderboard = append(leaderboard, entry)
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success":     true,
+		"leaderboard": leaderboard,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+	})
+}
+
+// GetTeamStats returns aggregate team statistics
+// GET /api/teams/{id}/stats
+func GetTeamStats(w http.ResponseWriter, r *http.Request) {
+	teamID, ok := viewableTeamID(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := teamService.GetTeamStats(teamID)
+	if err != nil {
+		writeTeamError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"stats":   stats,
+	})
+}
+
+// SearchTeams returns a page of public teams matching q
+// GET /api/teams/search?q=&page=&limit=
+func SearchTeams(w http.ResponseWriter, r *http.Request) {
+	page, limit := teamPage(r)
+	teams, total, err := teamService.SearchPublicTeams(utils.Query(r, "q"), limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("⚠️  Team search failed: %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to search teams")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"teams":   teamSummaries(teams),
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// GetPopularTeams returns the public teams with the most members
+// GET /api/teams/popular?limit=
+func GetPopularTeams(w http.ResponseWriter, r *http.Request) {
+	_, limit := teamPage(r)
+	teams, err := teamService.GetPopularTeams(limit)
+	if err != nil {
+		log.Printf("⚠️  Failed to load popular teams: %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch teams")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"teams":   teamSummaries(teams),
+	})
+}
+
+// ================== HELPERS ==================
+
+// changeTeamMember runs a role or membership change by the current user on {userId}
+func changeTeamMember(w http.ResponseWriter, r *http.Request, change func(teamID, actorID, memberID uint) error, message string) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	teamID, ok := teamIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.ParseUint(r.PathValue("userId"), 10, 32)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := change(teamID, userID, uint(memberID)); err != nil {
+		writeTeamError(w, err)
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": message,
+	})
+}
+
+// teamIDParam parses {id}, writing a 400 if it isn't a valid ID
+func teamIDParam(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		utils.JSONError(w, http.StatusBadRequest, "Invalid team ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// viewableTeamID parses {id} and checks the current user may see the team:
+// public teams are visible to everyone, private teams only to members
+func viewableTeamID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	userID, err := middleware.GetUserID(r)
+	if err != nil {
+		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return 0, false
+	}
+
+	teamID, ok := teamIDParam(w, r)
+	if !ok {
+		return 0, false
+	}
+
+	var team models.Team
+	if err := database.GetDB().Select("id", "is_public").
+		Where("id = ? AND is_active = ?", teamID, true).First(&team).Error; err != nil {
+		writeTeamError(w, services.ErrTeamNotFound)
+		return 0, false
+	}
+	if !team.IsPublic && !teamService.IsTeamMember(userID, teamID) {
+		writeTeamError(w, services.ErrTeamNotFound)
+		return 0, false
+	}
+
+	return teamID, true
+}
+
+// teamPage reads page (default 1) and limit (default 20, max 100)
+func teamPage(r *http.Request) (int, int) {
+	page, err := strconv.Atoi(utils.Query(r, "page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(utils.Query(r, "limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	} else if limit > 100 {
+		limit = 100
+	}
+	return page, limit
+}
+
+// teamSummary is the public view of a team; role is empty for non-members
+func teamSummary(team models.Team, memberCount int64, role models.TeamRole) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           team.ID,
+		"name":         team.Name,
+		"description":  team.Description,
+		"team_code":    team.TeamCode,
+		"is_public":    team.IsPublic,
+		"creator_id":   team.CreatorID,
+		"member_count": memberCount,
+		"role":         role,
+		"created_at":   team.CreatedAt,
+	}
+}
+
+func teamSummaries(teams []models.Team) []map[string]interface{} {
+	teamIDs := make([]uint, 0, len(teams))
+	for _, t := range teams {
+		teamIDs = append(teamIDs, t.ID)
+	}
+	counts := teamService.MemberCounts(teamIDs)
+
+	summaries := make([]map[string]interface{}, 0, len(teams))
+	for _, t := range teams {
+		summaries = append(summaries, teamSummary(t, counts[t.ID], ""))
+	}
+	return summaries
+}
+
+// teamMemberView is a member's team stats with public profile fields only
+func teamMemberView(m models.TeamMember) map[string]interface{} {
+	view := map[string]interface{}{
+		"user_id":        m.UserID,
+		"role":           m.Role,
+		"joined_at":      m.JoinedAt,
+		"total_score":    m.TotalScore,
+		"quizzes_played": m.QuizzesPlayed,
+		"last_active":    m.LastActive,
+	}
+	if m.User != nil {
+		view["username"] = m.User.Username
+		view["display_name"] = m.User.DisplayName
+		view["avatar"] = m.User.Avatar
+		view["level"] = m.User.Level
+	}
+	return view
+}
+
+// writeTeamError maps team service errors to HTTP status codes
+func writeTeamError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrTeamNotFound), errors.Is(err, services.ErrTeamMemberNotFound):
+		utils.JSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrTeamNameRequired), errors.Is(err, services.ErrTeamNameTooLong):
+		utils.JSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrNotTeamMember), errors.Is(err, services.ErrTeamAdminRequired),
+		errors.Is(err, services.ErrTeamOwnerRequired):
+		utils.JSONError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrAlreadyTeamMember), errors.Is(err, services.ErrTeamOwnerProtected),
+		errors.Is(err, services.ErrTeamOwnerMustTransfer):
+		utils.JSONError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("⚠️  Team request failed: %v", err)
+		utils.JSONError(w, http.StatusInternalServerError, "Team request failed")
+	}
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strings"
+	"ubible/reference"
 	"ubible/services"
 	"ubible/utils"
 )
@@ -19,8 +21,9 @@ func GenerateTheme(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate request
-	if len(req.Keywords) == 0 {
-		utils.JSONError(w, http.StatusBadRequest, "At least one keyword is required")
+	req.Query = strings.TrimSpace(req.Query)
+	if len(req.Keywords) == 0 && req.Query == "" {
+		utils.JSONError(w, http.StatusBadRequest, "At least one keyword or a query is required")
 		return
 	}
 
@@ -33,6 +36,18 @@ func GenerateTheme(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// A query names the theme after the words it searches for
+	if req.Query != "" {
+		query, err := services.ParseSearchQuery(req.Query)
+		if err != nil {
+			utils.JSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(cleanedKeywords) == 0 {
+			cleanedKeywords = query.Terms()
+		}
+	}
+
 	if len(cleanedKeywords) == 0 {
 		utils.JSONError(w, http.StatusBadRequest, "At least one valid keyword is required")
 		return
@@ -57,12 +72,21 @@ func GenerateTheme(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("Generating theme with keywords: %v, count: %d, testament: %s, books: %v",
-		req.Keywords, req.Count, req.Testament, req.Books)
+	log.Printf("Generating theme with keywords: %v, query: %q, count: %d, testament: %s, books: %v, translation: %s",
+		req.Keywords, req.Query, req.Count, req.Testament, req.Books, req.Translation)
 
 	// Search for verses
 	verses, err := services.SearchVerses(req)
 	if err != nil {
+		// Books may be given by any name or abbreviation the reference package knows
+		if errors.Is(err, reference.ErrUnknownBook) || errors.Is(err, services.ErrEmptySearchQuery) {
+			utils.JSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrUnknownTranslation) {
+			writeBibleError(w, err)
+			return
+		}
 		log.Printf("Error searching verses: %v", err)
 		utils.JSONError(w, http.StatusInternalServerError, "Failed to search verses: "+err.Error())
 		return
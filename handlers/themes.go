@@ -12,6 +12,7 @@ import (
 	"ubible/database"
 	"ubible/middleware"
 	"ubible/models"
+	"ubible/reference"
 	"ubible/utils"
 
 	"gorm.io/gorm"
@@ -85,7 +86,9 @@ func GetTheme(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Transform questions to include parsed options
+	// Transform questions to include parsed options. Question IDs are shared
+	// with graded games, so the answer (and the reference that gives it away)
+	// stays on the server and the options are shuffled.
 	questionsData := make([]map[string]interface{}, len(questions))
 	for i, q := range questions {
 		var wrongAnswers []string
@@ -94,14 +97,13 @@ func GetTheme(w http.ResponseWriter, r *http.Request) {
 		}
 
 		options := append([]string{q.CorrectAnswer}, wrongAnswers...)
+		rand.Shuffle(len(options), func(i, j int) { options[i], options[j] = options[j], options[i] })
 
 		questionsData[i] = map[string]interface{}{
-			"id":             q.ID,
-			"text":           q.Text,
-			"correct_answer": q.CorrectAnswer,
-			"options":        options,
-			"reference":      q.Reference,
-			"difficulty":     q.Difficulty,
+			"id":         q.ID,
+			"text":       q.Text,
+			"options":    options,
+			"difficulty": q.Difficulty,
 		}
 	}
 
@@ -116,14 +118,11 @@ func GetTheme(w http.ResponseWriter, r *http.Request) {
 // CreateTheme creates a new theme with verses (requires auth)
 func CreateTheme(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Icon        string `json:"icon"`
-		Color       string `json:"color"`
-		Verses      []struct {
-			Reference string `json:"reference"`
-			Text      string `json:"text"`
-		} `json:"verses"`
+		Name        string       `json:"name"`
+		Description string       `json:"description"`
+		Icon        string       `json:"icon"`
+		Color       string       `json:"color"`
+		Verses      []themeVerse `json:"verses"`
 	}
 
 	if err := utils.ParseJSON(r, &req); err != nil {
@@ -141,6 +140,12 @@ func CreateTheme(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	verses, rejected := canonicalizeVerses(req.Verses)
+	if len(verses) < 5 {
+		writeRejectedVerses(w, rejected)
+		return
+	}
+
 	db := database.GetDB()
 
 	// Check for duplicate
@@ -174,7 +179,7 @@ func CreateTheme(w http.ResponseWriter, r *http.Request) {
 
 	// Create questions for each verse
 	successCount := 0
-	for i, verse := range req.Verses {
+	for i, verse := range verses {
 		if verse.Reference == "" || verse.Text == "" {
 			log.Printf("Skipping verse %d: empty reference or text", i)
 			continue
@@ -197,6 +202,7 @@ func CreateTheme(w http.ResponseWriter, r *http.Request) {
 			CorrectAnswer: verse.Reference,
 			WrongAnswers:  "[]", // Empty array for now
 			Reference:     verse.Reference,
+			OSIS:          verse.OSIS,
 			Difficulty:    "medium",
 		}
 		if err := db.Create(&question).Error; err != nil {
@@ -211,10 +217,11 @@ func CreateTheme(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Theme %d created with %d/%d verses", theme.ID, successCount, len(req.Verses))
 
 	utils.JSON(w, http.StatusOK, map[string]interface{}{
-		"success":        true,
-		"message":        "Theme created successfully",
-		"theme":          theme,
-		"verses_created": successCount,
+		"success":             true,
+		"message":             "Theme created successfully",
+		"theme":               theme,
+		"verses_created":      successCount,
+		"rejected_references": rejected,
 	})
 }
 
@@ -307,11 +314,8 @@ func DeleteTheme(w http.ResponseWriter, r *http.Request) {
 // CreatePublicTheme creates a new public theme (no auth required)
 func CreatePublicTheme(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name   string `json:"name"`
-		Verses []struct {
-			Reference string `json:"reference"`
-			Text      string `json:"text"`
-		} `json:"verses"`
+		Name   string       `json:"name"`
+		Verses []themeVerse `json:"verses"`
 	}
 
 	if err := utils.ParseJSON(r, &req); err != nil {
@@ -334,6 +338,12 @@ func CreatePublicTheme(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	verses, rejected := canonicalizeVerses(req.Verses)
+	if len(verses) < 5 {
+		writeRejectedVerses(w, rejected)
+		return
+	}
+
 	db := database.GetDB()
 
 	var existing models.Theme
@@ -360,7 +370,7 @@ func CreatePublicTheme(w http.ResponseWriter, r *http.Request) {
 
 	// Generate questions from verses - alternating between two question types
 	successCount := 0
-	for i, verse := range req.Verses {
+	for i, verse := range verses {
 		if verse.Reference == "" || verse.Text == "" {
 			continue
 		}
@@ -373,7 +383,7 @@ func CreatePublicTheme(w http.ResponseWriter, r *http.Request) {
 
 		// Long verses (>200 chars) always show verse → pick reference
 		if len(cleanText) > 200 {
-			wrongRefs := generateWrongReferences(db, verse.Reference, req.Verses)
+			wrongRefs := generateWrongReferences(db, verse.Reference, verses)
 			wrongAnswersJSON, _ := json.Marshal(wrongRefs)
 
 			question = models.Question{
@@ -383,11 +393,12 @@ func CreatePublicTheme(w http.ResponseWriter, r *http.Request) {
 				CorrectAnswer: verse.Reference,
 				WrongAnswers:  string(wrongAnswersJSON),
 				Reference:     verse.Reference,
+				OSIS:          verse.OSIS,
 				Difficulty:    "medium",
 			}
 		} else if i%2 == 0 {
 			// Short verses alternate: verse → reference
-			wrongRefs := generateWrongReferences(db, verse.Reference, req.Verses)
+			wrongRefs := generateWrongReferences(db, verse.Reference, verses)
 			wrongAnswersJSON, _ := json.Marshal(wrongRefs)
 
 			question = models.Question{
@@ -397,11 +408,12 @@ func CreatePublicTheme(w http.ResponseWriter, r *http.Request) {
 				CorrectAnswer: verse.Reference,
 				WrongAnswers:  string(wrongAnswersJSON),
 				Reference:     verse.Reference,
+				OSIS:          verse.OSIS,
 				Difficulty:    "medium",
 			}
 		} else {
 			// Short verses alternate: reference → verse
-			wrongTexts := generateWrongVerses(db, cleanText, verse.Reference, req.Verses)
+			wrongTexts := generateWrongVerses(db, cleanText, verse.Reference, verses)
 			wrongAnswersJSON, _ := json.Marshal(wrongTexts)
 
 			question = models.Question{
@@ -411,6 +423,7 @@ func CreatePublicTheme(w http.ResponseWriter, r *http.Request) {
 				CorrectAnswer: cleanText,
 				WrongAnswers:  string(wrongAnswersJSON),
 				Reference:     verse.Reference,
+				OSIS:          verse.OSIS,
 				Difficulty:    "medium",
 			}
 		}
@@ -421,25 +434,23 @@ func CreatePublicTheme(w http.ResponseWriter, r *http.Request) {
 	}
 
 	utils.JSON(w, http.StatusOK, map[string]interface{}{
-		"success":        true,
-		"message":        "Theme created successfully",
-		"theme":          theme,
-		"verses_created": successCount,
-		"total_verses":   len(req.Verses),
+		"success":             true,
+		"message":             "Theme created successfully",
+		"theme":               theme,
+		"verses_created":      successCount,
+		"total_verses":        len(req.Verses),
+		"rejected_references": rejected,
 	})
 }
 
 // CreateThemeFromVerses creates a new theme from bulk verses (admin endpoint - protected)
 func CreateThemeFromVerses(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Icon        string `json:"icon"`
-		Color       string `json:"color"`
-		Verses      []struct {
-			Reference string `json:"reference"`
-			Text      string `json:"text"`
-		} `json:"verses"`
+		Name        string       `json:"name"`
+		Description string       `json:"description"`
+		Icon        string       `json:"icon"`
+		Color       string       `json:"color"`
+		Verses      []themeVerse `json:"verses"`
 	}
 
 	if err := utils.ParseJSON(r, &req); err != nil {
@@ -463,6 +474,12 @@ func CreateThemeFromVerses(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	verses, rejected := canonicalizeVerses(req.Verses)
+	if len(verses) < 5 {
+		writeRejectedVerses(w, rejected)
+		return
+	}
+
 	db := database.GetDB()
 
 	// Check for duplicate theme name
@@ -493,9 +510,9 @@ func CreateThemeFromVerses(w http.ResponseWriter, r *http.Request) {
 
 	// Create questions for each verse
 	successCount := 0
-	failureCount := 0
+	failureCount := len(rejected)
 
-	for i, verse := range req.Verses {
+	for i, verse := range verses {
 		if verse.Reference == "" || verse.Text == "" {
 			log.Printf("⚠️  Skipping verse %d: empty reference or text", i)
 			failureCount++
@@ -508,6 +525,7 @@ func CreateThemeFromVerses(w http.ResponseWriter, r *http.Request) {
 			CorrectAnswer: verse.Reference,
 			WrongAnswers:  "[]", // Empty for now - could be enhanced with similar verses
 			Reference:     verse.Reference,
+			OSIS:          verse.OSIS,
 			Difficulty:    "medium",
 		}
 
@@ -526,20 +544,57 @@ func CreateThemeFromVerses(w http.ResponseWriter, r *http.Request) {
 		theme.ID, successCount, len(req.Verses), failureCount)
 
 	utils.JSON(w, http.StatusCreated, map[string]interface{}{
-		"success":        true,
-		"message":        "Theme created successfully",
-		"theme":          theme,
-		"verses_created": successCount,
-		"verses_failed":  failureCount,
-		"total_verses":   len(req.Verses),
+		"success":             true,
+		"message":             "Theme created successfully",
+		"theme":               theme,
+		"verses_created":      successCount,
+		"verses_failed":       failureCount,
+		"total_verses":        len(req.Verses),
+		"rejected_references": rejected,
 	})
 }
 
-// generateWrongReferences generates wrong references from same theme verses
-func generateWrongReferences(_ *gorm.DB, correctRef string, themeVerses []struct {
+// themeVerse is a verse submitted for a new theme. OSIS is filled in by
+// canonicalizeVerses.
+type themeVerse struct {
 	Reference string `json:"reference"`
 	Text      string `json:"text"`
-}) []string {
+	OSIS      string `json:"-"`
+}
+
+// canonicalizeVerses rewrites each verse's reference to its canonical form.
+// Verses whose reference isn't in the canon are left out and returned as
+// rejected with the reason; empty references are kept for the caller to skip.
+func canonicalizeVerses(verses []themeVerse) ([]themeVerse, []map[string]string) {
+	valid := make([]themeVerse, 0, len(verses))
+	rejected := []map[string]string{}
+	for _, v := range verses {
+		if strings.TrimSpace(v.Reference) == "" {
+			valid = append(valid, v)
+			continue
+		}
+		ref, err := reference.Parse(v.Reference)
+		if err != nil {
+			rejected = append(rejected, map[string]string{"reference": v.Reference, "error": err.Error()})
+			continue
+		}
+		v.Reference, v.OSIS = ref.String(), ref.OSIS()
+		valid = append(valid, v)
+	}
+	return valid, rejected
+}
+
+// writeRejectedVerses answers a theme request left with too few valid references
+func writeRejectedVerses(w http.ResponseWriter, rejected []map[string]string) {
+	utils.JSON(w, http.StatusBadRequest, map[string]interface{}{
+		"success":             false,
+		"error":               "At least 5 verses with valid references are required",
+		"rejected_references": rejected,
+	})
+}
+
+// generateWrongReferences generates wrong references from same theme verses
+func generateWrongReferences(_ *gorm.DB, correctRef string, themeVerses []themeVerse) []string {
 	wrong := []string{}
 	used := map[string]bool{correctRef: true}
 
@@ -554,14 +609,14 @@ func generateWrongReferences(_ *gorm.DB, correctRef string, themeVerses []struct
 		}
 	}
 
-	// Fallback: generate random references if not enough verses in theme
+	// Fallback: random verses from the canon, named in the same language
 	if len(wrong) < 3 {
-		books := []string{"John", "Matthew", "Mark", "Luke", "Romans", "Genesis", "Psalms", "Proverbs", "Isaiah", "Jeremiah"}
+		lang := reference.English
+		if parsed, err := reference.Parse(correctRef); err == nil {
+			lang = parsed.Lang
+		}
 		for len(wrong) < 3 {
-			book := books[rand.Intn(len(books))]
-			chapter := rand.Intn(20) + 1
-			verse := rand.Intn(30) + 1
-			ref := fmt.Sprintf("%s %d:%d", book, chapter, verse)
+			ref := reference.Random(lang).String()
 			if !used[ref] {
 				wrong = append(wrong, ref)
 				used[ref] = true
@@ -573,10 +628,7 @@ func generateWrongReferences(_ *gorm.DB, correctRef string, themeVerses []struct
 }
 
 // generateWrongVerses generates wrong verse texts from same theme verses
-func generateWrongVerses(_ *gorm.DB, correctText string, _ string, themeVerses []struct {
-	Reference string `json:"reference"`
-	Text      string `json:"text"`
-}) []string {
+func generateWrongVerses(_ *gorm.DB, correctText string, _ string, themeVerses []themeVerse) []string {
 	wrong := []string{}
 	used := map[string]bool{correctText: true}
 
@@ -67,6 +67,8 @@ func SearchUsers(w http.ResponseWriter, r *http.Request) {
 	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "users": users})
 }
 
+// GetUserProfile returns a user's public profile and team memberships. Private
+// teams and a private email are only included on the user's own profile.
 func GetUserProfile(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	db := database.GetDB()
@@ -76,7 +78,28 @@ func GetUserProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "user": user})
+	viewerID, _ := middleware.GetUserID(r)
+	self := viewerID == user.ID
+	if !self && !user.EmailPublic {
+		user.Email = nil
+	}
+
+	teams := make([]map[string]interface{}, 0)
+	if memberships, err := teamService.GetUserMemberships(user.ID); err == nil {
+		for _, m := range memberships {
+			if m.Team == nil || (!m.Team.IsPublic && !self) {
+				continue
+			}
+			teams = append(teams, map[string]interface{}{
+				"id":        m.Team.ID,
+				"name":      m.Team.Name,
+				"role":      m.Role,
+				"joined_at": m.JoinedAt,
+			})
+		}
+	}
+
+	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "user": user, "teams": teams})
 }
 
 func GetGameHistory(w http.ResponseWriter, r *http.Request) {
@@ -92,17 +115,3 @@ func GetGameHistory(w http.ResponseWriter, r *http.Request) {
 
 	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "history": attempts})
 }
-
-func GetFriends(w http.ResponseWriter, r *http.Request) {
-	userID, err := middleware.GetUserID(r)
-	if err != nil {
-		utils.JSONError(w, http.StatusUnauthorized, "Unauthorized")
-		return
-	}
-
-	db := database.GetDB()
-	var friends []models.Friend
-	db.Preload("Friend").Where("user_id = ?", userID).Find(&friends)
-
-	utils.JSON(w, http.StatusOK, map[string]interface{}{"success": true, "friends": friends})
-}
@@ -4,8 +4,12 @@ import (
 	"net/http"
 	"strconv"
 	"ubible/database"
+	"ubible/middleware"
 	"ubible/models"
+	"ubible/services"
 	"ubible/utils"
+
+	"gorm.io/gorm"
 )
 
 // GetUsers returns all users with pagination
@@ -111,13 +115,26 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 	if updateData.XP >= 0 {
 		user.XP = updateData.XP
 	}
+	oldFaithPoints := user.FaithPoints
 	if updateData.FaithPoints >= 0 {
 		user.FaithPoints = updateData.FaithPoints
 	}
 	user.IsAdmin = updateData.IsAdmin
 	user.IsBanned = updateData.IsBanned
 
-	if err := db.Save(&user).Error; err != nil {
+	// Manual balance edits go through the ledger like any other FP change
+	reference := ""
+	if adminID, err := middleware.GetUserID(r); err == nil {
+		reference = "admin:" + strconv.FormatUint(uint64(adminID), 10)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		return services.FaithPoints.Record(tx, user.ID, user.FaithPoints-oldFaithPoints, user.FaithPoints, models.FPReasonAdminAdjustment, reference)
+	})
+	if err != nil {
 		utils.JSONError(w, http.StatusInternalServerError, "Failed to update user")
 		return
 	}
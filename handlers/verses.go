@@ -12,7 +12,9 @@ import (
 	"strings"
 	"time"
 	"ubible/database"
+	"ubible/middleware"
 	"ubible/models"
+	"ubible/services"
 	"ubible/utils"
 
 	"gorm.io/gorm"
@@ -33,6 +35,18 @@ type VerseResponse struct {
 	Difficulty    string   `json:"difficulty"`
 }
 
+// QuizQuestionResponse is a quiz question as sent to the player. The answer
+// and the reference (often the answer itself) stay on the server, which
+// grades answers through /api/quiz/sessions.
+type QuizQuestionResponse struct {
+	ID         uint     `json:"id"`
+	ThemeID    uint     `json:"theme_id"`
+	ThemeName  string   `json:"theme_name"`
+	Text       string   `json:"text"`
+	Options    []string `json:"options"`
+	Difficulty string   `json:"difficulty"`
+}
+
 // Normalize "Book 1:1: ..." => "Book 1:1 — ..." (supports multi-word books)
 var refColonRe = regexp.MustCompile(`^([1-3]?\s*[A-Za-z]+(?:\s+[A-Za-z]+)*\s+\d+:\d+)\s*:\s+`)
 
@@ -330,9 +344,26 @@ func GetQuizQuestions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	translation, err := requestTranslation(r, utils.Query(r, "translation", ""))
+	if err != nil {
+		writeBibleError(w, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r)
 	query := db.Model(&models.Question{}).Preload("Theme")
 	if themeID != "" {
-		query = query.Where("theme_id = ?", themeID)
+		id, err := strconv.Atoi(themeID)
+		if err != nil {
+			utils.JSONError(w, http.StatusBadRequest, "Invalid theme ID")
+			return
+		}
+		if !requireThemeAccess(w, userID, []int{id}) {
+			return
+		}
+		query = query.Where("theme_id = ?", id)
+	} else {
+		query = services.Shop.ExcludeLockedThemes(query, userID)
 	}
 	if difficulty != "" {
 		query = query.Where("difficulty = ?", difficulty)
@@ -345,8 +376,12 @@ func GetQuizQuestions(w http.ResponseWriter, r *http.Request) {
 		utils.JSONError(w, http.StatusInternalServerError, "Failed to fetch questions")
 		return
 	}
+	if questions, err = services.Bible.TranslateQuestions(questions, translation); err != nil {
+		writeBibleError(w, err)
+		return
+	}
 
-	verses := make([]VerseResponse, 0, len(questions))
+	verses := make([]QuizQuestionResponse, 0, len(questions))
 	for _, q := range questions {
 		comp := isCompletionQuestion(q)
 
@@ -381,15 +416,13 @@ func GetQuizQuestions(w http.ResponseWriter, r *http.Request) {
 			themeName = q.Theme.Name
 		}
 
-		verses = append(verses, VerseResponse{
-			ID:            q.ID,
-			ThemeID:       q.ThemeID,
-			ThemeName:     themeName,
-			Text:          sanitizeDisplayText(q.Text),
-			CorrectAnswer: q.CorrectAnswer,
-			Options:       options,
-			Reference:     q.Reference,
-			Difficulty:    q.Difficulty,
+		verses = append(verses, QuizQuestionResponse{
+			ID:         q.ID,
+			ThemeID:    q.ThemeID,
+			ThemeName:  themeName,
+			Text:       sanitizeDisplayText(q.Text),
+			Options:    options,
+			Difficulty: q.Difficulty,
 		})
 	}
 
@@ -407,7 +440,7 @@ func GetQuizQuestions(w http.ResponseWriter, r *http.Request) {
 		log.Printf("📚 Quiz: Requested %d questions, only %d available. Repeating %d questions.", count, available, needed)
 
 		// Create repeat pool and shuffle it
-		repeatPool := make([]VerseResponse, len(verses))
+		repeatPool := make([]QuizQuestionResponse, len(verses))
 		copy(repeatPool, verses)
 		rand.Shuffle(len(repeatPool), func(i, j int) {
 			repeatPool[i], repeatPool[j] = repeatPool[j], repeatPool[i]
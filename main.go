@@ -10,6 +10,7 @@ import (
 	"time"
 	"ubible/database"
 	"ubible/handlers"
+	"ubible/handlers/admin"
 	"ubible/middleware"
 	"ubible/services"
 
@@ -28,14 +29,28 @@ func main() {
 	// Initialize database
 	database.InitDB()
 
+	// Resume multiplayer games interrupted by a restart
+	handlers.RestoreActiveGames()
+
 	// Initialize team handlers
-	// handlers.InitTeamHandlers() // TODO: Uncomment after migrating teams.go
+	handlers.InitTeamHandlers()
 
 	// Load verses from files
 	log.Println("Loading verses from files...")
 	services.LoadVersesFromFiles()
 	services.LoadVersesFromTXT()
 
+	// Load Bible translations, seeding the bundled ones on first run
+	services.Bible.Init()
+
+	// Activate and close team challenges on schedule
+	services.Challenges.Start()
+	defer services.Challenges.Stop()
+
+	// Roll seasons over when they end
+	services.Seasons.Start()
+	defer services.Seasons.Stop()
+
 	// Initialize cleanup service
 	services.InitCleanupService()
 	defer func() {
@@ -194,14 +209,60 @@ func main() {
 
 	// Quiz (migrated to net/http)
 	route("/api/questions/quiz", chain(
-		mh(http.MethodGet, handlers.GetQuizQuestions),
+		middleware.OptionalAuthMiddleware(mh(http.MethodGet, handlers.GetQuizQuestions)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Single-player quiz sessions (server-graded)
+	route("/api/quiz/sessions", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.StartQuizSession)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/quiz/sessions/{id}", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetQuizSession)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/quiz/sessions/{id}/answer", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.SubmitQuizAnswer)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/quiz/sessions/{id}/finish", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.FinishQuizSession)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Game session bookkeeping used by quiz.html
+	route("/api/game/check-active", chain(
+		middleware.OptionalAuthMiddleware(mh(http.MethodGet, handlers.CheckActiveGame)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/game/start", chain(
+		middleware.OptionalAuthMiddleware(mh(http.MethodPost, handlers.StartGameSession)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/game/end", chain(
+		middleware.OptionalAuthMiddleware(mh(http.MethodPost, handlers.EndGameSession)),
 		globalRL,
 		middleware.HTTPCORSMiddleware(allowed),
 	))
 
 	// Practice
 	route("/api/practice/cards", chain(
-		mh(http.MethodGet, handlers.GetPracticeCards),
+		middleware.OptionalAuthMiddleware(mh(http.MethodGet, handlers.GetPracticeCards)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Bible translations
+	route("/api/bible/translations", chain(
+		mh(http.MethodGet, handlers.GetBibleTranslations),
 		globalRL,
 		middleware.HTTPCORSMiddleware(allowed),
 	))
@@ -218,6 +279,481 @@ func main() {
 		middleware.HTTPCORSMiddleware(allowed),
 	))
 
+	// Progression
+	route("/api/progression", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetProgression)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Teams
+	route("/api/teams", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetMyTeams)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/create", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.CreateTeam)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/join", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.JoinTeam)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/search", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.SearchTeams)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/popular", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetPopularTeams)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetTeam)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/update", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.UpdateTeam)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/delete", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.DeleteTeam)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/leave", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.LeaveTeam)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/transfer", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.TransferTeamOwnership)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/members", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetTeamMembers)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/members/{userId}/remove", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.RemoveTeamMember)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/members/{userId}/promote", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.PromoteTeamMember)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/members/{userId}/demote", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.DemoteTeamMember)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/leaderboard", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetTeamLeaderboard)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/stats", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetTeamStats)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/challenges", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetMyChallenges)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/challenges", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetTeamChallenges)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/challenges/create", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.CreateTeamChallenge)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Team challenges
+	route("/api/challenges/{id}", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetChallenge)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/challenges/{id}/update", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.UpdateTeamChallenge)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/challenges/{id}/cancel", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.CancelTeamChallenge)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/challenges/{id}/join", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.JoinChallenge)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/challenges/{id}/leave", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.LeaveChallenge)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/challenges/{id}/start", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.StartChallengeAttempt)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/challenges/{id}/leaderboard", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetChallengeLeaderboard)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Daily and weekly challenges
+	route("/api/daily-challenges/{period}", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetDailyChallenge)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/daily-challenges/{period}/start", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.StartDailyChallenge)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/daily-challenges/{period}/leaderboard", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetDailyChallengeLeaderboard)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Team themes (verse collections)
+	route("/api/teams/themes", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetMyTeamThemes)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/themes", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetTeamThemes)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/themes/tags", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetTeamThemeTags)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/teams/{id}/themes/create", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.CreateTeamTheme)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/team-themes/{id}", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetTeamTheme)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/team-themes/{id}/update", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.UpdateTeamTheme)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/team-themes/{id}/delete", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.DeleteTeamTheme)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/team-themes/{id}/play", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.PlayTeamTheme)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/team-themes/{id}/publish", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.PublishTeamTheme)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Users
+	route("/api/users/{id}", chain(
+		middleware.OptionalAuthMiddleware(mh(http.MethodGet, handlers.GetUserProfile)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Ratings
+	route("/api/users/{id}/rating-history", chain(
+		mh(http.MethodGet, handlers.GetRatingHistory),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Leaderboards
+	route("/api/leaderboard", chain(
+		mh(http.MethodGet, handlers.GetLeaderboardHTTP),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/leaderboard/season", chain(
+		mh(http.MethodGet, handlers.GetSeasonLeaderboardHTTP),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/leaderboard/friends", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetFriendsLeaderboardHTTP)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/leaderboard/themes/{id}", chain(
+		mh(http.MethodGet, handlers.GetThemeLeaderboardHTTP),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/leaderboard/user/{id}", chain(
+		mh(http.MethodGet, handlers.GetUserRankHTTP),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/leaderboard/around/{id}", chain(
+		mh(http.MethodGet, handlers.GetLeaderboardAroundUserHTTP),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Match replays
+	route("/api/games/{id}/replay", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetGameReplay)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Seasons
+	route("/api/seasons", chain(
+		mh(http.MethodGet, handlers.GetSeasons),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/seasons/current", chain(
+		middleware.OptionalAuthMiddleware(mh(http.MethodGet, handlers.GetCurrentSeason)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/seasons/{number}/standings", chain(
+		middleware.OptionalAuthMiddleware(mh(http.MethodGet, handlers.GetSeasonStandings)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Friends
+	route("/api/friends", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetFriends)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/friends/requests", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetFriendRequests)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/friends/request", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.SendFriendRequest)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/friends/accept", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.AcceptFriendRequest)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/friends/reject", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.RejectFriendRequest)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/friends/cancel", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.CancelFriendRequest)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/friends/blocked", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetBlockedUsers)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/friends/{id}/remove", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.RemoveFriend)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/friends/{id}/mutual", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetMutualFriends)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/friends/{id}/block", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.BlockUser)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/friends/{id}/unblock", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.UnblockUser)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Notifications
+	route("/api/notifications", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetNotifications)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/notifications/unread-count", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetUnreadNotificationCount)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/notifications/read", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.MarkNotificationsRead)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/notifications/preferences", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetNotificationPreferences)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/notifications/preferences/update", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.UpdateNotificationPreferences)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Power-ups
+	route("/api/powerups/inventory", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetPowerUpInventory)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/powerups/use", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.UsePowerUp)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Shop
+	route("/api/shop/catalog", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetShopCatalog)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/shop/purchase", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.PurchaseShopItem)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/powerups/purchase", chain(
+		middleware.AuthMiddleware(mh(http.MethodPost, handlers.PurchaseShopItem)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/shop/ledger", chain(
+		middleware.AuthMiddleware(mh(http.MethodGet, handlers.GetFaithPointHistory)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
+	// Admin
+	route("/api/admin/achievements", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodGet, admin.GetAchievements)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/achievements/create", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodPost, admin.CreateAchievement)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/achievements/{id}/update", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodPost, admin.UpdateAchievement)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/achievements/{id}/delete", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodPost, admin.DeleteAchievement)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/challenges", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodGet, admin.GetChallenges)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/challenges/create", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodPost, admin.CreateChallenge)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/challenges/{id}/update", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodPost, admin.UpdateChallenge)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/challenges/{id}/delete", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodPost, admin.DeleteChallenge)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/daily-challenges", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodGet, admin.GetDailyChallenges)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/daily-challenges/{period}/{key}/pin", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodPost, admin.PinDailyChallenge)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/daily-challenges/{period}/{key}/unpin", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodPost, admin.UnpinDailyChallenge)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/team-themes", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodGet, admin.GetTeamThemeReviews)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/team-themes/{id}/approve", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodPost, admin.ApproveTeamTheme)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/team-themes/{id}/reject", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodPost, admin.RejectTeamTheme)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/games/{id}/replay", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodGet, admin.GetGameReplay)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+	route("/api/admin/users/{id}/ledger", chain(
+		middleware.AdminAuthMiddleware(mh(http.MethodGet, admin.GetUserLedger)),
+		globalRL,
+		middleware.HTTPCORSMiddleware(allowed),
+	))
+
 	// Debug
 	route("/api/debug/rooms", chain(
 		mh(http.MethodGet, handlers.GetActiveRooms),
@@ -16,6 +16,10 @@ type Achievement struct {
 	FPReward    int    `gorm:"default:0" json:"fp_reward"`
 	PowerUpReward   string `json:"powerup_reward,omitempty"`
 	PowerUpQuantity int    `json:"powerup_quantity,omitempty"`
+
+	// Unlock rule as JSON, e.g. {"event":"multiplayer","all":[{"metric":"won","value":1},{"metric":"players","value":4}]}
+	// See services.AchievementCriteria for the schema. Empty = never unlocked automatically.
+	Criteria string `gorm:"type:text" json:"criteria"`
 	
 	// Timestamps
 	CreatedAt time.Time `json:"created_at"`
@@ -0,0 +1,33 @@
+// models/bible.go - Bible translations and their verse text
+package models
+
+import "time"
+
+// BibleTranslation is a translation held in the verse store
+type BibleTranslation struct {
+	Code        string    `json:"code" gorm:"primaryKey;size:20"` // KJV, SWNT...
+	Name        string    `json:"name" gorm:"size:100"`
+	Language    string    `json:"language" gorm:"size:10;default:'en'"` // Book names for references: en, sw
+	Description string    `json:"description" gorm:"type:text"`
+	Source      string    `json:"source" gorm:"size:255"` // File(s) the text was imported from
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BibleVerse is one verse of one translation. Book is the OSIS book ID.
+type BibleVerse struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Translation string `json:"translation" gorm:"size:20;not null;default:'KJV';uniqueIndex:idx_bible_verses_ref,priority:1"`
+	Book        string `json:"book" gorm:"size:20;not null;uniqueIndex:idx_bible_verses_ref,priority:2"`
+	Chapter     int    `json:"chapter" gorm:"not null;uniqueIndex:idx_bible_verses_ref,priority:3"`
+	Verse       int    `json:"verse" gorm:"not null;uniqueIndex:idx_bible_verses_ref,priority:4"`
+	Text        string `json:"text" gorm:"type:text;not null"`
+}
+
+func (BibleTranslation) TableName() string {
+	return "bible_translations"
+}
+
+func (BibleVerse) TableName() string {
+	return "bible_verses"
+}
@@ -2,6 +2,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -15,6 +16,14 @@ const (
 	ChallengeStatusCancelled ChallengeStatus = "cancelled"
 )
 
+// Challenge participant status constants
+const (
+	ParticipantStatusJoined       = "joined"
+	ParticipantStatusPlaying      = "playing"
+	ParticipantStatusCompleted    = "completed"
+	ParticipantStatusDidNotFinish = "did_not_finish"
+)
+
 // Challenge represents a team challenge/competition
 type Challenge struct {
 	ID              uint            `json:"id" gorm:"primaryKey"`
@@ -36,6 +45,8 @@ type Challenge struct {
 	UpdatedAt       time.Time       `json:"updated_at"`
 	StartedAt       *time.Time      `json:"started_at"`
 	CompletedAt     *time.Time      `json:"completed_at"`
+	CancelReason    string          `json:"cancel_reason,omitempty" gorm:"size:255"`
+	QuestionsJSON   string          `json:"-" gorm:"type:text"` // Fixed question set, chosen at activation
 	Participants    []ChallengeParticipant `json:"participants,omitempty" gorm:"foreignKey:ChallengeID"`
 }
 
@@ -53,6 +64,8 @@ type ChallengeParticipant struct {
 	Incorrect   int        `json:"incorrect" gorm:"default:0"`
 	CompletedAt *time.Time `json:"completed_at"`
 	Status      string     `json:"status" gorm:"default:'joined'"`
+	SessionID   string     `json:"session_id,omitempty" gorm:"size:100"` // Quiz session for the attempt
+	Rank        int        `json:"rank" gorm:"default:0"`                // Final placement, 0 until completed
 }
 
 func (Challenge) TableName() string {
@@ -62,3 +75,23 @@ func (Challenge) TableName() string {
 func (ChallengeParticipant) TableName() string {
 	return "challenge_participants"
 }
+
+// GetQuestions returns the challenge's fixed question set
+func (c *Challenge) GetQuestions() ([]QuestionData, error) {
+	var questions []QuestionData
+	if c.QuestionsJSON == "" {
+		return questions, nil
+	}
+	err := json.Unmarshal([]byte(c.QuestionsJSON), &questions)
+	return questions, err
+}
+
+// SetQuestions fixes the challenge's question set
+func (c *Challenge) SetQuestions(questions []QuestionData) error {
+	data, err := json.Marshal(questions)
+	if err != nil {
+		return err
+	}
+	c.QuestionsJSON = string(data)
+	return nil
+}
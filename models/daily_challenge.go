@@ -0,0 +1,76 @@
+// models/daily_challenge.go - Daily and weekly challenge question sets and attempts
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Daily challenge periods
+const (
+	PeriodDaily  = "daily"
+	PeriodWeekly = "weekly"
+)
+
+// DailyChallenge is the question set shared by every player for one UTC day
+// or ISO week. It is derived from a seed on first request unless an admin
+// pinned it in advance.
+type DailyChallenge struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	Period        string    `json:"period" gorm:"not null;size:10;uniqueIndex:idx_daily_challenge_period"`
+	PeriodKey     string    `json:"period_key" gorm:"not null;size:10;uniqueIndex:idx_daily_challenge_period"` // 2006-01-02 or 2006-W01
+	Title         string    `json:"title" gorm:"size:100"`
+	Description   string    `json:"description" gorm:"type:text"`
+	TimeLimit     int       `json:"time_limit" gorm:"not null"`
+	QuestionCount int       `json:"question_count" gorm:"not null"`
+	QuestionsJSON string    `json:"-" gorm:"type:text"`
+	IsPinned      bool      `json:"is_pinned" gorm:"default:false"` // Set by an admin rather than derived
+	PinnedBy      *uint     `json:"pinned_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// DailyChallengeAttempt is a user's one scored attempt at a daily or weekly challenge
+type DailyChallengeAttempt struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	DailyChallengeID uint       `json:"daily_challenge_id" gorm:"not null;uniqueIndex:idx_daily_attempt_user"`
+	UserID           uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_daily_attempt_user;index"`
+	User             *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	SessionID        string     `json:"session_id" gorm:"size:100"`
+	Status           string     `json:"status" gorm:"not null;size:20;default:'playing'"` // playing, completed
+	Score            int        `json:"score" gorm:"default:0"`
+	CorrectAnswers   int        `json:"correct_answers" gorm:"default:0"`
+	TotalQuestions   int        `json:"total_questions" gorm:"default:0"`
+	TimeSpent        int        `json:"time_spent" gorm:"default:0"` // Seconds
+	StartedAt        time.Time  `json:"started_at"`
+	CompletedAt      *time.Time `json:"completed_at"`
+}
+
+func (DailyChallenge) TableName() string {
+	return "daily_challenges"
+}
+
+func (DailyChallengeAttempt) TableName() string {
+	return "daily_challenge_attempts"
+}
+
+// GetQuestions returns the challenge's question set
+func (d *DailyChallenge) GetQuestions() ([]QuestionData, error) {
+	var questions []QuestionData
+	if d.QuestionsJSON == "" {
+		return questions, nil
+	}
+	err := json.Unmarshal([]byte(d.QuestionsJSON), &questions)
+	return questions, err
+}
+
+// SetQuestions fixes the challenge's question set
+func (d *DailyChallenge) SetQuestions(questions []QuestionData) error {
+	data, err := json.Marshal(questions)
+	if err != nil {
+		return err
+	}
+	d.QuestionsJSON = string(data)
+	d.QuestionCount = len(questions)
+	return nil
+}
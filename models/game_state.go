@@ -56,6 +56,12 @@ type ActiveGameState struct {
 	// Multiplayer Specific
 	HostPlayerID string `json:"host_player_id" gorm:"size:100"`
 	Players      string `json:"players" gorm:"type:text"`                       // JSON array of player data
+
+	// Single-player Specific
+	UserID        *uint  `json:"user_id,omitempty" gorm:"index"`
+	SoloStateJSON string `json:"solo_state_json" gorm:"type:text"` // Graded answers and pending power-up effects
+	ChallengeID   *uint  `json:"challenge_id,omitempty" gorm:"index"` // Team challenge this attempt counts for
+	DailyChallengeID *uint `json:"daily_challenge_id,omitempty" gorm:"index"` // Daily or weekly challenge this attempt counts for
 }
 
 // TableName specifies the table name for ActiveGameState
@@ -84,10 +90,59 @@ type GameStateSnapshot struct {
 // QuestionData represents a single question in the game state
 type QuestionData struct {
 	ID            int      `json:"id"`
+	ThemeID       uint     `json:"theme_id,omitempty"`
+	ThemeName     string   `json:"theme_name,omitempty"`
 	Text          string   `json:"text"`
 	CorrectAnswer string   `json:"correct_answer"`
 	Options       []string `json:"options"`
 	Reference     string   `json:"reference"`
+	Difficulty    string   `json:"difficulty,omitempty"`
+}
+
+// PlayerSession is one player's checkpointed state within a multiplayer game
+type PlayerSession struct {
+	PlayerID       string `json:"player_id"`
+	Username       string `json:"username"`
+	UserID         *uint  `json:"user_id,omitempty"`
+	IsGuest        bool   `json:"is_guest"`
+	IsHost         bool   `json:"is_host"`
+	IsPlaying      bool   `json:"is_playing"`
+	Score          int    `json:"score"`
+	CorrectAnswers int    `json:"correct_answers"`
+	WrongAnswers   int    `json:"wrong_answers"`
+
+	// Answer to the current question, if already submitted
+	Answered       bool   `json:"answered"`
+	Answer         string `json:"answer,omitempty"`
+	AnswerCorrect  bool   `json:"answer_correct,omitempty"`
+	PointsEarned   int    `json:"points_earned,omitempty"`
+	ResponseTimeMs int64  `json:"response_time_ms,omitempty"`
+
+	// Power-up state
+	PowerUpsUsed  []string `json:"powerups_used,omitempty"`  // Used on the current question
+	TimeBonus     int      `json:"time_bonus,omitempty"`     // Extra seconds on the current question (time freeze)
+	DoublePending bool     `json:"double_pending,omitempty"` // Next graded answer scores double
+}
+
+// SoloAnswer is one graded answer in a single-player session
+type SoloAnswer struct {
+	QuestionIndex  int    `json:"question_index"`
+	QuestionID     int    `json:"question_id"`
+	Answer         string `json:"answer"`
+	Correct        bool   `json:"correct"`
+	PointsEarned   int    `json:"points_earned"`
+	ResponseTimeMs int64  `json:"response_time_ms"`
+	TimedOut       bool   `json:"timed_out,omitempty"`
+	Skipped        bool   `json:"skipped,omitempty"`
+	Doubled        bool   `json:"doubled,omitempty"`
+}
+
+// SoloState is a single-player session's answer history and power-up effects
+type SoloState struct {
+	Answers      []SoloAnswer `json:"answers"`
+	TimeBonus    int          `json:"time_bonus,omitempty"`    // Extra seconds on the current question
+	PowerUpsUsed []string     `json:"powerups_used,omitempty"` // Power-ups used on the current question
+	DoubleNext   bool         `json:"double_next,omitempty"`   // Next answer scores double
 }
 
 // Helper methods to marshal/unmarshal JSON fields
@@ -164,6 +219,24 @@ func (ags *ActiveGameState) SetAuthorizedPlayers(players map[string]bool) error
 	return nil
 }
 
+func (ags *ActiveGameState) GetPlayerSessions() (map[string]PlayerSession, error) {
+	sessions := make(map[string]PlayerSession)
+	if ags.PlayerSessionsJSON == "" {
+		return sessions, nil
+	}
+	err := json.Unmarshal([]byte(ags.PlayerSessionsJSON), &sessions)
+	return sessions, err
+}
+
+func (ags *ActiveGameState) SetPlayerSessions(sessions map[string]PlayerSession) error {
+	data, err := json.Marshal(sessions)
+	if err != nil {
+		return err
+	}
+	ags.PlayerSessionsJSON = string(data)
+	return nil
+}
+
 // CreateSnapshot generates a complete snapshot for client restoration
 func (ags *ActiveGameState) CreateSnapshot() (*GameStateSnapshot, error) {
 	questions, err := ags.GetQuestionsData()
@@ -203,3 +276,21 @@ func (ags *ActiveGameState) CreateSnapshot() (*GameStateSnapshot, error) {
 		AuthorizedPlayers:  authorized,
 	}, nil
 }
+
+func (ags *ActiveGameState) GetSoloState() (SoloState, error) {
+	var state SoloState
+	if ags.SoloStateJSON == "" {
+		return state, nil
+	}
+	err := json.Unmarshal([]byte(ags.SoloStateJSON), &state)
+	return state, err
+}
+
+func (ags *ActiveGameState) SetSoloState(state SoloState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	ags.SoloStateJSON = string(data)
+	return nil
+}
@@ -35,6 +35,7 @@ type Question struct {
 	CorrectAnswer string    `json:"correct_answer" gorm:"not null;size:500"`
 	WrongAnswers  string    `json:"wrong_answers" gorm:"not null;type:text"`
 	Reference     string    `json:"reference" gorm:"size:100"`
+	OSIS          string    `json:"osis,omitempty" gorm:"column:osis;size:200;index"` // Canonical reference ID, e.g. John.3.16
 	Difficulty    string    `json:"difficulty" gorm:"default:'medium';size:20"`
 	CreatedAt     time.Time `json:"created_at"`
 }
@@ -78,6 +79,15 @@ type FriendRequest struct {
 	CreatedAt  time.Time `json:"created_at"`
 }
 
+// UserBlock stops BlockedID from sending friend requests or invites to UserID
+type UserBlock struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_blocks_pair"`
+	BlockedID uint      `json:"blocked_id" gorm:"not null;uniqueIndex:idx_user_blocks_pair;index"`
+	Blocked   *User     `json:"blocked,omitempty" gorm:"foreignKey:BlockedID"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // PowerUp represents a power-up item
 type PowerUp struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
@@ -109,6 +119,10 @@ func (FriendRequest) TableName() string {
 	return "friend_requests"
 }
 
+func (UserBlock) TableName() string {
+	return "user_blocks"
+}
+
 func (PowerUp) TableName() string {
 	return "power_ups"
 }
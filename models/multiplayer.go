@@ -67,6 +67,12 @@ type MultiplayerGamePlayer struct {
 	XPEarned          int       `json:"xp_earned" gorm:"default:0"`
 	FPEarned          int       `json:"fp_earned" gorm:"default:0"`
 
+	// Rating change from this game (registered players only)
+	RatingBefore      float64   `json:"rating_before" gorm:"default:0"`
+	RatingAfter       float64   `json:"rating_after" gorm:"default:0"`
+	RatingChange      float64   `json:"rating_change" gorm:"default:0"`
+	RatingDeviation   float64   `json:"rating_deviation" gorm:"default:0"` // Deviation after the game
+
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
 }
@@ -0,0 +1,77 @@
+// models/notification.go - Per-user notifications and mute preferences
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Notification categories
+const (
+	NotificationCategorySocial      = "social"      // Friend requests
+	NotificationCategoryAchievement = "achievement" // Achievement unlocks
+	NotificationCategoryChallenge   = "challenge"   // Team challenge starts, results and cancellations
+	NotificationCategoryProgress    = "progress"    // Level-ups and season rewards
+)
+
+// NotificationCategories lists every category a user can mute
+var NotificationCategories = []string{
+	NotificationCategorySocial,
+	NotificationCategoryAchievement,
+	NotificationCategoryChallenge,
+	NotificationCategoryProgress,
+}
+
+// Notification is a user-level event kept for the user's inbox
+type Notification struct {
+	ID        uint                   `json:"id" gorm:"primaryKey"`
+	UserID    uint                   `json:"user_id" gorm:"not null;index:idx_notifications_user,priority:1"`
+	Category  string                 `json:"category" gorm:"not null;size:20"`
+	Type      string                 `json:"type" gorm:"not null;size:50"` // friend_request, achievement_unlocked, level_up...
+	Title     string                 `json:"title" gorm:"size:200"`
+	Body      string                 `json:"body" gorm:"size:500"`
+	DataJSON  string                 `json:"-" gorm:"column:data;type:text"`
+	Data      map[string]interface{} `json:"data,omitempty" gorm:"-"`
+	ReadAt    *time.Time             `json:"read_at"`
+	CreatedAt time.Time              `json:"created_at" gorm:"index:idx_notifications_user,priority:2,sort:desc"`
+}
+
+// NotificationMute silences one category of notifications for a user
+type NotificationMute struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_notification_mutes_user"`
+	Category  string    `json:"category" gorm:"not null;size:20;uniqueIndex:idx_notification_mutes_user"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+func (NotificationMute) TableName() string {
+	return "notification_mutes"
+}
+
+// SetData stores the notification's structured payload
+func (n *Notification) SetData(data map[string]interface{}) error {
+	n.Data = data
+	if len(data) == 0 {
+		n.DataJSON = ""
+		return nil
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	n.DataJSON = string(encoded)
+	return nil
+}
+
+// LoadData decodes the stored payload into Data
+func (n *Notification) LoadData() error {
+	if n.DataJSON == "" {
+		n.Data = nil
+		return nil
+	}
+	return json.Unmarshal([]byte(n.DataJSON), &n.Data)
+}
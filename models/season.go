@@ -0,0 +1,80 @@
+// models/season.go - Competitive seasons, season stats and archived standings
+package models
+
+import "time"
+
+// Season statuses
+const (
+	SeasonStatusActive = "active"
+	SeasonStatusClosed = "closed"
+)
+
+// Season reward tiers, best first
+const (
+	SeasonTierLegend  = "legend"
+	SeasonTierDiamond = "diamond"
+	SeasonTierGold    = "gold"
+	SeasonTierSilver  = "silver"
+	SeasonTierBronze  = "bronze"
+)
+
+// Season is one competitive period. Exactly one season is active at a time;
+// at its end the standings are archived and the next season begins.
+type Season struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Number    int        `json:"number" gorm:"not null;uniqueIndex"`
+	Name      string     `json:"name" gorm:"size:100"`
+	StartsAt  time.Time  `json:"starts_at" gorm:"not null"`
+	EndsAt    time.Time  `json:"ends_at" gorm:"not null;index"`
+	Status    string     `json:"status" gorm:"not null;size:20;index;default:'active'"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// SeasonStat is a user's stats within one season, updated as games finish
+type SeasonStat struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	SeasonID      uint      `json:"season_id" gorm:"not null;uniqueIndex:idx_season_stat_user"`
+	UserID        uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_season_stat_user;index"`
+	Games         int       `json:"games" gorm:"default:0"`
+	RatedGames    int       `json:"rated_games" gorm:"default:0"` // Multiplayer games
+	Wins          int       `json:"wins" gorm:"default:0"`
+	PerfectGames  int       `json:"perfect_games" gorm:"default:0"`
+	Score         int       `json:"score" gorm:"default:0"`
+	XPEarned      int       `json:"xp_earned" gorm:"default:0"`
+	CurrentStreak int       `json:"current_streak" gorm:"default:0"`
+	BestStreak    int       `json:"best_streak" gorm:"default:0"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// SeasonStanding is a user's archived final position in a closed season
+type SeasonStanding struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	SeasonID     uint      `json:"season_id" gorm:"not null;uniqueIndex:idx_season_standing_user;index:idx_season_standing_rank,priority:1"`
+	UserID       uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_season_standing_user;index"`
+	User         *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Rank         int       `json:"rank" gorm:"not null;index:idx_season_standing_rank,priority:2"`
+	Rating       float64   `json:"rating"` // Before the soft reset
+	Games        int       `json:"games"`
+	RatedGames   int       `json:"rated_games"`
+	Wins         int       `json:"wins"`
+	PerfectGames int       `json:"perfect_games"`
+	Score        int       `json:"score"`
+	XPEarned     int       `json:"xp_earned"`
+	BestStreak   int       `json:"best_streak"`
+	Tier         string    `json:"tier" gorm:"size:20"` // Empty when no reward was earned
+	FPReward     int       `json:"fp_reward"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (Season) TableName() string {
+	return "seasons"
+}
+
+func (SeasonStat) TableName() string {
+	return "season_stats"
+}
+
+func (SeasonStanding) TableName() string {
+	return "season_standings"
+}
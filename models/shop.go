@@ -0,0 +1,45 @@
+// models/shop.go - Faith Points ledger and shop unlocks
+package models
+
+import (
+	"time"
+)
+
+// Faith Points ledger reasons
+const (
+	FPReasonGameReward      = "game_reward"
+	FPReasonLevelUp         = "level_up"
+	FPReasonPurchase        = "purchase"
+	FPReasonAchievement     = "achievement"
+	FPReasonAdminAdjustment = "admin_adjustment"
+	FPReasonSeasonReward    = "season_reward"
+)
+
+// FaithPointTransaction is one append-only entry in a user's Faith Points ledger.
+// Rows are never updated or deleted; the latest BalanceAfter matches User.FaithPoints.
+type FaithPointTransaction struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"not null;index"`
+	Amount       int       `json:"amount" gorm:"not null"`        // Positive = credit, negative = debit
+	BalanceAfter int       `json:"balance_after" gorm:"not null"` // User balance once applied
+	Reason       string    `json:"reason" gorm:"not null;size:30;index"`
+	Reference    string    `json:"reference" gorm:"size:100"` // Game ID, shop item, admin ID...
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+}
+
+// ThemeUnlock records a theme a user bought with Faith Points
+type ThemeUnlock struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_theme_unlock_user_theme"`
+	ThemeID    uint      `json:"theme_id" gorm:"not null;uniqueIndex:idx_theme_unlock_user_theme"`
+	Cost       int       `json:"cost" gorm:"not null"`
+	UnlockedAt time.Time `json:"unlocked_at"`
+}
+
+func (FaithPointTransaction) TableName() string {
+	return "faith_point_transactions"
+}
+
+func (ThemeUnlock) TableName() string {
+	return "theme_unlocks"
+}
@@ -5,33 +5,75 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 )
 
 // StringArray is a custom type for PostgreSQL text[] arrays
 type StringArray []string
 
-// Scan implements sql.Scanner interface
+// Scan implements sql.Scanner interface. Accepts the PostgreSQL array literal
+// ({a,"b c"}) and, for rows written before it was used, a JSON array.
 func (s *StringArray) Scan(value interface{}) error {
-	if value == nil {
+	var raw string
+	switch v := value.(type) {
+	case nil:
 		*s = []string{}
 		return nil
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return errors.New("failed to scan StringArray: unsupported type")
 	}
 
-	bytes, ok := value.([]byte)
-	if !ok {
-		return errors.New("failed to scan StringArray: value is not []byte")
+	if strings.HasPrefix(raw, "[") {
+		return json.Unmarshal([]byte(raw), s)
+	}
+	if !strings.HasPrefix(raw, "{") || !strings.HasSuffix(raw, "}") {
+		return errors.New("failed to scan StringArray: not an array")
+	}
+
+	result := []string{}
+	body := raw[1 : len(raw)-1]
+	for i := 0; i < len(body); {
+		var elem strings.Builder
+		quoted := body[i] == '"'
+		if quoted {
+			i++
+			for i < len(body) && body[i] != '"' {
+				if body[i] == '\\' && i+1 < len(body) {
+					i++
+				}
+				elem.WriteByte(body[i])
+				i++
+			}
+			i++ // Closing quote
+		} else {
+			for i < len(body) && body[i] != ',' {
+				elem.WriteByte(body[i])
+				i++
+			}
+		}
+		if quoted || elem.String() != "NULL" {
+			result = append(result, elem.String())
+		}
+		i++ // Separator
 	}
 
-	return json.Unmarshal(bytes, s)
+	*s = result
+	return nil
 }
 
-// Value implements driver.Valuer interface
+// Value implements driver.Valuer interface as a PostgreSQL array literal
 func (s StringArray) Value() (driver.Value, error) {
-	if len(s) == 0 {
-		return "{}", nil
+	quoted := make([]string, len(s))
+	for i, elem := range s {
+		elem = strings.ReplaceAll(elem, `\`, `\\`)
+		quoted[i] = `"` + strings.ReplaceAll(elem, `"`, `\"`) + `"`
 	}
-	return json.Marshal(s)
+	return "{" + strings.Join(quoted, ",") + "}", nil
 }
 
 // TeamTheme represents a shared verse collection within a team
@@ -41,7 +83,7 @@ type TeamTheme struct {
 	Team        *Team       `json:"team,omitempty" gorm:"foreignKey:TeamID"`
 	Name        string      `json:"name" gorm:"not null;size:100"`
 	Description string      `json:"description" gorm:"type:text"`
-	VerseFile   string      `json:"verse_file" gorm:"not null;size:255"` // Path to verse file or content
+	VerseFile   string      `json:"verse_file" gorm:"not null;type:text"` // Path to verse file or content
 	IsPublic    bool        `json:"is_public" gorm:"default:false;index"`
 	Tags        StringArray `json:"tags" gorm:"type:text[]"` // Categories/tags for filtering
 	CreatedBy   uint        `json:"created_by_user_id" gorm:"column:created_by;not null"` // JSON shows as created_by_user_id
@@ -51,8 +93,22 @@ type TeamTheme struct {
 	UsageCount  int         `json:"usage_count" gorm:"default:0"` // How many times used
 	LastUsed    *time.Time  `json:"last_used"`
 	IsActive    bool        `json:"is_active" gorm:"default:true;index"`
+
+	// Publishing to the global theme catalog, after admin review
+	PublishStatus    string     `json:"publish_status,omitempty" gorm:"size:20;index"` // pending, approved, rejected
+	PublishedThemeID *uint      `json:"published_theme_id,omitempty"`
+	ReviewNote       string     `json:"review_note,omitempty" gorm:"type:text"`
+	ReviewedBy       *uint      `json:"reviewed_by,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty"`
 }
 
+// Team theme publish statuses
+const (
+	ThemePublishPending  = "pending"
+	ThemePublishApproved = "approved"
+	ThemePublishRejected = "rejected"
+)
+
 // TableName specifies the table name for TeamTheme
 func (TeamTheme) TableName() string {
 	return "team_themes"
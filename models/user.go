@@ -22,7 +22,12 @@ type User struct {
 	Level       int     `gorm:"default:1" json:"level"`
 	XP          int     `gorm:"default:0" json:"xp"`
 	FaithPoints int     `gorm:"default:0" json:"faith_points"`
-	Rating      float64 `gorm:"default:5.0" json:"rating"` // Chess-like rating: 0.0 - 10.0, starts at 5.0
+	Rating      float64 `gorm:"default:1500" json:"rating"` // Glicko-2 rating, starts at 1500
+
+	// Rating confidence (Glicko-2)
+	RatingDeviation  float64 `gorm:"default:350" json:"rating_deviation"`
+	RatingVolatility float64 `gorm:"default:0.06" json:"rating_volatility"`
+	RatedGames       int     `gorm:"default:0" json:"rated_games"`
 
 	// Stats
 	TotalGames    int `gorm:"default:0" json:"total_games"`
@@ -33,6 +38,11 @@ type User struct {
 	BestStreak    int `gorm:"default:0" json:"best_streak"`
 	QuitsCount    int `gorm:"default:0" json:"quits_count"` // Track abandoned quizzes
 
+	// Daily challenge streak (consecutive UTC days with a completed daily challenge)
+	DailyStreak        int    `gorm:"default:0" json:"daily_streak"`
+	BestDailyStreak    int    `gorm:"default:0" json:"best_daily_streak"`
+	LastDailyChallenge string `gorm:"size:10" json:"last_daily_challenge,omitempty"` // 2006-01-02 of the last completed daily
+
 	// Power-ups
 	PowerUp5050       int `gorm:"default:3" json:"powerup_5050"`
 	PowerUpTimeFreeze int `gorm:"default:3" json:"powerup_time_freeze"`
@@ -41,9 +51,10 @@ type User struct {
 	PowerUpDouble     int `gorm:"default:2" json:"powerup_double"`
 
 	// Quiz Preferences
-	SelectedThemes    string `gorm:"default:'[]'" json:"selected_themes"` // JSON array of theme IDs
-	QuizTimeLimit     int    `gorm:"default:10" json:"quiz_time_limit"`
-	QuizQuestionCount int    `gorm:"default:10" json:"quiz_question_count"`
+	SelectedThemes       string `gorm:"default:'[]'" json:"selected_themes"` // JSON array of theme IDs
+	QuizTimeLimit        int    `gorm:"default:10" json:"quiz_time_limit"`
+	QuizQuestionCount    int    `gorm:"default:10" json:"quiz_question_count"`
+	PreferredTranslation string `gorm:"size:20" json:"preferred_translation"` // Translation for quizzes and practice; empty plays themes as written
 
 	// Active Game Session
 	ActiveGameSession *string    `json:"active_game_session,omitempty"`
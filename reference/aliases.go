@@ -0,0 +1,184 @@
+// reference/aliases.go - Book name lookup by English and Swahili names and abbreviations
+package reference
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Language is the language a reference's book name is written in
+type Language string
+
+const (
+	English Language = "en"
+	Swahili Language = "sw"
+)
+
+// englishAliases lists the abbreviations and alternative names accepted for
+// each book besides its English name
+var englishAliases = map[string][]string{
+	"Gen":    {"Gn", "Ge"},
+	"Exod":   {"Exo", "Ex"},
+	"Lev":    {"Lv", "Le"},
+	"Num":    {"Nm", "Nb", "Nu"},
+	"Deut":   {"Deu", "Dt"},
+	"Josh":   {"Jos", "Jsh"},
+	"Judg":   {"Jdg", "Jg", "Jdgs"},
+	"Ruth":   {"Rut", "Rth", "Ru"},
+	"1Sam":   {"1 Sa", "1 Sm", "1 S"},
+	"2Sam":   {"2 Sa", "2 Sm", "2 S"},
+	"1Kgs":   {"1 Kings", "1 Kin", "1 Ki", "1 Kg"},
+	"2Kgs":   {"2 Kings", "2 Kin", "2 Ki", "2 Kg"},
+	"1Chr":   {"1 Chron", "1 Chro", "1 Ch"},
+	"2Chr":   {"2 Chron", "2 Chro", "2 Ch"},
+	"Ezra":   {"Ezr"},
+	"Neh":    {"Ne"},
+	"Esth":   {"Est", "Es"},
+	"Job":    {"Jb"},
+	"Ps":     {"Psalm", "Psa", "Psm", "Pss"},
+	"Prov":   {"Pro", "Prv", "Pr"},
+	"Eccl":   {"Ecc", "Ec", "Qoh", "Qoheleth"},
+	"Song":   {"Song of Songs", "Song of Sol", "SOS", "So", "Canticles", "Cant"},
+	"Isa":    {"Is"},
+	"Jer":    {"Je", "Jr"},
+	"Lam":    {"La"},
+	"Ezek":   {"Eze", "Ezk"},
+	"Dan":    {"Da", "Dn"},
+	"Hos":    {"Ho"},
+	"Joel":   {"Jl"},
+	"Amos":   {"Am"},
+	"Obad":   {"Oba", "Ob"},
+	"Jonah":  {"Jnh", "Jon"},
+	"Mic":    {"Mc"},
+	"Nah":    {"Na"},
+	"Hab":    {"Hb"},
+	"Zeph":   {"Zep", "Zp"},
+	"Hag":    {"Hg"},
+	"Zech":   {"Zec", "Zc"},
+	"Mal":    {"Ml"},
+	"Matt":   {"Mat", "Mt"},
+	"Mark":   {"Mrk", "Mar", "Mk", "Mr"},
+	"Luke":   {"Luk", "Lk"},
+	"John":   {"Joh", "Jhn", "Jn"},
+	"Acts":   {"Act", "Ac"},
+	"Rom":    {"Ro", "Rm"},
+	"1Cor":   {"1 Co"},
+	"2Cor":   {"2 Co"},
+	"Gal":    {"Ga"},
+	"Eph":    {"Ephes"},
+	"Phil":   {"Php", "Pp"},
+	"Col":    {"Co"},
+	"1Thess": {"1 Thes", "1 Th"},
+	"2Thess": {"2 Thes", "2 Th"},
+	"1Tim":   {"1 Ti"},
+	"2Tim":   {"2 Ti"},
+	"Titus":  {"Tit", "Ti"},
+	"Phlm":   {"Philem", "Phm", "Pm"},
+	"Heb":    {"He"},
+	"Jas":    {"Jam", "Jm"},
+	"1Pet":   {"1 Pe", "1 Pt", "1 P"},
+	"2Pet":   {"2 Pe", "2 Pt", "2 P"},
+	"1John":  {"1 Jn", "1 Jhn", "1 Joh", "1 Jo", "1 J"},
+	"2John":  {"2 Jn", "2 Jhn", "2 Joh", "2 Jo", "2 J"},
+	"3John":  {"3 Jn", "3 Jhn", "3 Joh", "3 Jo", "3 J"},
+	"Jude":   {"Jud", "Jd"},
+	"Rev":    {"Revelations", "Re", "The Revelation", "Apocalypse"},
+}
+
+// swahiliAliases lists the Swahili names accepted for each book besides its
+// Union Version name, including the short forms used in the verse files
+var swahiliAliases = map[string][]string{
+	"Lev":  {"Walawi"},
+	"Deut": {"Kumbukumbu"},
+	"1Chr": {"1 Nyakati"},
+	"2Chr": {"2 Nyakati"},
+	"Ps":   {"Zab"},
+	"Song": {"Wimbo wa Sulemani"},
+	"Acts": {"Matendo"},
+	"Gal":  {"Galatia"},
+	"Phil": {"Filipi"},
+	"Col":  {"Kolosai"},
+	"Heb":  {"Ebrania"},
+	"Rev":  {"Ufunuo wa Yohana"},
+}
+
+type bookName struct {
+	book *Book
+	lang Language
+}
+
+// bookNames maps every accepted name, as an aliasKey, to its book. English
+// names win where both languages spell a book the same way (Ezra, Hosea).
+var bookNames = func() map[string]bookName {
+	names := make(map[string]bookName)
+	add := func(name string, b *Book, lang Language) {
+		key := aliasKey(name)
+		if existing, ok := names[key]; ok {
+			if existing.book != b {
+				panic("reference: book name " + name + " is ambiguous")
+			}
+			return
+		}
+		names[key] = bookName{book: b, lang: lang}
+	}
+
+	for _, b := range Canon {
+		add(b.Name, b, English)
+		add(b.ID, b, English)
+		for _, alias := range englishAliases[b.ID] {
+			add(alias, b, English)
+		}
+	}
+	for _, b := range Canon {
+		add(b.Swahili, b, Swahili)
+		for _, alias := range swahiliAliases[b.ID] {
+			add(alias, b, Swahili)
+		}
+	}
+	return names
+}()
+
+// LookupBook finds a book by any accepted name or abbreviation and reports the
+// language the name is in. Case, periods and spacing are ignored, and a
+// leading I/II/III or First/Second/Third is read as 1/2/3.
+func LookupBook(name string) (*Book, Language, bool) {
+	entry, ok := bookNames[aliasKey(name)]
+	if !ok {
+		return nil, "", false
+	}
+	return entry.book, entry.lang, true
+}
+
+// aliasKey reduces a book name to the form used for lookups: "1 Cor." and
+// "I cor" both become "1cor"
+func aliasKey(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for prefix, number := range ordinalPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			name = number + name[len(prefix):]
+			break
+		}
+	}
+
+	var sb strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// ordinalPrefixes are the spelled-out forms of the 1/2/3 before a book name.
+// The trailing space keeps "isaiah" and "iii" from clashing.
+var ordinalPrefixes = map[string]string{
+	"iii ":    "3",
+	"ii ":     "2",
+	"i ":      "1",
+	"first ":  "1",
+	"second ": "2",
+	"third ":  "3",
+	"1st ":    "1",
+	"2nd ":    "2",
+	"3rd ":    "3",
+}
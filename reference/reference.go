@@ -0,0 +1,368 @@
+// reference/reference.go - Parsing, validation and formatting of Bible references
+package reference
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidReference  = errors.New("invalid reference")
+	ErrUnknownBook       = errors.New("unknown book")
+	ErrChapterOutOfRange = errors.New("chapter out of range")
+	ErrVerseOutOfRange   = errors.New("verse out of range")
+)
+
+// Passage is a single verse, a verse range or a span of whole chapters in
+// one book. Verse and EndVerse are 0 when the passage covers whole chapters.
+type Passage struct {
+	Book       *Book
+	Chapter    int
+	Verse      int
+	EndChapter int
+	EndVerse   int
+}
+
+// Reference is a list of passages, as in "John 3:16, 18; Rom 5:8". Lang is
+// the language its first book name was written in and is used for display.
+type Reference struct {
+	Passages []Passage
+	Lang     Language
+}
+
+var (
+	// segmentPattern splits "1 Cor. 13:4-7, 13" into the book name and the
+	// chapter/verse list. A segment without a book continues the previous one.
+	segmentPattern = regexp.MustCompile(`^((?:[1-3]\s*)?\pL[^\d]*?)\s*(\d[\d\s:.,\-]*)$`)
+	bareSpec       = regexp.MustCompile(`^\d[\d\s:.,\-]*$`)
+	dashes         = strings.NewReplacer("\u2010", "-", "\u2011", "-", "\u2012", "-", "\u2013", "-", "\u2014", "-", "\u2015", "-")
+	spaces         = strings.NewReplacer("\u202F", " ", "\u00A0", " ")
+)
+
+// Parse reads a reference such as "John 3:16", "Jn 3:16-18", "1 Cor 13:4-7, 13",
+// "Yohana 3:16; Warumi 5:8" or "Psalm 23". Book names may be English names,
+// common abbreviations or Swahili names. Chapters and verses are checked
+// against the canon.
+func Parse(s string) (Reference, error) {
+	s = strings.TrimSpace(spaces.Replace(dashes.Replace(s)))
+	if s == "" {
+		return Reference{}, fmt.Errorf("%w: empty", ErrInvalidReference)
+	}
+
+	var ref Reference
+	var book *Book
+	for _, segment := range strings.Split(s, ";") {
+		segment = strings.TrimSpace(segment)
+		if segment == "" {
+			continue
+		}
+
+		spec := segment
+		if !bareSpec.MatchString(segment) {
+			m := segmentPattern.FindStringSubmatch(segment)
+			if m == nil {
+				return Reference{}, fmt.Errorf("%w: %q", ErrInvalidReference, segment)
+			}
+			b, lang, ok := LookupBook(m[1])
+			if !ok {
+				return Reference{}, fmt.Errorf("%w: %q", ErrUnknownBook, strings.TrimSpace(m[1]))
+			}
+			if ref.Lang == "" {
+				ref.Lang = lang
+			}
+			book, spec = b, m[2]
+		}
+		if book == nil {
+			return Reference{}, fmt.Errorf("%w: %q has no book", ErrInvalidReference, segment)
+		}
+
+		passages, err := parseSpec(book, spec)
+		if err != nil {
+			return Reference{}, err
+		}
+		ref.Passages = append(ref.Passages, passages...)
+	}
+
+	if len(ref.Passages) == 0 {
+		return Reference{}, fmt.Errorf("%w: %q", ErrInvalidReference, s)
+	}
+	return ref, nil
+}
+
+// ParseOSIS reads an OSIS reference as produced by Reference.OSIS: "John.3.16",
+// "John.3.16-John.3.18", "Ps.23" or a comma-separated list of them. Book
+// names in the result are English.
+func ParseOSIS(id string) (Reference, error) {
+	ref := Reference{Lang: English}
+	for _, part := range strings.Split(id, ",") {
+		bounds := strings.Split(strings.TrimSpace(part), "-")
+		if len(bounds) > 2 {
+			return Reference{}, fmt.Errorf("%w: %q", ErrInvalidReference, part)
+		}
+
+		book, chapter, verse, err := parseOSISPoint(bounds[0])
+		if err != nil {
+			return Reference{}, err
+		}
+		p := Passage{Book: book, Chapter: chapter, Verse: verse, EndChapter: chapter, EndVerse: verse}
+		if len(bounds) == 2 {
+			endBook, endChapter, endVerse, err := parseOSISPoint(bounds[1])
+			if err != nil {
+				return Reference{}, err
+			}
+			if endBook != book {
+				return Reference{}, fmt.Errorf("%w: %q spans books", ErrInvalidReference, part)
+			}
+			p.EndChapter, p.EndVerse = endChapter, endVerse
+		}
+		if err := p.validate(); err != nil {
+			return Reference{}, err
+		}
+		ref.Passages = append(ref.Passages, p)
+	}
+	return ref, nil
+}
+
+func parseOSISPoint(s string) (*Book, int, int, error) {
+	fields := strings.Split(s, ".")
+	if len(fields) < 2 || len(fields) > 3 {
+		return nil, 0, 0, fmt.Errorf("%w: %q", ErrInvalidReference, s)
+	}
+	book := ByID(fields[0])
+	if book == nil {
+		return nil, 0, 0, fmt.Errorf("%w: %q", ErrUnknownBook, fields[0])
+	}
+	chapter, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("%w: %q", ErrInvalidReference, s)
+	}
+	verse := 0
+	if len(fields) == 3 {
+		if verse, err = strconv.Atoi(fields[2]); err != nil {
+			return nil, 0, 0, fmt.Errorf("%w: %q", ErrInvalidReference, s)
+		}
+	}
+	return book, chapter, verse, nil
+}
+
+// parseSpec reads the comma-separated chapters, verses and ranges that
+// follow a book name. A bare number after a verse is another verse in the
+// same chapter; otherwise it is a chapter, except in one-chapter books.
+func parseSpec(book *Book, spec string) ([]Passage, error) {
+	var passages []Passage
+	chapter, inVerses := 0, false
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		bounds := strings.Split(item, "-")
+		if len(bounds) > 2 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidReference, item)
+		}
+
+		var p Passage
+		var err error
+		p.Book = book
+		p.Chapter, p.Verse, err = parsePoint(book, bounds[0], chapter, inVerses)
+		if err != nil {
+			return nil, err
+		}
+		p.EndChapter, p.EndVerse = p.Chapter, p.Verse
+		if len(bounds) == 2 {
+			p.EndChapter, p.EndVerse, err = parsePoint(book, bounds[1], p.Chapter, p.Verse > 0)
+			if err != nil {
+				return nil, err
+			}
+			// "3-4:2" starts at the top of chapter 3
+			if p.Verse == 0 && p.EndVerse > 0 {
+				p.Verse = 1
+			}
+			if p.Verse > 0 && p.EndVerse == 0 {
+				p.EndVerse = book.VerseCount(p.EndChapter)
+			}
+		}
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+
+		passages = append(passages, p)
+		chapter, inVerses = p.EndChapter, p.Verse > 0
+	}
+
+	if len(passages) == 0 {
+		return nil, fmt.Errorf("%w: %s has no chapter", ErrInvalidReference, book.Name)
+	}
+	return passages, nil
+}
+
+// parsePoint reads "3:16", "3.16" or a bare number. A bare number is a verse
+// of chapter when inVerses is set or the book has one chapter, else a chapter.
+func parsePoint(book *Book, s string, chapter int, inVerses bool) (int, int, error) {
+	s = strings.TrimSpace(s)
+	sep := strings.IndexAny(s, ":.")
+	if sep < 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%w: %q", ErrInvalidReference, s)
+		}
+		switch {
+		case inVerses:
+			return chapter, n, nil
+		case book.Chapters() == 1:
+			return 1, n, nil
+		default:
+			return n, 0, nil
+		}
+	}
+
+	c, err := strconv.Atoi(strings.TrimSpace(s[:sep]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidReference, s)
+	}
+	v, err := strconv.Atoi(strings.TrimSpace(s[sep+1:]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidReference, s)
+	}
+	return c, v, nil
+}
+
+// validate checks the passage against the book's chapter and verse counts
+func (p Passage) validate() error {
+	for _, point := range [][2]int{{p.Chapter, p.Verse}, {p.EndChapter, p.EndVerse}} {
+		chapter, verse := point[0], point[1]
+		if chapter < 1 || chapter > p.Book.Chapters() {
+			return fmt.Errorf("%w: %s has %d chapters", ErrChapterOutOfRange, p.Book.Name, p.Book.Chapters())
+		}
+		if verse < 0 || verse > p.Book.VerseCount(chapter) || (verse == 0 && p.Verse != 0) {
+			return fmt.Errorf("%w: %s %d has %d verses", ErrVerseOutOfRange, p.Book.Name, chapter, p.Book.VerseCount(chapter))
+		}
+	}
+	if p.EndChapter < p.Chapter || (p.EndChapter == p.Chapter && p.EndVerse < p.Verse) {
+		return fmt.Errorf("%w: %s %d:%d ends before it starts", ErrInvalidReference, p.Book.Name, p.Chapter, p.Verse)
+	}
+	return nil
+}
+
+// OSIS returns the passage as an OSIS reference: "John.3.16",
+// "John.3.16-John.3.18" or "Ps.23"
+func (p Passage) OSIS() string {
+	start := osisPoint(p.Book, p.Chapter, p.Verse)
+	if p.EndChapter == p.Chapter && p.EndVerse == p.Verse {
+		return start
+	}
+	return start + "-" + osisPoint(p.Book, p.EndChapter, p.EndVerse)
+}
+
+func osisPoint(book *Book, chapter, verse int) string {
+	if verse == 0 {
+		return fmt.Sprintf("%s.%d", book.ID, chapter)
+	}
+	return fmt.Sprintf("%s.%d.%d", book.ID, chapter, verse)
+}
+
+// Format returns the passage for display with the book named in lang:
+// "John 3:16", "John 3:16-18", "John 3:16-4:2" or "Psalms 23"
+func (p Passage) Format(lang Language) string {
+	return p.Book.NameIn(lang) + " " + p.numbers()
+}
+
+// numbers returns the passage without its book name
+func (p Passage) numbers() string {
+	if p.Verse == 0 {
+		if p.EndChapter == p.Chapter {
+			return strconv.Itoa(p.Chapter)
+		}
+		return fmt.Sprintf("%d-%d", p.Chapter, p.EndChapter)
+	}
+	s := fmt.Sprintf("%d:%d", p.Chapter, p.Verse)
+	switch {
+	case p.EndChapter != p.Chapter:
+		s += fmt.Sprintf("-%d:%d", p.EndChapter, p.EndVerse)
+	case p.EndVerse != p.Verse:
+		s += fmt.Sprintf("-%d", p.EndVerse)
+	}
+	return s
+}
+
+// VerseIDs returns the OSIS ID of every verse the passage covers, in order
+func (p Passage) VerseIDs() []string {
+	var ids []string
+	for chapter := p.Chapter; chapter <= p.EndChapter; chapter++ {
+		first, last := 1, p.Book.VerseCount(chapter)
+		if chapter == p.Chapter && p.Verse > 0 {
+			first = p.Verse
+		}
+		if chapter == p.EndChapter && p.EndVerse > 0 {
+			last = p.EndVerse
+		}
+		for verse := first; verse <= last; verse++ {
+			ids = append(ids, VerseID(p.Book, chapter, verse))
+		}
+	}
+	return ids
+}
+
+// VerseID returns the OSIS ID of a single verse: "John.3.16"
+func VerseID(book *Book, chapter, verse int) string {
+	return osisPoint(book, chapter, verse)
+}
+
+// OSIS returns the reference as comma-separated OSIS references
+func (r Reference) OSIS() string {
+	ids := make([]string, len(r.Passages))
+	for i, p := range r.Passages {
+		ids[i] = p.OSIS()
+	}
+	return strings.Join(ids, ",")
+}
+
+// String returns the reference in its canonical display form, with book
+// names in the language it was written in
+func (r Reference) String() string {
+	return r.Format(r.Lang)
+}
+
+// Format returns the reference for display with book names in lang. Verses
+// in the same chapter are listed after a comma and other books after a
+// semicolon: "John 3:16, 18; Romans 5:8".
+func (r Reference) Format(lang Language) string {
+	var sb strings.Builder
+	for i, p := range r.Passages {
+		if i == 0 {
+			sb.WriteString(p.Format(lang))
+			continue
+		}
+		prev := r.Passages[i-1]
+		switch {
+		case p.Book != prev.Book:
+			sb.WriteString("; " + p.Format(lang))
+		case p.Verse > 0 && prev.Verse > 0 && p.Chapter == prev.EndChapter && p.EndChapter == p.Chapter:
+			sb.WriteString(", " + strings.SplitN(p.numbers(), ":", 2)[1])
+		default:
+			sb.WriteString(", " + p.numbers())
+		}
+	}
+	return sb.String()
+}
+
+// IsZero reports whether the reference has no passages
+func (r Reference) IsZero() bool {
+	return len(r.Passages) == 0
+}
+
+// Random returns a random single-verse reference anywhere in the canon
+func Random(lang Language) Reference {
+	book := Canon[rand.Intn(len(Canon))]
+	chapter := rand.Intn(book.Chapters()) + 1
+	verse := rand.Intn(book.VerseCount(chapter)) + 1
+	return Reference{
+		Passages: []Passage{{Book: book, Chapter: chapter, Verse: verse, EndChapter: chapter, EndVerse: verse}},
+		Lang:     lang,
+	}
+}
@@ -0,0 +1,82 @@
+package reference
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in     string
+		osis   string
+		format string
+		lang   Language
+	}{
+		{"John 3:16", "John.3.16", "John 3:16", English},
+		{"jn 3:16-18", "John.3.16-John.3.18", "John 3:16-18", English},
+		{"John 3.16", "John.3.16", "John 3:16", English},
+		{"1 Cor 13:4-7, 13", "1Cor.13.4-1Cor.13.7,1Cor.13.13", "1 Corinthians 13:4-7, 13", English},
+		{"1 Corinthians 13:4–7", "1Cor.13.4-1Cor.13.7", "1 Corinthians 13:4-7", English},
+		{"Psalm 23", "Ps.23", "Psalms 23", English},
+		{"Ps 23-24", "Ps.23-Ps.24", "Psalms 23-24", English},
+		{"John 3:16-4:2", "John.3.16-John.4.2", "John 3:16-4:2", English},
+		{"John 3-4:2", "John.3.1-John.4.2", "John 3:1-4:2", English},
+		{"John 3:16; Rom 5:8", "John.3.16,Rom.5.8", "John 3:16; Romans 5:8", English},
+		{"John 3:16; 4:2", "John.3.16,John.4.2", "John 3:16, 4:2", English},
+		{"Jude 3", "Jude.1.3", "Jude 1:3", English},
+		{"Yohana 3:16; Warumi 5:8", "John.3.16,Rom.5.8", "Yohana 3:16; Warumi 5:8", Swahili},
+	}
+	for _, tt := range tests {
+		ref, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got := ref.OSIS(); got != tt.osis {
+			t.Errorf("Parse(%q).OSIS() = %q, want %q", tt.in, got, tt.osis)
+		}
+		if got := ref.String(); got != tt.format {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.in, got, tt.format)
+		}
+		if ref.Lang != tt.lang {
+			t.Errorf("Parse(%q).Lang = %q, want %q", tt.in, ref.Lang, tt.lang)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want error
+	}{
+		{"", ErrInvalidReference},
+		{"John", ErrInvalidReference},
+		{"3:16", ErrInvalidReference},
+		{"Hezekiah 1:1", ErrUnknownBook},
+		{"John 22:1", ErrChapterOutOfRange},
+		{"John 3:37", ErrVerseOutOfRange},
+		{"John 3:18-16", ErrInvalidReference},
+		{"John 3:1-2-3", ErrInvalidReference},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(tt.in); !errors.Is(err, tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %v", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestParseOSISRoundTrip(t *testing.T) {
+	for _, in := range []string{"John 3:16", "1 Cor 13:4-7, 13", "Psalm 23", "John 3:16-4:2"} {
+		ref, err := Parse(in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", in, err)
+		}
+		back, err := ParseOSIS(ref.OSIS())
+		if err != nil {
+			t.Fatalf("ParseOSIS(%q): %v", ref.OSIS(), err)
+		}
+		if back.OSIS() != ref.OSIS() {
+			t.Errorf("ParseOSIS(%q).OSIS() = %q", ref.OSIS(), back.OSIS())
+		}
+	}
+}
@@ -0,0 +1,364 @@
+// services/achievements.go - Declarative achievement rules and unlocks
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	"ubible/database"
+	"ubible/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Achievement events - what just happened to the user
+const (
+	AchievementEventAttempt     = "attempt"     // Finished a single-player quiz
+	AchievementEventMultiplayer = "multiplayer" // Finished a multiplayer game
+	AchievementEventSocial      = "social"      // Made a friend, joined a team...
+)
+
+// achievementMetrics lists every metric a rule may test. Event metrics describe
+// the game that was just played (0 for social events); the rest are lifetime totals.
+var achievementMetrics = map[string]bool{
+	// Event
+	"score": true, "correct_answers": true, "total_questions": true, "accuracy": true,
+	"perfect": true, "won": true, "placement": true, "players": true, "time_elapsed": true,
+	// Lifetime
+	"total_games": true, "wins": true, "perfect_games": true, "current_streak": true,
+	"best_streak": true, "level": true, "rating": true, "friends": true, "teams": true,
+	"themes_completed": true, "theme_perfect": true,
+}
+
+var ErrInvalidCriteria = errors.New("invalid achievement criteria")
+
+// AchievementCriteria is the JSON rule stored in Achievement.Criteria. A leaf
+// compares one metric with a value; All/Any combine nested rules.
+//
+//	{"metric": "perfect_games", "value": 5}
+//	{"event": "multiplayer", "all": [{"metric": "won", "value": 1}, {"metric": "players", "value": 4}]}
+//	{"metric": "theme_perfect", "theme_id": 3, "value": 1}
+type AchievementCriteria struct {
+	Event   string                `json:"event,omitempty"` // Only match on this event type
+	Metric  string                `json:"metric,omitempty"`
+	Op      string                `json:"op,omitempty"` // >= (default), >, ==, !=, <=, <
+	Value   float64               `json:"value,omitempty"`
+	ThemeID uint                  `json:"theme_id,omitempty"` // For theme_perfect
+	All     []AchievementCriteria `json:"all,omitempty"`
+	Any     []AchievementCriteria `json:"any,omitempty"`
+}
+
+// AchievementEvent describes the outcome that triggered an evaluation
+type AchievementEvent struct {
+	Type           string
+	Score          int
+	CorrectAnswers int
+	TotalQuestions int
+	IsPerfect      bool
+	Won            bool
+	Placement      int
+	Players        int
+	TimeElapsed    int // Seconds
+	ThemeID        uint
+	Reference      string // Game or attempt ID, for logs
+}
+
+// AchievementService evaluates achievement rules and grants unlocks
+type AchievementService struct{}
+
+// NewAchievementService creates a new achievement service
+func NewAchievementService() *AchievementService {
+	return &AchievementService{}
+}
+
+// ParseCriteria decodes and validates a rule. An empty string is a valid "no rule".
+func (s *AchievementService) ParseCriteria(raw string) (*AchievementCriteria, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var c AchievementCriteria
+	if err := json.Unmarshal([]byte(raw), &c); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCriteria, err)
+	}
+	if err := validateCriteria(c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func validateCriteria(c AchievementCriteria) error {
+	switch c.Event {
+	case "", AchievementEventAttempt, AchievementEventMultiplayer, AchievementEventSocial:
+	default:
+		return fmt.Errorf("%w: unknown event %q", ErrInvalidCriteria, c.Event)
+	}
+
+	if c.Metric == "" && len(c.All) == 0 && len(c.Any) == 0 {
+		return fmt.Errorf("%w: rule needs a metric, all or any", ErrInvalidCriteria)
+	}
+	if c.Metric != "" {
+		if !achievementMetrics[c.Metric] {
+			return fmt.Errorf("%w: unknown metric %q", ErrInvalidCriteria, c.Metric)
+		}
+		if c.Metric == "theme_perfect" && c.ThemeID == 0 {
+			return fmt.Errorf("%w: theme_perfect needs theme_id", ErrInvalidCriteria)
+		}
+		if _, ok := compare(0, c.Op, 0); !ok {
+			return fmt.Errorf("%w: unknown op %q", ErrInvalidCriteria, c.Op)
+		}
+	}
+
+	for _, sub := range append(append([]AchievementCriteria{}, c.All...), c.Any...) {
+		if err := validateCriteria(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Evaluate checks every rule-based achievement the user hasn't unlocked yet
+// against the event and grants the ones that now match. Unlocking is
+// idempotent: a concurrent evaluation can't grant the same achievement twice.
+func (s *AchievementService) Evaluate(userID uint, event AchievementEvent) ([]models.Achievement, error) {
+	db := database.GetDB()
+	if db == nil {
+		return nil, nil
+	}
+
+	var achievements []models.Achievement
+	if err := db.Where("criteria IS NOT NULL AND criteria <> ''").
+		Where("id NOT IN (?)", db.Model(&models.UserAchievement{}).Select("achievement_id").Where("user_id = ?", userID)).
+		Find(&achievements).Error; err != nil {
+		return nil, fmt.Errorf("failed to load achievements: %w", err)
+	}
+	if len(achievements) == 0 {
+		return nil, nil
+	}
+
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	if user.IsGuest {
+		return nil, nil
+	}
+
+	m := &metricSource{db: db, user: &user, event: event, cache: make(map[string]float64)}
+
+	var unlocked []models.Achievement
+	for _, a := range achievements {
+		criteria, err := s.ParseCriteria(a.Criteria)
+		if err != nil {
+			log.Printf("⚠️  Achievement %d (%s) has bad criteria: %v", a.ID, a.Name, err)
+			continue
+		}
+		if criteria == nil || !m.matches(*criteria) {
+			continue
+		}
+
+		granted, err := s.grant(userID, a)
+		if err != nil {
+			log.Printf("⚠️  Failed to grant achievement %d to user %d: %v", a.ID, userID, err)
+			continue
+		}
+		if granted {
+			log.Printf("🏆 User %d unlocked achievement %q (%s)", userID, a.Name, event.Type)
+			unlocked = append(unlocked, a)
+		}
+	}
+
+	return unlocked, nil
+}
+
+// grant records the unlock and pays its rewards in one transaction. Returns
+// false if the user already had it.
+func (s *AchievementService) grant(userID uint, a models.Achievement) (bool, error) {
+	db := database.GetDB()
+
+	granted := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		ua := models.UserAchievement{UserID: userID, AchievementID: a.ID, UnlockedAt: time.Now()}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&ua)
+		if result.Error != nil {
+			return fmt.Errorf("failed to record unlock: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		reference := "achievement:" + strconv.FormatUint(uint64(a.ID), 10)
+		if a.XPReward > 0 {
+			if _, err := Progression.AddXP(tx, userID, a.XPReward, reference); err != nil {
+				return fmt.Errorf("failed to grant XP: %w", err)
+			}
+		}
+
+		if a.FPReward > 0 {
+			if _, err := FaithPoints.Adjust(tx, userID, a.FPReward, models.FPReasonAchievement, reference); err != nil {
+				return err
+			}
+		}
+
+		if a.PowerUpReward != "" {
+			column, ok := powerUpColumns[PowerUps.NormalizeType(a.PowerUpReward)]
+			if !ok {
+				return fmt.Errorf("unknown power-up reward %q", a.PowerUpReward)
+			}
+			quantity := a.PowerUpQuantity
+			if quantity <= 0 {
+				quantity = 1
+			}
+			if err := tx.Model(&models.User{}).Where("id = ?", userID).
+				Update(column, gorm.Expr(column+" + ?", quantity)).Error; err != nil {
+				return fmt.Errorf("failed to grant power-up: %w", err)
+			}
+		}
+
+		granted = true
+		return nil
+	})
+
+	return granted, err
+}
+
+// metricSource resolves metrics for one evaluation, querying lifetime
+// aggregates at most once each
+type metricSource struct {
+	db    *gorm.DB
+	user  *models.User
+	event AchievementEvent
+	cache map[string]float64
+}
+
+func (m *metricSource) matches(c AchievementCriteria) bool {
+	if c.Event != "" && c.Event != m.event.Type {
+		return false
+	}
+
+	if c.Metric != "" {
+		ok, _ := compare(m.value(c), c.Op, c.Value)
+		if !ok {
+			return false
+		}
+	}
+
+	for _, sub := range c.All {
+		if !m.matches(sub) {
+			return false
+		}
+	}
+
+	if len(c.Any) > 0 {
+		for _, sub := range c.Any {
+			if m.matches(sub) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+func (m *metricSource) value(c AchievementCriteria) float64 {
+	e, u := m.event, m.user
+
+	switch c.Metric {
+	case "score":
+		return float64(e.Score)
+	case "correct_answers":
+		return float64(e.CorrectAnswers)
+	case "total_questions":
+		return float64(e.TotalQuestions)
+	case "accuracy":
+		if e.TotalQuestions == 0 {
+			return 0
+		}
+		return float64(e.CorrectAnswers) * 100 / float64(e.TotalQuestions)
+	case "perfect":
+		return boolMetric(e.IsPerfect)
+	case "won":
+		return boolMetric(e.Won)
+	case "placement":
+		return float64(e.Placement)
+	case "players":
+		return float64(e.Players)
+	case "time_elapsed":
+		return float64(e.TimeElapsed)
+	case "total_games":
+		return float64(u.TotalGames)
+	case "wins":
+		return float64(u.Wins)
+	case "perfect_games":
+		return float64(u.PerfectGames)
+	case "current_streak":
+		return float64(u.CurrentStreak)
+	case "best_streak":
+		return float64(u.BestStreak)
+	case "level":
+		return float64(u.Level)
+	case "rating":
+		return u.Rating
+	}
+
+	key := c.Metric
+	if c.Metric == "theme_perfect" {
+		key += ":" + strconv.FormatUint(uint64(c.ThemeID), 10)
+	}
+	if v, ok := m.cache[key]; ok {
+		return v
+	}
+
+	var count int64
+	switch c.Metric {
+	case "friends":
+		m.db.Model(&models.Friend{}).Where("user_id = ?", u.ID).Count(&count)
+	case "teams":
+		m.db.Model(&models.TeamMember{}).Where("user_id = ?", u.ID).Count(&count)
+	case "themes_completed":
+		m.db.Model(&models.Attempt{}).
+			Where("user_id = ? AND theme_id > 0 AND is_perfect = ?", u.ID, true).
+			Distinct("theme_id").Count(&count)
+	case "theme_perfect":
+		m.db.Model(&models.Attempt{}).
+			Where("user_id = ? AND theme_id = ? AND is_perfect = ?", u.ID, c.ThemeID, true).
+			Count(&count)
+	}
+
+	m.cache[key] = float64(count)
+	return float64(count)
+}
+
+// compare applies op; the second result is false for an unknown op
+func compare(actual float64, op string, want float64) (bool, bool) {
+	switch op {
+	case "", ">=":
+		return actual >= want, true
+	case ">":
+		return actual > want, true
+	case "==":
+		return actual == want, true
+	case "!=":
+		return actual != want, true
+	case "<=":
+		return actual <= want, true
+	case "<":
+		return actual < want, true
+	}
+	return false, false
+}
+
+func boolMetric(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Global instance
+var Achievements = NewAchievementService()
@@ -0,0 +1,476 @@
+// services/bible.go - Multi-translation Bible text store and translated questions
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"ubible/database"
+	"ubible/models"
+	"ubible/reference"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultTranslation is the translation used when none is asked for
+const DefaultTranslation = "KJV"
+
+var (
+	ErrUnknownTranslation = errors.New("unknown translation")
+	ErrPassageMissing     = errors.New("passage not in this translation")
+)
+
+// bundledTranslation is a translation shipped in ./verses. Files are read in
+// order; later files only fill verses the earlier ones lack.
+type bundledTranslation struct {
+	Translation models.BibleTranslation
+	Files       []string
+}
+
+var bundledTranslations = []bundledTranslation{
+	{
+		Translation: models.BibleTranslation{
+			Code:        "KJV",
+			Name:        "King James Version",
+			Language:    string(reference.English),
+			Description: "Authorized Version, 1769",
+		},
+		Files: []string{"kjv-full.json"},
+	},
+	{
+		Translation: models.BibleTranslation{
+			Code:        "SWNT",
+			Name:        "Swahili New Testament",
+			Language:    string(reference.Swahili),
+			Description: "Swahili New Testament (public domain)",
+		},
+		Files: []string{"txt files/swahili-bible.json", "txt files/swahili-full.json"},
+	},
+}
+
+// TranslationSummary is a translation with how many verses the store holds
+type TranslationSummary struct {
+	models.BibleTranslation
+	Verses int `json:"verses"`
+}
+
+// BibleText is one translation loaded into memory
+type BibleText struct {
+	Translation models.BibleTranslation
+	verses      []models.BibleVerse // Canonical order
+	byID        map[string]string   // OSIS verse ID -> text
+	index       *verseIndex
+}
+
+// Lang returns the language book names are written in for this translation
+func (t *BibleText) Lang() reference.Language {
+	if t.Translation.Language == string(reference.Swahili) {
+		return reference.Swahili
+	}
+	return reference.English
+}
+
+// Verses returns every verse of the translation in canonical order
+func (t *BibleText) Verses() []models.BibleVerse {
+	return t.verses
+}
+
+// PassageText returns the text of every verse a reference covers, joined
+// with spaces. It fails if the translation lacks any of them.
+func (t *BibleText) PassageText(ref reference.Reference) (string, error) {
+	var parts []string
+	for _, p := range ref.Passages {
+		for _, id := range p.VerseIDs() {
+			text, ok := t.byID[id]
+			if !ok {
+				return "", fmt.Errorf("%w: %s in %s", ErrPassageMissing, id, t.Translation.Code)
+			}
+			parts = append(parts, text)
+		}
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrPassageMissing, ref.OSIS())
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// Passage returns an OSIS reference formatted in the translation's language
+// along with its text
+func (t *BibleText) Passage(osis string) (string, string, error) {
+	ref, err := reference.ParseOSIS(osis)
+	if err != nil {
+		return "", "", err
+	}
+	text, err := t.PassageText(ref)
+	if err != nil {
+		return "", "", err
+	}
+	return ref.Format(t.Lang()), text, nil
+}
+
+// BibleService holds every translation in the Bible text store
+type BibleService struct {
+	mu     sync.RWMutex
+	texts  map[string]*BibleText
+	byText map[string]string // Normalised verse text -> OSIS verse ID, across translations
+}
+
+// NewBibleService creates a new Bible service
+func NewBibleService() *BibleService {
+	return &BibleService{
+		texts:  make(map[string]*BibleText),
+		byText: make(map[string]string),
+	}
+}
+
+// Init seeds the store with the bundled translations it doesn't have yet and
+// loads every stored translation into memory
+func (s *BibleService) Init() {
+	db := database.GetDB()
+	if db == nil {
+		log.Println("⚠️  Bible store not loaded: database not initialized")
+		return
+	}
+	for _, b := range bundledTranslations {
+		if err := seedTranslation(db, b); err != nil {
+			log.Printf("⚠️  Failed to seed %s: %v", b.Translation.Code, err)
+		}
+	}
+	if err := s.Load(db); err != nil {
+		log.Printf("⚠️  Failed to load Bible store: %v", err)
+	}
+}
+
+// seedTranslation imports a bundled translation unless the store already has
+// its verses. Verses kept from the old importer get the bundled name and
+// description in place of the placeholder row the migration gave them.
+func seedTranslation(db *gorm.DB, b bundledTranslation) error {
+	var count int64
+	if err := db.Model(&models.BibleVerse{}).Where("translation = ?", b.Translation.Code).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		t := b.Translation
+		return db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "code"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "language", "description", "updated_at"}),
+			Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "bible_translations.name = bible_translations.code"}}},
+		}).Create(&t).Error
+	}
+
+	seen := make(map[string]bool)
+	var verses []models.BibleVerse
+	var sources []string
+	for _, name := range b.Files {
+		path := filepath.Join(VersesDirectory, name)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		fileVerses, err := ReadBibleFile(path)
+		if err != nil {
+			log.Printf("⚠️  Skipping %s: %v", path, err)
+			continue
+		}
+		for _, v := range fileVerses {
+			id := fmt.Sprintf("%s.%d.%d", v.Book, v.Chapter, v.Verse)
+			if !seen[id] {
+				seen[id] = true
+				verses = append(verses, v)
+			}
+		}
+		sources = append(sources, name)
+	}
+	if len(verses) == 0 {
+		return nil
+	}
+
+	t := b.Translation
+	t.Source = strings.Join(sources, ", ")
+	n, err := ImportTranslation(db, t, verses)
+	if err != nil {
+		return err
+	}
+	log.Printf("📖 Seeded %s with %d verses from %s", t.Code, n, t.Source)
+	return nil
+}
+
+// ImportTranslation upserts a translation and its verses into the store.
+// Importing the same text again updates it in place.
+func ImportTranslation(db *gorm.DB, t models.BibleTranslation, verses []models.BibleVerse) (int, error) {
+	t.Code = strings.ToUpper(strings.TrimSpace(t.Code))
+	if t.Code == "" {
+		return 0, errors.New("translation code is required")
+	}
+	if t.Language == "" {
+		t.Language = string(reference.English)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "code"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "language", "description", "source", "updated_at"}),
+		}).Create(&t).Error; err != nil {
+			return err
+		}
+
+		for i := range verses {
+			verses[i].ID = 0
+			verses[i].Translation = t.Code
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "translation"}, {Name: "book"}, {Name: "chapter"}, {Name: "verse"}},
+			DoUpdates: clause.AssignmentColumns([]string{"text"}),
+		}).CreateInBatches(verses, 1000).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to import %s: %w", t.Code, err)
+	}
+	return len(verses), nil
+}
+
+// Load reads every translation in the store into memory
+func (s *BibleService) Load(db *gorm.DB) error {
+	var translations []models.BibleTranslation
+	if err := db.Find(&translations).Error; err != nil {
+		return err
+	}
+
+	texts := make(map[string]*BibleText, len(translations))
+	byText := make(map[string]string)
+	for _, t := range translations {
+		var verses []models.BibleVerse
+		if err := db.Where("translation = ?", t.Code).Find(&verses).Error; err != nil {
+			return fmt.Errorf("failed to load %s: %w", t.Code, err)
+		}
+
+		text := &BibleText{Translation: t, byID: make(map[string]string, len(verses))}
+		for _, v := range verses {
+			book := reference.ByID(v.Book)
+			if book == nil {
+				continue
+			}
+			id := reference.VerseID(book, v.Chapter, v.Verse)
+			text.byID[id] = v.Text
+			text.verses = append(text.verses, v)
+			if key := normalizeVerseText(v.Text); key != "" {
+				if _, ok := byText[key]; !ok {
+					byText[key] = id
+				}
+			}
+		}
+		sort.Slice(text.verses, func(i, j int) bool {
+			a, b := text.verses[i], text.verses[j]
+			if a.Book != b.Book {
+				return reference.ByID(a.Book).Number() < reference.ByID(b.Book).Number()
+			}
+			if a.Chapter != b.Chapter {
+				return a.Chapter < b.Chapter
+			}
+			return a.Verse < b.Verse
+		})
+		text.index = newVerseIndex(text.verses, text.Lang())
+		texts[t.Code] = text
+		log.Printf("📖 Loaded %s (%s): %d verses", t.Code, t.Name, len(text.verses))
+	}
+
+	s.mu.Lock()
+	s.texts = texts
+	s.byText = byText
+	s.mu.Unlock()
+	return nil
+}
+
+// Translations lists the translations in the store
+func (s *BibleService) Translations() []TranslationSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]TranslationSummary, 0, len(s.texts))
+	for _, t := range s.texts {
+		list = append(list, TranslationSummary{BibleTranslation: t.Translation, Verses: len(t.verses)})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// Text returns a translation by its code, ignoring case
+func (s *BibleService) Text(code string) (*BibleText, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	text, ok := s.texts[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTranslation, code)
+	}
+	return text, nil
+}
+
+// Has reports whether the store holds a translation
+func (s *BibleService) Has(code string) bool {
+	_, err := s.Text(code)
+	return err == nil
+}
+
+// verseID identifies a verse text in any stored translation
+func (s *BibleService) verseID(text string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, ok := s.byText[normalizeVerseText(text)]
+	return id, ok
+}
+
+// TranslateQuestions returns the questions with their verse text, references
+// and options in another translation. Verse→reference and reference→verse
+// questions with an OSIS ID are translated; a question the translation can't
+// cover (another book, a completion question) is returned as it was. An
+// empty code returns the questions unchanged.
+func (s *BibleService) TranslateQuestions(questions []models.Question, code string) ([]models.Question, error) {
+	if strings.TrimSpace(code) == "" {
+		return questions, nil
+	}
+	text, err := s.Text(code)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wrong answers are usually other verses of the same theme, so the
+	// questions themselves say which verse each text is
+	known := make(map[string]string)
+	var pool []string
+	inPool := make(map[string]bool)
+	for _, q := range questions {
+		if q.OSIS == "" {
+			continue
+		}
+		switch {
+		case q.CorrectAnswer == q.Reference:
+			known[normalizeVerseText(q.Text)] = q.OSIS
+		case q.Text == q.Reference:
+			known[normalizeVerseText(q.CorrectAnswer)] = q.OSIS
+		}
+		if !inPool[q.OSIS] {
+			inPool[q.OSIS] = true
+			pool = append(pool, q.OSIS)
+		}
+	}
+
+	out := make([]models.Question, len(questions))
+	translated := 0
+	for i, q := range questions {
+		out[i] = q
+		if tq, ok := s.translateQuestion(text, q, known, pool); ok {
+			out[i] = tq
+			translated++
+		}
+	}
+	if translated < len(questions) {
+		log.Printf("📖 %d of %d questions kept their original text: not covered by %s", len(questions)-translated, len(questions), text.Translation.Code)
+	}
+	return out, nil
+}
+
+// translateQuestion translates one question, reporting false if it can't
+func (s *BibleService) translateQuestion(text *BibleText, q models.Question, known map[string]string, pool []string) (models.Question, bool) {
+	if q.OSIS == "" {
+		return q, false
+	}
+	ref, err := reference.ParseOSIS(q.OSIS)
+	if err != nil {
+		return q, false
+	}
+	verseText, err := text.PassageText(ref)
+	if err != nil {
+		return q, false
+	}
+
+	var wrong []string
+	if q.WrongAnswers != "" {
+		if err := json.Unmarshal([]byte(q.WrongAnswers), &wrong); err != nil {
+			return q, false
+		}
+	}
+
+	lang := text.Lang()
+	translatedRef := ref.Format(lang)
+	var translatedWrong []string
+	used := map[string]bool{q.OSIS: true}
+
+	switch {
+	case q.CorrectAnswer == q.Reference:
+		// Verse → reference: the options are references
+		for _, w := range wrong {
+			if r, err := reference.Parse(w); err == nil {
+				translatedWrong = append(translatedWrong, r.Format(lang))
+			} else {
+				translatedWrong = append(translatedWrong, reference.Random(lang).Format(lang))
+			}
+		}
+		q.Text = verseText
+		q.CorrectAnswer = translatedRef
+
+	case q.Text == q.Reference:
+		// Reference → verse: the options are verse texts
+		for _, w := range wrong {
+			id, ok := known[normalizeVerseText(w)]
+			if !ok {
+				id, ok = s.verseID(w)
+			}
+			if ok && !used[id] {
+				if t, ok := osisText(text, id); ok {
+					used[id] = true
+					translatedWrong = append(translatedWrong, t)
+					continue
+				}
+			}
+			// Not a verse we know: use another verse of the theme instead
+			for _, id := range pool {
+				if used[id] {
+					continue
+				}
+				used[id] = true
+				if t, ok := osisText(text, id); ok {
+					translatedWrong = append(translatedWrong, t)
+					break
+				}
+			}
+		}
+		if len(translatedWrong) < min(3, len(wrong)) {
+			return q, false
+		}
+		q.Text = translatedRef
+		q.CorrectAnswer = verseText
+
+	default:
+		return q, false
+	}
+
+	wrongJSON, _ := json.Marshal(dedup(translatedWrong))
+	q.WrongAnswers = string(wrongJSON)
+	q.Reference = translatedRef
+	return q, true
+}
+
+// osisText returns the text of an OSIS reference in a translation
+func osisText(text *BibleText, id string) (string, bool) {
+	_, t, err := text.Passage(id)
+	return t, err == nil
+}
+
+// normalizeVerseText reduces verse text to lower-case words so the same verse
+// matches whatever its punctuation and spacing
+func normalizeVerseText(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// Global instance
+var Bible = NewBibleService()
@@ -0,0 +1,689 @@
+// services/bible_formats.go - Reading Bible text files into store verses
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"ubible/models"
+	"ubible/reference"
+)
+
+var ErrUnknownBibleFormat = errors.New("unrecognised Bible file format")
+
+// markupTag matches inline markup some sources leave in verse text (<FO>, <i>...)
+var markupTag = regexp.MustCompile(`<[^>]*>`)
+
+// BibleReader reads a Bible in one file format
+type BibleReader func(io.Reader) (*BibleImport, error)
+
+// BibleFormats are the formats the importer understands, by name
+var BibleFormats = map[string]BibleReader{
+	"json":    readJSONBible,
+	"osis":    ReadOSIS,
+	"usfm":    ReadUSFM,
+	"zefania": ReadZefania,
+	"csv":     ReadCSV,
+}
+
+// BibleImport is the verses read from one or more Bible files. Verses outside
+// the canon are dropped and listed; a verse read twice keeps its first text.
+type BibleImport struct {
+	Name       string // Translation title, when the file names it
+	Verses     []models.BibleVerse
+	Dropped    []string // Why each verse left out was: an unknown book, a chapter or verse past the canon's, a bridged verse or an unreadable row
+	Duplicates int
+	seen       map[string]bool
+}
+
+func newBibleImport() *BibleImport {
+	return &BibleImport{seen: make(map[string]bool)}
+}
+
+// add keeps a verse if it's inside the canon
+func (imp *BibleImport) add(book *reference.Book, chapter, verse int, text string) {
+	text = strings.Join(strings.Fields(markupTag.ReplaceAllString(text, "")), " ")
+	if book == nil || text == "" {
+		return
+	}
+	if verse < 1 || verse > book.VerseCount(chapter) {
+		imp.Dropped = append(imp.Dropped, fmt.Sprintf("%s %d:%d is outside the canon", book.Name, chapter, verse))
+		return
+	}
+	id := reference.VerseID(book, chapter, verse)
+	if imp.seen[id] {
+		imp.Duplicates++
+		return
+	}
+	imp.seen[id] = true
+	imp.Verses = append(imp.Verses, models.BibleVerse{Book: book.ID, Chapter: chapter, Verse: verse, Text: text})
+}
+
+// dropBook records a verse of a book the canon doesn't have
+func (imp *BibleImport) dropBook(name string) {
+	imp.Dropped = append(imp.Dropped, fmt.Sprintf("unknown book %q", name))
+}
+
+// merge adds another import's verses, keeping this one's where both have a verse
+func (imp *BibleImport) merge(other *BibleImport) {
+	if imp.Name == "" {
+		imp.Name = other.Name
+	}
+	for _, v := range other.Verses {
+		imp.add(reference.ByID(v.Book), v.Chapter, v.Verse, v.Text)
+	}
+	imp.Dropped = append(imp.Dropped, other.Dropped...)
+	imp.Duplicates += other.Duplicates
+}
+
+// ReadBibleFiles reads Bible files in a format. Directories are expanded to
+// the files directly inside them, in name order, so a USFM Bible split into
+// one file per book can be read in one go.
+func ReadBibleFiles(format string, paths []string) (*BibleImport, error) {
+	read, ok := BibleFormats[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownBibleFormat, format)
+	}
+
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+	}
+	sort.Strings(files)
+
+	imp := newBibleImport()
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		fileImp, err := read(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		imp.merge(fileImp)
+	}
+	if len(imp.Verses) == 0 {
+		return nil, fmt.Errorf("%w: no verses in %s", ErrUnknownBibleFormat, strings.Join(files, ", "))
+	}
+	return imp, nil
+}
+
+// bookListFile is the [{abbrev, name, chapters: [[verse, ...], ...]}] shape of kjv-full.json
+type bookListFile []struct {
+	Abbrev   string     `json:"abbrev"`
+	Name     string     `json:"name"`
+	Chapters [][]string `json:"chapters"`
+}
+
+// nestedBooksFile is the {books: [{nr, name, chapters: [{chapter, verses}]}]} shape of swahili-bible.json
+type nestedBooksFile struct {
+	Books []struct {
+		Nr       int    `json:"nr"`
+		Name     string `json:"name"`
+		Chapters []struct {
+			Chapter int `json:"chapter"`
+			Verses  []struct {
+				Verse int    `json:"verse"`
+				Text  string `json:"text"`
+			} `json:"verses"`
+		} `json:"chapters"`
+	} `json:"books"`
+}
+
+// flatVersesFile is the {verses: [{book, book_name, chapter, verse, text}]} shape of swahili-full.json
+type flatVersesFile struct {
+	Verses []struct {
+		BookName string `json:"book_name"`
+		Book     int    `json:"book"`
+		Chapter  int    `json:"chapter"`
+		Verse    int    `json:"verse"`
+		Text     string `json:"text"`
+	} `json:"verses"`
+}
+
+// ReadBibleFile reads a JSON Bible in any of the shapes bundled in ./verses.
+// Books are identified by canonical number, by name, or by position in a
+// 66-book list. Verses outside the canon's bounds are dropped.
+func ReadBibleFile(path string) ([]models.BibleVerse, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	imp, err := readJSONBible(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return imp.Verses, nil
+}
+
+func readJSONBible(r io.Reader) (*BibleImport, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := newBibleImport()
+	trimmed := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(trimmed, "["):
+		var books bookListFile
+		if err := json.Unmarshal(data, &books); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		for i, b := range books {
+			book := lookupFileBook(b.Name, 0)
+			if book == nil && len(books) == len(reference.Canon) {
+				book = reference.Canon[i]
+			}
+			if book == nil {
+				imp.dropBook(b.Name)
+				continue
+			}
+			for c, chapter := range b.Chapters {
+				for v, text := range chapter {
+					imp.add(book, c+1, v+1, text)
+				}
+			}
+		}
+
+	case strings.Contains(trimmed, `"books"`):
+		var file nestedBooksFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		for _, b := range file.Books {
+			book := lookupFileBook(b.Name, b.Nr)
+			if book == nil {
+				imp.dropBook(b.Name)
+				continue
+			}
+			for _, chapter := range b.Chapters {
+				for _, v := range chapter.Verses {
+					imp.add(book, chapter.Chapter, v.Verse, v.Text)
+				}
+			}
+		}
+
+	case strings.Contains(trimmed, `"verses"`):
+		var file flatVersesFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		for _, v := range file.Verses {
+			book := lookupFileBook(v.BookName, v.Book)
+			if book == nil {
+				imp.dropBook(v.BookName)
+				continue
+			}
+			imp.add(book, v.Chapter, v.Verse, v.Text)
+		}
+
+	default:
+		return nil, ErrUnknownBibleFormat
+	}
+
+	if len(imp.Verses) == 0 {
+		return nil, fmt.Errorf("%w: no verses", ErrUnknownBibleFormat)
+	}
+	return imp, nil
+}
+
+// lookupFileBook finds a book by its canonical number (1-66), then by name
+func lookupFileBook(name string, number int) *reference.Book {
+	if number >= 1 && number <= len(reference.Canon) {
+		return reference.Canon[number-1]
+	}
+	if book, _, ok := reference.LookupBook(name); ok {
+		return book
+	}
+	return nil
+}
+
+// ReadOSIS reads an OSIS XML Bible. Verses may be containers
+// (<verse osisID="Gen.1.1">...</verse>) or milestones (<verse sID=.../> ...
+// <verse eID=.../>). Notes are left out of the text.
+func ReadOSIS(r io.Reader) (*BibleImport, error) {
+	imp := newBibleImport()
+	dec := xml.NewDecoder(r)
+
+	var (
+		current    string // osisID of the verse being read
+		text       strings.Builder
+		containers []bool // Open <verse> elements: true for containers
+		inNote     int
+		inWork     bool
+		inTitle    bool
+		title      strings.Builder
+	)
+	flush := func() {
+		if current != "" {
+			imp.addOSIS(current, text.String())
+		}
+		current = ""
+		text.Reset()
+	}
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid OSIS XML: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "work":
+				inWork = true
+			case "title":
+				inTitle = inWork && imp.Name == ""
+			case "note":
+				inNote++
+			case "chapter":
+				flush()
+			case "verse":
+				switch {
+				case xmlAttr(t, "eID") != "":
+					flush()
+					containers = append(containers, false)
+				case xmlAttr(t, "sID") != "":
+					flush()
+					current = strings.Fields(xmlAttr(t, "osisID") + " " + xmlAttr(t, "sID"))[0]
+					containers = append(containers, false)
+				default:
+					flush()
+					if ids := strings.Fields(xmlAttr(t, "osisID")); len(ids) > 0 {
+						current = ids[0]
+					}
+					containers = append(containers, true)
+				}
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "work":
+				inWork = false
+			case "title":
+				if inTitle {
+					imp.Name = strings.TrimSpace(title.String())
+					inTitle = false
+				}
+			case "note":
+				inNote--
+			case "verse":
+				if n := len(containers); n > 0 {
+					if containers[n-1] {
+						flush()
+					}
+					containers = containers[:n-1]
+				}
+			}
+
+		case xml.CharData:
+			switch {
+			case inTitle:
+				title.Write(t)
+			case current != "" && inNote == 0:
+				text.Write(t)
+			}
+		}
+	}
+	flush()
+
+	if len(imp.Verses) == 0 {
+		return nil, fmt.Errorf("%w: no OSIS verses", ErrUnknownBibleFormat)
+	}
+	return imp, nil
+}
+
+// addOSIS adds a verse by its OSIS ID, "Gen.1.1"
+func (imp *BibleImport) addOSIS(id, text string) {
+	parts := strings.Split(id, ".")
+	if len(parts) != 3 {
+		return
+	}
+	book := reference.ByID(parts[0])
+	if book == nil {
+		imp.dropBook(parts[0])
+		return
+	}
+	chapter, err1 := strconv.Atoi(parts[1])
+	verse, err2 := strconv.Atoi(parts[2])
+	if err1 != nil || err2 != nil {
+		return
+	}
+	imp.add(book, chapter, verse, text)
+}
+
+func xmlAttr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// usfmBooks are the USFM book codes of the canon, in canonical order
+var usfmBooks = []string{
+	"GEN", "EXO", "LEV", "NUM", "DEU", "JOS", "JDG", "RUT", "1SA", "2SA", "1KI", "2KI",
+	"1CH", "2CH", "EZR", "NEH", "EST", "JOB", "PSA", "PRO", "ECC", "SNG", "ISA", "JER",
+	"LAM", "EZK", "DAN", "HOS", "JOL", "AMO", "OBA", "JON", "MIC", "NAM", "HAB", "ZEP",
+	"HAG", "ZEC", "MAL",
+	"MAT", "MRK", "LUK", "JHN", "ACT", "ROM", "1CO", "2CO", "GAL", "EPH", "PHP", "COL",
+	"1TH", "2TH", "1TI", "2TI", "TIT", "PHM", "HEB", "JAS", "1PE", "2PE", "1JN", "2JN",
+	"3JN", "JUD", "REV",
+}
+
+var (
+	// usfmNotes matches footnotes and cross references, left out of the text
+	usfmNotes = regexp.MustCompile(`(?s)\\(f|fe|x)\s.*?\\(f|fe|x)\*`)
+	// usfmWord matches a word with attributes: \w grace|strong="G5485"\w*
+	usfmWord = regexp.MustCompile(`\\\+?w\s+([^|\\]*)(\|[^\\]*)?\\\+?w\*`)
+	// usfmMarker matches any marker, opening or closing
+	usfmMarker = regexp.MustCompile(`\\\+?([a-z]+[0-9]*)(\*?)`)
+	// usfmNonVerse are markers whose text isn't scripture: headings, titles, running headers
+	usfmNonVerse = regexp.MustCompile(`^(id|ide|h|toc[0-9]*|toca[0-9]*|mt[0-9]*|mte[0-9]*|ms[0-9]*|mr|s[0-9]*|sr|r|d|sp|rem|sts|usfm|cl|cp|cd|imt[0-9]*|is[0-9]*|ip|ipi|im|imi|iot|io[0-9]*|ie|restore)$`)
+)
+
+// ReadUSFM reads a USFM Bible; several books may follow each other, each
+// starting with its \id line. Headings, footnotes and cross references are
+// left out of the text. A verse bridge (\v 4-5) is stored as its first verse,
+// and the verses it covers after that are listed as dropped.
+func ReadUSFM(r io.Reader) (*BibleImport, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	content := usfmNotes.ReplaceAllString(string(data), "")
+	content = usfmWord.ReplaceAllString(content, "$1")
+
+	imp := newBibleImport()
+	var (
+		book      *reference.Book
+		bookCode  string
+		chapter   int
+		verse     int
+		lastVerse int // End of a verse bridge
+		text      strings.Builder
+	)
+	flush := func() {
+		switch {
+		case verse == 0:
+		case book != nil:
+			imp.add(book, chapter, verse, text.String())
+			for v := verse + 1; v <= lastVerse; v++ {
+				imp.Dropped = append(imp.Dropped, fmt.Sprintf("%s %d:%d is bridged into %d:%d", book.Name, chapter, v, chapter, verse))
+			}
+		case bookCode != "":
+			imp.dropBook(bookCode)
+		}
+		verse, lastVerse = 0, 0
+		text.Reset()
+	}
+
+	markers := usfmMarker.FindAllStringSubmatchIndex(content, -1)
+	for i, m := range markers {
+		name := content[m[2]:m[3]]
+		closing := m[5] > m[4]
+		end := len(content)
+		if i+1 < len(markers) {
+			end = markers[i+1][0]
+		}
+		segment := content[m[1]:end]
+		fields := strings.Fields(segment)
+
+		switch {
+		case closing:
+			// End of a character style: the text after it continues the verse
+		case name == "id":
+			flush()
+			book, bookCode, chapter = nil, "", 0
+			if len(fields) > 0 {
+				bookCode = strings.ToUpper(fields[0])
+				for n, c := range usfmBooks {
+					if c == bookCode {
+						book = reference.Canon[n]
+					}
+				}
+			}
+			continue
+		case name == "c":
+			flush()
+			if len(fields) > 0 {
+				chapter, _ = strconv.Atoi(fields[0])
+			}
+			continue
+		case name == "v":
+			flush()
+			if len(fields) == 0 {
+				continue
+			}
+			num, last, _ := strings.Cut(fields[0], "-")
+			if i := strings.Index(num, ","); i > 0 {
+				num = num[:i]
+			}
+			verse, _ = strconv.Atoi(strings.TrimRight(num, "abcdefghijklmnopqrstuvwxyz"))
+			lastVerse, _ = strconv.Atoi(strings.TrimRight(last, "abcdefghijklmnopqrstuvwxyz"))
+			segment = strings.TrimSpace(segment)
+			segment = strings.TrimSpace(segment[len(fields[0]):])
+		case usfmNonVerse.MatchString(name):
+			continue
+		}
+
+		if verse > 0 {
+			text.WriteString(segment)
+		}
+	}
+	flush()
+
+	if len(imp.Verses) == 0 {
+		return nil, fmt.Errorf("%w: no USFM verses", ErrUnknownBibleFormat)
+	}
+	return imp, nil
+}
+
+// ReadZefania reads a Zefania XML Bible: XMLBIBLE > BIBLEBOOK[bnumber] >
+// CHAPTER[cnumber] > VERS[vnumber]. Notes and cross references are left out.
+func ReadZefania(r io.Reader) (*BibleImport, error) {
+	imp := newBibleImport()
+	dec := xml.NewDecoder(r)
+
+	var (
+		book     *reference.Book
+		bookName string
+		chapter  int
+		verse    int
+		text     strings.Builder
+		skip     int
+		inInfo   bool
+		inTitle  bool
+		title    strings.Builder
+	)
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid Zefania XML: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch strings.ToUpper(t.Name.Local) {
+			case "XMLBIBLE":
+				imp.Name = xmlAttr(t, "biblename")
+			case "INFORMATION":
+				inInfo = true
+			case "TITLE":
+				inTitle = inInfo
+			case "BIBLEBOOK":
+				n, _ := strconv.Atoi(xmlAttr(t, "bnumber"))
+				book = lookupFileBook(xmlAttr(t, "bname"), n)
+				bookName = strings.TrimSpace(xmlAttr(t, "bname") + " " + xmlAttr(t, "bnumber"))
+			case "CHAPTER":
+				chapter, _ = strconv.Atoi(xmlAttr(t, "cnumber"))
+			case "VERS":
+				verse, _ = strconv.Atoi(xmlAttr(t, "vnumber"))
+				text.Reset()
+			case "NOTE", "XREF", "REMARK":
+				skip++
+			}
+
+		case xml.EndElement:
+			switch strings.ToUpper(t.Name.Local) {
+			case "INFORMATION":
+				inInfo = false
+			case "TITLE":
+				if inTitle && strings.TrimSpace(title.String()) != "" {
+					imp.Name = strings.TrimSpace(title.String())
+				}
+				inTitle = false
+			case "VERS":
+				if book != nil {
+					imp.add(book, chapter, verse, text.String())
+				} else {
+					imp.dropBook(bookName)
+				}
+				verse = 0
+			case "NOTE", "XREF", "REMARK":
+				skip--
+			}
+
+		case xml.CharData:
+			switch {
+			case inTitle:
+				title.Write(t)
+			case verse > 0 && skip == 0:
+				text.Write(t)
+			}
+		}
+	}
+
+	if len(imp.Verses) == 0 {
+		return nil, fmt.Errorf("%w: no Zefania verses", ErrUnknownBibleFormat)
+	}
+	return imp, nil
+}
+
+// csvColumns are the header names accepted for each CSV column
+var csvColumns = map[string][]string{
+	"book":    {"book", "b", "book_name", "bookname", "book_id", "osis"},
+	"chapter": {"chapter", "c", "chap"},
+	"verse":   {"verse", "v", "vs"},
+	"text":    {"text", "t", "content", "scripture"},
+}
+
+// ReadCSV reads a CSV (or tab-separated) Bible with one verse per row. With
+// a header row the columns are found by name (book, chapter, verse, text and
+// common short forms); without one they must be in that order. Books may be
+// canonical numbers, OSIS IDs or names.
+func ReadCSV(r io.Reader) (*BibleImport, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte("\t")) > bytes.Count(firstLine, []byte(",")) {
+		cr.Comma = '\t'
+	}
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: empty CSV", ErrUnknownBibleFormat)
+	}
+
+	cols := map[string]int{"book": 0, "chapter": 1, "verse": 2, "text": 3}
+	if len(rows[0]) > 1 {
+		if _, err := strconv.Atoi(strings.TrimSpace(rows[0][1])); err != nil {
+			// Header row
+			for col, names := range csvColumns {
+				cols[col] = -1
+				for i, h := range rows[0] {
+					for _, name := range names {
+						if strings.EqualFold(strings.TrimSpace(h), name) {
+							cols[col] = i
+						}
+					}
+				}
+				if cols[col] < 0 {
+					return nil, fmt.Errorf("%w: CSV header has no %s column", ErrUnknownBibleFormat, col)
+				}
+			}
+			rows = rows[1:]
+		}
+	}
+
+	imp := newBibleImport()
+	for _, row := range rows {
+		if len(row) <= max(cols["book"], cols["chapter"], cols["verse"], cols["text"]) {
+			imp.Dropped = append(imp.Dropped, fmt.Sprintf("row %q has too few columns", strings.Join(row, ",")))
+			continue
+		}
+		name := strings.TrimSpace(row[cols["book"]])
+		n, _ := strconv.Atoi(name)
+		book := reference.ByID(name)
+		if book == nil {
+			book = lookupFileBook(name, n)
+		}
+		if book == nil {
+			imp.dropBook(name)
+			continue
+		}
+		chapter, err1 := strconv.Atoi(strings.TrimSpace(row[cols["chapter"]]))
+		verse, err2 := strconv.Atoi(strings.TrimSpace(row[cols["verse"]]))
+		if err1 != nil || err2 != nil {
+			imp.Dropped = append(imp.Dropped, fmt.Sprintf("%s %s:%s has no chapter or verse number",
+				book.Name, strings.TrimSpace(row[cols["chapter"]]), strings.TrimSpace(row[cols["verse"]])))
+			continue
+		}
+		imp.add(book, chapter, verse, row[cols["text"]])
+	}
+
+	if len(imp.Verses) == 0 {
+		return nil, fmt.Errorf("%w: no CSV verses", ErrUnknownBibleFormat)
+	}
+	return imp, nil
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type bibleReaderTest struct {
+	name    string
+	in      string
+	verses  []string // "Gen.1.1 text"
+	dropped []string
+	title   string
+	dupes   int
+}
+
+// runBibleReaderTests reads each input and compares the verses, dropped
+// reasons, translation name and duplicate count
+func runBibleReaderTests(t *testing.T, read BibleReader, tests []bibleReaderTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imp, err := read(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			var verses []string
+			for _, v := range imp.Verses {
+				verses = append(verses, fmt.Sprintf("%s.%d.%d %s", v.Book, v.Chapter, v.Verse, v.Text))
+			}
+			if !reflect.DeepEqual(verses, tt.verses) {
+				t.Errorf("verses = %q, want %q", verses, tt.verses)
+			}
+			if !reflect.DeepEqual(imp.Dropped, tt.dropped) {
+				t.Errorf("dropped = %q, want %q", imp.Dropped, tt.dropped)
+			}
+			if imp.Name != tt.title {
+				t.Errorf("name = %q, want %q", imp.Name, tt.title)
+			}
+			if imp.Duplicates != tt.dupes {
+				t.Errorf("duplicates = %d, want %d", imp.Duplicates, tt.dupes)
+			}
+		})
+	}
+}
+
+func TestReadOSIS(t *testing.T) {
+	runBibleReaderTests(t, ReadOSIS, []bibleReaderTest{
+		{
+			name: "container verses with a note and a title",
+			in: `<osis><osisText><header><work><title>World English Bible</title></work></header>
+<div type="book" osisID="Gen"><chapter osisID="Gen.1">
+<verse osisID="Gen.1.1">In the beginning<note>Or, "At first"</note> God created</verse>
+<verse osisID="Gen.1.2">The earth was <w lemma="x">formless</w>.</verse>
+</chapter></div></osisText></osis>`,
+			verses: []string{"Gen.1.1 In the beginning God created", "Gen.1.2 The earth was formless."},
+			title:  "World English Bible",
+		},
+		{
+			name: "milestone verses",
+			in: `<osis><chapter sID="John.3"/>
+<verse sID="John.3.16" osisID="John.3.16"/>For God so loved the world<verse eID="John.3.16"/>
+<verse sID="John.3.17" osisID="John.3.17"/>For God didn't send his Son<verse eID="John.3.17"/>
+<chapter eID="John.3"/></osis>`,
+			verses: []string{"John.3.16 For God so loved the world", "John.3.17 For God didn't send his Son"},
+		},
+		{
+			name: "unknown book, verse past the canon and a repeat",
+			in: `<osis><verse osisID="Gen.1.1">First</verse><verse osisID="Gen.1.1">Again</verse>
+<verse osisID="Gen.1.32">Extra</verse><verse osisID="Tob.1.1">Tobit</verse></osis>`,
+			verses:  []string{"Gen.1.1 First"},
+			dropped: []string{"Genesis 1:32 is outside the canon", `unknown book "Tob"`},
+			dupes:   1,
+		},
+	})
+
+	if _, err := ReadOSIS(strings.NewReader("<osis></osis>")); !errors.Is(err, ErrUnknownBibleFormat) {
+		t.Errorf("empty OSIS error = %v, want %v", err, ErrUnknownBibleFormat)
+	}
+}
+
+func TestReadUSFM(t *testing.T) {
+	runBibleReaderTests(t, ReadUSFM, []bibleReaderTest{
+		{
+			name: "headings, notes and word attributes",
+			in: `\id GEN World English Bible
+\h Genesis
+\mt1 Genesis
+\c 1
+\s1 The Creation
+\p
+\v 1 In the beginning\f + \fr 1:1 \ft Or, at first\f* God created
+\v 2 The earth was \w formless|strong="H8414"\w* and empty.
+`,
+			verses: []string{"Gen.1.1 In the beginning God created", "Gen.1.2 The earth was formless and empty."},
+		},
+		{
+			name: "several books in one file",
+			in: `\id RUT
+\c 1
+\v 1 In the days
+\id 3JN
+\c 1
+\v 1 The elder
+`,
+			verses: []string{"Ruth.1.1 In the days", "3John.1.1 The elder"},
+		},
+		{
+			name: "verse bridge",
+			in: `\id ROM
+\c 16
+\v 25-27 Now to him who is able
+`,
+			verses:  []string{"Rom.16.25 Now to him who is able"},
+			dropped: []string{"Romans 16:26 is bridged into 16:25", "Romans 16:27 is bridged into 16:25"},
+		},
+		{
+			name: "unknown book",
+			in: `\id GEN
+\c 1
+\v 1 In the beginning
+\id TOB
+\c 1
+\v 1 Tobit
+`,
+			verses:  []string{"Gen.1.1 In the beginning"},
+			dropped: []string{`unknown book "TOB"`},
+		},
+	})
+}
+
+func TestReadZefania(t *testing.T) {
+	runBibleReaderTests(t, ReadZefania, []bibleReaderTest{
+		{
+			name: "books by number with notes",
+			in: `<XMLBIBLE biblename="ASV">
+<INFORMATION><title>American Standard Version</title></INFORMATION>
+<BIBLEBOOK bnumber="1" bname="Genesis"><CHAPTER cnumber="1">
+<VERS vnumber="1">In the beginning <NOTE>a note</NOTE>God created</VERS>
+<VERS vnumber="2">And the earth was waste</VERS>
+</CHAPTER></BIBLEBOOK>
+<BIBLEBOOK bnumber="43" bname="John"><CHAPTER cnumber="11"><VERS vnumber="35">Jesus wept.</VERS></CHAPTER></BIBLEBOOK>
+</XMLBIBLE>`,
+			verses: []string{"Gen.1.1 In the beginning God created", "Gen.1.2 And the earth was waste", "John.11.35 Jesus wept."},
+			title:  "American Standard Version",
+		},
+		{
+			name: "book outside the canon",
+			in: `<XMLBIBLE biblename="ASV">
+<BIBLEBOOK bnumber="1"><CHAPTER cnumber="1"><VERS vnumber="1">In the beginning</VERS></CHAPTER></BIBLEBOOK>
+<BIBLEBOOK bnumber="67" bname="Tobit"><CHAPTER cnumber="1"><VERS vnumber="1">Tobit</VERS></CHAPTER></BIBLEBOOK>
+</XMLBIBLE>`,
+			verses:  []string{"Gen.1.1 In the beginning"},
+			dropped: []string{`unknown book "Tobit 67"`},
+			title:   "ASV",
+		},
+	})
+}
+
+func TestReadCSV(t *testing.T) {
+	runBibleReaderTests(t, ReadCSV, []bibleReaderTest{
+		{
+			name:   "no header, book numbers",
+			in:     "1,1,1,In the beginning\n43,11,35,Jesus wept.\n",
+			verses: []string{"Gen.1.1 In the beginning", "John.11.35 Jesus wept."},
+		},
+		{
+			name:   "header in another order, book names and IDs",
+			in:     "\xEF\xBB\xBFText,Book,Chapter,Verse\n\"In the beginning, God\",Genesis,1,1\nJesus wept.,John,11,35\n",
+			verses: []string{"Gen.1.1 In the beginning, God", "John.11.35 Jesus wept."},
+		},
+		{
+			name:   "tab separated",
+			in:     "b\tc\tv\tt\nPs\t23\t1\tThe LORD is my shepherd\n",
+			verses: []string{"Ps.23.1 The LORD is my shepherd"},
+		},
+		{
+			name:   "unreadable rows are dropped",
+			in:     "book,chapter,verse,text\nGen,1,1,In the beginning\nGen,one,2,The earth\nGen,1\nTobit,1,1,Tobit\nGen,1,99,Extra\n",
+			verses: []string{"Gen.1.1 In the beginning"},
+			dropped: []string{
+				"Genesis one:2 has no chapter or verse number",
+				`row "Gen,1" has too few columns`,
+				`unknown book "Tobit"`,
+				"Genesis 1:99 is outside the canon",
+			},
+		},
+	})
+
+	if _, err := ReadCSV(strings.NewReader("name,age\nx,1\n")); !errors.Is(err, ErrUnknownBibleFormat) {
+		t.Errorf("CSV without Bible columns error = %v, want %v", err, ErrUnknownBibleFormat)
+	}
+}
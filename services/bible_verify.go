@@ -0,0 +1,114 @@
+// services/bible_verify.go - Checking a translation's verse counts against the canon
+package services
+
+import (
+	"fmt"
+	"strings"
+	"ubible/models"
+	"ubible/reference"
+
+	"gorm.io/gorm"
+)
+
+// BookCounts is how much of one book a translation has
+type BookCounts struct {
+	Book             string   `json:"book"` // OSIS book ID
+	Chapters         int      `json:"chapters"`
+	ExpectedChapters int      `json:"expected_chapters"`
+	Verses           int      `json:"verses"`
+	ExpectedVerses   int      `json:"expected_verses"`
+	Incomplete       []string `json:"incomplete,omitempty"` // Chapters with fewer verses than the canon: "3 (35/36)"
+}
+
+// Complete reports whether the book has every chapter and verse of the canon
+func (b BookCounts) Complete() bool {
+	return b.Verses == b.ExpectedVerses
+}
+
+// TranslationReport compares a translation's book, chapter and verse counts
+// with the canon's (KJV versification). Books with no verses at all are only
+// listed in MissingBooks, so a New Testament is reported as 27 complete books.
+type TranslationReport struct {
+	Translation    string       `json:"translation"`
+	Books          []BookCounts `json:"books"`
+	MissingBooks   []string     `json:"missing_books"`
+	Verses         int          `json:"verses"`
+	ExpectedVerses int          `json:"expected_verses"` // Of the books present
+}
+
+// Complete reports whether every book present has all its verses
+func (r TranslationReport) Complete() bool {
+	return r.Verses == r.ExpectedVerses
+}
+
+// CountVerses reports on verses that haven't been stored yet
+func CountVerses(code string, verses []models.BibleVerse) TranslationReport {
+	counts := make(map[string]map[int]int)
+	for _, v := range verses {
+		if counts[v.Book] == nil {
+			counts[v.Book] = make(map[int]int)
+		}
+		counts[v.Book][v.Chapter]++
+	}
+	return newTranslationReport(code, counts)
+}
+
+// VerifyTranslation reports on a translation as it is in the store
+func VerifyTranslation(db *gorm.DB, code string) (TranslationReport, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	var rows []struct {
+		Book    string
+		Chapter int
+		Count   int
+	}
+	err := db.Model(&models.BibleVerse{}).
+		Select("book, chapter, COUNT(*) AS count").
+		Where("translation = ?", code).
+		Group("book, chapter").
+		Scan(&rows).Error
+	if err != nil {
+		return TranslationReport{}, fmt.Errorf("failed to count %s verses: %w", code, err)
+	}
+	if len(rows) == 0 {
+		return TranslationReport{}, fmt.Errorf("%w: %s", ErrUnknownTranslation, code)
+	}
+
+	counts := make(map[string]map[int]int)
+	for _, row := range rows {
+		if counts[row.Book] == nil {
+			counts[row.Book] = make(map[int]int)
+		}
+		counts[row.Book][row.Chapter] = row.Count
+	}
+	return newTranslationReport(code, counts), nil
+}
+
+// newTranslationReport builds a report from verse counts by book and chapter
+func newTranslationReport(code string, counts map[string]map[int]int) TranslationReport {
+	report := TranslationReport{Translation: code, Books: []BookCounts{}, MissingBooks: []string{}}
+	for _, book := range reference.Canon {
+		chapters, ok := counts[book.ID]
+		if !ok {
+			report.MissingBooks = append(report.MissingBooks, book.ID)
+			continue
+		}
+
+		bc := BookCounts{Book: book.ID, ExpectedChapters: book.Chapters()}
+		for c := 1; c <= book.Chapters(); c++ {
+			expected := book.VerseCount(c)
+			bc.ExpectedVerses += expected
+			n := chapters[c]
+			if n > 0 {
+				bc.Chapters++
+			}
+			bc.Verses += n
+			if n < expected {
+				bc.Incomplete = append(bc.Incomplete, fmt.Sprintf("%d (%d/%d)", c, n, expected))
+			}
+		}
+		report.Books = append(report.Books, bc)
+		report.Verses += bc.Verses
+		report.ExpectedVerses += bc.ExpectedVerses
+	}
+	return report
+}
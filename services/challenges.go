@@ -0,0 +1,676 @@
+// services/challenges.go - Team challenge lifecycle: scheduling, attempts and scoring
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"ubible/database"
+	"ubible/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ChallengeTickInterval    = time.Minute
+	DefaultChallengeDuration = 7 * 24 * time.Hour
+	MaxChallengeQuestions    = 50
+)
+
+var (
+	ErrChallengeNotFound       = errors.New("challenge not found")
+	ErrInvalidChallenge        = errors.New("invalid challenge")
+	ErrChallengeStarted        = errors.New("challenge has already started")
+	ErrChallengeClosed         = errors.New("challenge is no longer open")
+	ErrChallengeNotActive      = errors.New("challenge is not active")
+	ErrChallengeFull           = errors.New("challenge is full")
+	ErrAlreadyInChallenge      = errors.New("already joined this challenge")
+	ErrNotChallengeParticipant = errors.New("not a participant in this challenge")
+	ErrChallengeAttemptUsed    = errors.New("challenge already played")
+)
+
+// ChallengeInput creates or edits a challenge. Nil fields keep their current
+// value (or the default, on create).
+type ChallengeInput struct {
+	Name            *string    `json:"name"`
+	Description     *string    `json:"description"`
+	ThemeID         *uint      `json:"theme_id"`
+	NumQuestions    *int       `json:"num_questions"`
+	TimeLimit       *int       `json:"time_limit"`
+	StartDate       *time.Time `json:"start_date"`
+	EndDate         *time.Time `json:"end_date"`
+	MinParticipants *int       `json:"min_participants"`
+	MaxParticipants *int       `json:"max_participants"`
+}
+
+// ChallengeService schedules team challenges and scores their participants
+type ChallengeService struct {
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewChallengeService creates a new challenge service
+func NewChallengeService() *ChallengeService {
+	return &ChallengeService{stop: make(chan struct{})}
+}
+
+// Start runs the scheduler: challenges are activated at StartDate and closed
+// at EndDate, checked every ChallengeTickInterval
+func (s *ChallengeService) Start() {
+	go func() {
+		ticker := time.NewTicker(ChallengeTickInterval)
+		defer ticker.Stop()
+
+		s.Tick(time.Now())
+		for {
+			select {
+			case now := <-ticker.C:
+				s.Tick(now)
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	log.Printf("🏁 Challenge scheduler started (every %s)", ChallengeTickInterval)
+}
+
+// Stop ends the scheduler
+func (s *ChallengeService) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// Tick activates pending challenges whose start has passed and completes
+// active ones whose end has passed
+func (s *ChallengeService) Tick(now time.Time) {
+	db := database.GetDB()
+	if db == nil {
+		return
+	}
+
+	var due []models.Challenge
+	db.Where("status = ? AND start_date <= ?", models.ChallengeStatusPending, now).Find(&due)
+	for _, c := range due {
+		if err := s.activate(c.ID, now); err != nil {
+			log.Printf("⚠️  Failed to activate challenge %d: %v", c.ID, err)
+		}
+	}
+
+	due = nil
+	db.Where("status = ? AND end_date <= ?", models.ChallengeStatusActive, now).Find(&due)
+	for _, c := range due {
+		if err := s.complete(c.ID, now); err != nil {
+			log.Printf("⚠️  Failed to complete challenge %d: %v", c.ID, err)
+		}
+	}
+}
+
+// ================== CHALLENGE MANAGEMENT ==================
+
+// Create schedules a challenge for a team. It starts now if no start date is
+// given and runs for DefaultChallengeDuration if no end date is given.
+func (s *ChallengeService) Create(teamID, creatorID uint, in ChallengeInput) (*models.Challenge, error) {
+	db := database.GetDB()
+
+	now := time.Now()
+	c := &models.Challenge{
+		TeamID:          teamID,
+		NumQuestions:    10,
+		TimeLimit:       30,
+		StartDate:       now,
+		MinParticipants: 2,
+		Status:          models.ChallengeStatusPending,
+		CreatedBy:       creatorID,
+		CreatedAt:       now,
+	}
+	if in.StartDate == nil && in.EndDate == nil {
+		c.EndDate = now.Add(DefaultChallengeDuration)
+	} else if in.EndDate == nil {
+		c.EndDate = in.StartDate.Add(DefaultChallengeDuration)
+	}
+
+	if err := s.apply(c, in); err != nil {
+		return nil, err
+	}
+
+	if err := db.Create(c).Error; err != nil {
+		return nil, fmt.Errorf("failed to create challenge: %w", err)
+	}
+
+	log.Printf("🎯 Challenge %d %q scheduled for team %d (%s - %s)",
+		c.ID, c.Name, teamID, c.StartDate.Format(time.RFC3339), c.EndDate.Format(time.RFC3339))
+
+	// Starting now shouldn't wait for the next tick
+	if !c.StartDate.After(now) {
+		if err := s.activate(c.ID, now); err != nil {
+			log.Printf("⚠️  Failed to activate challenge %d: %v", c.ID, err)
+		}
+		db.First(c, c.ID)
+	}
+
+	return c, nil
+}
+
+// Update edits a challenge that hasn't started yet
+func (s *ChallengeService) Update(challengeID uint, in ChallengeInput) (*models.Challenge, error) {
+	db := database.GetDB()
+
+	var c models.Challenge
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&c, challengeID).Error; err != nil {
+			return ErrChallengeNotFound
+		}
+		if c.Status != models.ChallengeStatusPending {
+			return ErrChallengeStarted
+		}
+		if err := s.apply(&c, in); err != nil {
+			return err
+		}
+		c.UpdatedAt = time.Now()
+		return tx.Save(&c).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Cancel stops a pending or active challenge and tells its participants why
+func (s *ChallengeService) Cancel(challengeID uint, reason string) error {
+	db := database.GetDB()
+
+	result := db.Model(&models.Challenge{}).
+		Where("id = ? AND status IN ?", challengeID,
+			[]models.ChallengeStatus{models.ChallengeStatusPending, models.ChallengeStatusActive}).
+		Updates(map[string]interface{}{
+			"status":        models.ChallengeStatusCancelled,
+			"cancel_reason": reason,
+			"updated_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to cancel challenge: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		db.Model(&models.Challenge{}).Where("id = ?", challengeID).Count(&count)
+		if count == 0 {
+			return ErrChallengeNotFound
+		}
+		return ErrChallengeClosed
+	}
+
+	s.notifyCancelled(challengeID, reason)
+	return nil
+}
+
+// apply validates input onto c
+func (s *ChallengeService) apply(c *models.Challenge, in ChallengeInput) error {
+	if in.Name != nil {
+		c.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Description != nil {
+		c.Description = *in.Description
+	}
+	if in.ThemeID != nil {
+		c.ThemeID = *in.ThemeID
+	}
+	if in.NumQuestions != nil {
+		c.NumQuestions = *in.NumQuestions
+	}
+	if in.TimeLimit != nil {
+		c.TimeLimit = *in.TimeLimit
+	}
+	if in.StartDate != nil {
+		c.StartDate = *in.StartDate
+	}
+	if in.EndDate != nil {
+		c.EndDate = *in.EndDate
+	}
+	if in.MinParticipants != nil {
+		c.MinParticipants = *in.MinParticipants
+	}
+	if in.MaxParticipants != nil {
+		c.MaxParticipants = *in.MaxParticipants
+	}
+
+	switch {
+	case c.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidChallenge)
+	case len(c.Name) > 100:
+		return fmt.Errorf("%w: name is too long", ErrInvalidChallenge)
+	case c.NumQuestions < 1 || c.NumQuestions > MaxChallengeQuestions:
+		return fmt.Errorf("%w: num_questions must be between 1 and %d", ErrInvalidChallenge, MaxChallengeQuestions)
+	case c.TimeLimit < 5 || c.TimeLimit > 300:
+		return fmt.Errorf("%w: time_limit must be between 5 and 300 seconds", ErrInvalidChallenge)
+	case !c.EndDate.After(c.StartDate):
+		return fmt.Errorf("%w: end_date must be after start_date", ErrInvalidChallenge)
+	case !c.EndDate.After(time.Now()):
+		return fmt.Errorf("%w: end_date must be in the future", ErrInvalidChallenge)
+	case c.MinParticipants < 1:
+		return fmt.Errorf("%w: min_participants must be at least 1", ErrInvalidChallenge)
+	case c.MaxParticipants != 0 && c.MaxParticipants < c.MinParticipants:
+		return fmt.Errorf("%w: max_participants must be 0 (unlimited) or at least min_participants", ErrInvalidChallenge)
+	}
+
+	// The theme must belong to the team or be shared publicly
+	var count int64
+	database.GetDB().Model(&models.TeamTheme{}).
+		Where("id = ? AND is_active = ? AND (team_id = ? OR is_public = ?)", c.ThemeID, true, c.TeamID, true).
+		Count(&count)
+	if count == 0 {
+		return fmt.Errorf("%w: theme not found", ErrInvalidChallenge)
+	}
+
+	return nil
+}
+
+// ================== PARTICIPATION ==================
+
+// Join adds a team member to a pending or active challenge
+func (s *ChallengeService) Join(challengeID, userID uint) (*models.ChallengeParticipant, error) {
+	db := database.GetDB()
+
+	participant := &models.ChallengeParticipant{
+		ChallengeID: challengeID,
+		UserID:      userID,
+		JoinedAt:    time.Now(),
+		Status:      models.ParticipantStatusJoined,
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Lock the challenge so concurrent joins can't overfill it
+		var c models.Challenge
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&c, challengeID).Error; err != nil {
+			return ErrChallengeNotFound
+		}
+		if (c.Status != models.ChallengeStatusPending && c.Status != models.ChallengeStatusActive) ||
+			!time.Now().Before(c.EndDate) {
+			return ErrChallengeClosed
+		}
+
+		var member int64
+		tx.Model(&models.TeamMember{}).
+			Where("team_id = ? AND user_id = ? AND is_active = ?", c.TeamID, userID, true).
+			Count(&member)
+		if member == 0 {
+			return ErrNotTeamMember
+		}
+
+		if c.MaxParticipants > 0 {
+			var joined int64
+			tx.Model(&models.ChallengeParticipant{}).Where("challenge_id = ?", challengeID).Count(&joined)
+			if joined >= int64(c.MaxParticipants) {
+				return ErrChallengeFull
+			}
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(participant)
+		if result.Error != nil {
+			return fmt.Errorf("failed to join challenge: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrAlreadyInChallenge
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return participant, nil
+}
+
+// Leave withdraws from a challenge that hasn't started yet
+func (s *ChallengeService) Leave(challengeID, userID uint) error {
+	db := database.GetDB()
+
+	var c models.Challenge
+	if err := db.First(&c, challengeID).Error; err != nil {
+		return ErrChallengeNotFound
+	}
+	if c.Status != models.ChallengeStatusPending {
+		return ErrChallengeStarted
+	}
+
+	result := db.Where("challenge_id = ? AND user_id = ?", challengeID, userID).
+		Delete(&models.ChallengeParticipant{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to leave challenge: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotChallengeParticipant
+	}
+	return nil
+}
+
+// BeginAttempt checks a participant may play an active challenge and returns
+// the challenge, their participation and the fixed question set
+func (s *ChallengeService) BeginAttempt(challengeID, userID uint) (*models.Challenge, *models.ChallengeParticipant, []models.QuestionData, error) {
+	db := database.GetDB()
+
+	var c models.Challenge
+	if err := db.First(&c, challengeID).Error; err != nil {
+		return nil, nil, nil, ErrChallengeNotFound
+	}
+	if c.Status != models.ChallengeStatusActive || !time.Now().Before(c.EndDate) {
+		return nil, nil, nil, ErrChallengeNotActive
+	}
+
+	var p models.ChallengeParticipant
+	if err := db.Where("challenge_id = ? AND user_id = ?", challengeID, userID).First(&p).Error; err != nil {
+		return nil, nil, nil, ErrNotChallengeParticipant
+	}
+	if p.Status == models.ParticipantStatusCompleted {
+		return nil, nil, nil, ErrChallengeAttemptUsed
+	}
+
+	questions, err := c.GetQuestions()
+	if err != nil || len(questions) == 0 {
+		return nil, nil, nil, fmt.Errorf("challenge %d has no question set", c.ID)
+	}
+
+	return &c, &p, questions, nil
+}
+
+// AttachSession records the quiz session a participant is playing. Returns
+// false if another session was attached first.
+func (s *ChallengeService) AttachSession(participantID uint, sessionID string) (bool, error) {
+	result := database.GetDB().Model(&models.ChallengeParticipant{}).
+		Where("id = ? AND status = ?", participantID, models.ParticipantStatusJoined).
+		Updates(map[string]interface{}{
+			"status":     models.ParticipantStatusPlaying,
+			"session_id": sessionID,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to start challenge attempt: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RecordResult stores a finished attempt inside tx. The challenge must still be active.
+func (s *ChallengeService) RecordResult(tx *gorm.DB, challengeID, userID uint, score, correct, incorrect, timeSpent int) error {
+	var c models.Challenge
+	if err := tx.Select("id", "status").First(&c, challengeID).Error; err != nil {
+		return ErrChallengeNotFound
+	}
+	if c.Status != models.ChallengeStatusActive {
+		return ErrChallengeNotActive
+	}
+
+	now := time.Now()
+	result := tx.Model(&models.ChallengeParticipant{}).
+		Where("challenge_id = ? AND user_id = ? AND status = ?", challengeID, userID, models.ParticipantStatusPlaying).
+		Updates(map[string]interface{}{
+			"status":       models.ParticipantStatusCompleted,
+			"score":        score,
+			"correct":      correct,
+			"incorrect":    incorrect,
+			"time_spent":   timeSpent,
+			"completed_at": now,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to record challenge result: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotChallengeParticipant
+	}
+
+	log.Printf("🎯 User %d finished challenge %d - score %d (%d/%d) in %ds", userID, challengeID, score, correct, correct+incorrect, timeSpent)
+	return nil
+}
+
+// Leaderboard returns a challenge's participants, best first. Finished
+// attempts rank by score, then by time taken.
+func (s *ChallengeService) Leaderboard(challengeID uint) ([]models.ChallengeParticipant, error) {
+	var participants []models.ChallengeParticipant
+	err := database.GetDB().Where("challenge_id = ?", challengeID).
+		Preload("User").
+		Order("CASE WHEN status = 'completed' THEN 0 ELSE 1 END, score DESC, time_spent ASC, joined_at ASC").
+		Find(&participants).Error
+	return participants, err
+}
+
+// ================== LIFECYCLE ==================
+
+// activate starts a pending challenge: fixes its question set, or cancels it
+// if too few members joined or its theme has nothing to play
+func (s *ChallengeService) activate(challengeID uint, now time.Time) error {
+	db := database.GetDB()
+
+	var c models.Challenge
+	var participants []models.ChallengeParticipant
+	cancelReason := ""
+	activated := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&c, challengeID).Error; err != nil {
+			return err
+		}
+		if c.Status != models.ChallengeStatusPending {
+			return nil // Another instance got here first
+		}
+
+		if err := tx.Where("challenge_id = ?", c.ID).Find(&participants).Error; err != nil {
+			return err
+		}
+
+		if len(participants) < c.MinParticipants {
+			// A challenge created to start immediately had no chance to fill
+			// up, so it keeps waiting for players until its end date
+			startsNow := !c.StartDate.After(c.CreatedAt)
+			if startsNow && now.Before(c.EndDate) {
+				return nil
+			}
+			cancelReason = fmt.Sprintf("only %d of the %d required participants joined", len(participants), c.MinParticipants)
+		} else if questions, err := s.pickQuestions(tx, c); err != nil {
+			cancelReason = err.Error()
+		} else if err := c.SetQuestions(questions); err != nil {
+			return err
+		}
+
+		if cancelReason != "" {
+			c.Status = models.ChallengeStatusCancelled
+			c.CancelReason = cancelReason
+		} else {
+			c.Status = models.ChallengeStatusActive
+			c.StartedAt = &now
+			activated = true
+		}
+		c.UpdatedAt = now
+		return tx.Save(&c).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if cancelReason != "" {
+		log.Printf("🚫 Challenge %d cancelled: %s", c.ID, cancelReason)
+		s.notifyCancelled(c.ID, cancelReason)
+		return nil
+	}
+	if !activated {
+		return nil
+	}
+
+	log.Printf("▶️  Challenge %d %q is live with %d participants", c.ID, c.Name, len(participants))
+	for _, p := range participants {
+		s.notify(p.UserID, "challenge_started", fmt.Sprintf("%s has started", c.Name), map[string]interface{}{
+			"challenge_id": c.ID,
+			"team_id":      c.TeamID,
+			"name":         c.Name,
+			"end_date":     c.EndDate,
+		})
+	}
+	return nil
+}
+
+// complete closes an active challenge, ranks finished attempts and credits
+// each finisher's score to their team membership
+func (s *ChallengeService) complete(challengeID uint, now time.Time) error {
+	db := database.GetDB()
+
+	var c models.Challenge
+	var participants []models.ChallengeParticipant
+	completed := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&c, challengeID).Error; err != nil {
+			return err
+		}
+		if c.Status != models.ChallengeStatusActive {
+			return nil
+		}
+
+		if err := tx.Where("challenge_id = ?", c.ID).Find(&participants).Error; err != nil {
+			return err
+		}
+		rankParticipants(participants)
+
+		for i := range participants {
+			p := &participants[i]
+			if p.Status != models.ParticipantStatusCompleted {
+				p.Status = models.ParticipantStatusDidNotFinish
+				if err := tx.Model(p).Update("status", p.Status).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := tx.Model(p).Update("rank", p.Rank).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.TeamMember{}).
+				Where("team_id = ? AND user_id = ?", c.TeamID, p.UserID).
+				Updates(map[string]interface{}{
+					"total_score":    gorm.Expr("total_score + ?", p.Score),
+					"quizzes_played": gorm.Expr("quizzes_played + 1"),
+					"last_active":    now,
+				}).Error; err != nil {
+				return fmt.Errorf("failed to credit team score: %w", err)
+			}
+		}
+
+		c.Status = models.ChallengeStatusCompleted
+		c.CompletedAt = &now
+		c.UpdatedAt = now
+		completed = true
+		return tx.Save(&c).Error
+	})
+	if err != nil || !completed {
+		return err
+	}
+
+	finishers := 0
+	for _, p := range participants {
+		if p.Status == models.ParticipantStatusCompleted {
+			finishers++
+		}
+	}
+	log.Printf("🏆 Challenge %d %q completed - %d of %d participants finished", c.ID, c.Name, finishers, len(participants))
+
+	for _, p := range participants {
+		s.notify(p.UserID, "challenge_completed", fmt.Sprintf("%s has finished", c.Name), map[string]interface{}{
+			"challenge_id": c.ID,
+			"team_id":      c.TeamID,
+			"name":         c.Name,
+			"status":       p.Status,
+			"rank":         p.Rank,
+			"score":        p.Score,
+			"finishers":    finishers,
+		})
+	}
+	return nil
+}
+
+// rankParticipants sorts finishers by score then time and assigns ranks; equal
+// score and time share a rank. Non-finishers sort last with rank 0.
+func rankParticipants(participants []models.ChallengeParticipant) {
+	sort.SliceStable(participants, func(i, j int) bool {
+		a, b := participants[i], participants[j]
+		aDone, bDone := a.Status == models.ParticipantStatusCompleted, b.Status == models.ParticipantStatusCompleted
+		if aDone != bDone {
+			return aDone
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.TimeSpent < b.TimeSpent
+	})
+
+	for i := range participants {
+		p := &participants[i]
+		if p.Status != models.ParticipantStatusCompleted {
+			p.Rank = 0
+			continue
+		}
+		p.Rank = i + 1
+		if i > 0 {
+			prev := participants[i-1]
+			if prev.Status == models.ParticipantStatusCompleted && prev.Score == p.Score && prev.TimeSpent == p.TimeSpent {
+				p.Rank = prev.Rank
+			}
+		}
+	}
+}
+
+// pickQuestions draws the challenge's fixed question set from its team theme,
+// seeded by the challenge ID
+func (s *ChallengeService) pickQuestions(tx *gorm.DB, c models.Challenge) ([]models.QuestionData, error) {
+	var theme models.TeamTheme
+	if err := tx.Where("id = ? AND is_active = ?", c.ThemeID, true).First(&theme).Error; err != nil {
+		return nil, errors.New("the challenge theme no longer exists")
+	}
+
+	bank, err := TeamThemes.Questions(theme)
+	if err != nil {
+		return nil, err
+	}
+	if err := TeamThemes.RecordUse(tx, theme.ID); err != nil {
+		return nil, err
+	}
+
+	questions := PickQuestions(bank, c.NumQuestions, "challenge:"+strconv.FormatUint(uint64(c.ID), 10))
+	for i := range questions {
+		questions[i].ID = i + 1 // Generated questions have no database ID
+	}
+	return questions, nil
+}
+
+// ================== NOTIFICATIONS ==================
+
+func (s *ChallengeService) notifyCancelled(challengeID uint, reason string) {
+	db := database.GetDB()
+
+	var c models.Challenge
+	if err := db.First(&c, challengeID).Error; err != nil {
+		return
+	}
+
+	var userIDs []uint
+	db.Model(&models.ChallengeParticipant{}).Where("challenge_id = ?", challengeID).Pluck("user_id", &userIDs)
+	notified := map[uint]bool{}
+	for _, userID := range append(userIDs, c.CreatedBy) {
+		if notified[userID] {
+			continue
+		}
+		notified[userID] = true
+		s.notify(userID, "challenge_cancelled", fmt.Sprintf("%s was cancelled", c.Name), map[string]interface{}{
+			"challenge_id": c.ID,
+			"team_id":      c.TeamID,
+			"name":         c.Name,
+			"reason":       reason,
+		})
+	}
+}
+
+// notify adds a challenge event to the user's notifications
+func (s *ChallengeService) notify(userID uint, msgType, title string, payload map[string]interface{}) {
+	Notifications.Notify(userID, models.NotificationCategoryChallenge, msgType, title, "", payload)
+}
+
+// Global instance
+var Challenges = NewChallengeService()
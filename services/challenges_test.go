@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+	"ubible/models"
+)
+
+func TestRankParticipants(t *testing.T) {
+	done := models.ParticipantStatusCompleted
+	tests := []struct {
+		name         string
+		participants []models.ChallengeParticipant
+		want         map[uint]int // User ID -> rank
+		order        []uint
+	}{
+		{
+			name: "score first, then time",
+			participants: []models.ChallengeParticipant{
+				{UserID: 1, Status: done, Score: 500, TimeSpent: 90},
+				{UserID: 2, Status: done, Score: 800, TimeSpent: 120},
+				{UserID: 3, Status: done, Score: 500, TimeSpent: 60},
+			},
+			want:  map[uint]int{2: 1, 3: 2, 1: 3},
+			order: []uint{2, 3, 1},
+		},
+		{
+			name: "equal score and time share a rank",
+			participants: []models.ChallengeParticipant{
+				{UserID: 1, Status: done, Score: 500, TimeSpent: 60},
+				{UserID: 2, Status: done, Score: 500, TimeSpent: 60},
+				{UserID: 3, Status: done, Score: 100, TimeSpent: 30},
+			},
+			want:  map[uint]int{1: 1, 2: 1, 3: 3},
+			order: []uint{1, 2, 3},
+		},
+		{
+			name: "non-finishers sort last unranked",
+			participants: []models.ChallengeParticipant{
+				{UserID: 1, Status: models.ParticipantStatusDidNotFinish, Score: 900},
+				{UserID: 2, Status: done, Score: 100, TimeSpent: 60},
+				{UserID: 3, Status: models.ParticipantStatusPlaying, Score: 700, Rank: 4},
+				{UserID: 4, Status: done, Score: 300, TimeSpent: 200},
+			},
+			want:  map[uint]int{4: 1, 2: 2, 1: 0, 3: 0},
+			order: []uint{4, 2, 1, 3},
+		},
+		{
+			name: "a non-finisher doesn't share a finisher's rank",
+			participants: []models.ChallengeParticipant{
+				{UserID: 1, Status: done},
+				{UserID: 2, Status: models.ParticipantStatusJoined},
+			},
+			want:  map[uint]int{1: 1, 2: 0},
+			order: []uint{1, 2},
+		},
+		{
+			name:         "no participants",
+			participants: nil,
+			want:         map[uint]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rankParticipants(tt.participants)
+			for i, p := range tt.participants {
+				if p.Rank != tt.want[p.UserID] {
+					t.Errorf("user %d rank = %d, want %d", p.UserID, p.Rank, tt.want[p.UserID])
+				}
+				if p.UserID != tt.order[i] {
+					t.Errorf("position %d is user %d, want %d", i, p.UserID, tt.order[i])
+				}
+			}
+		})
+	}
+}
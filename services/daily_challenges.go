@@ -0,0 +1,492 @@
+// services/daily_challenges.go - Daily and weekly challenges: one shared question set per period
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"ubible/database"
+	"ubible/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	dailyQuestionCount  = 10
+	dailyTimeLimit      = 20
+	weeklyQuestionCount = 20
+	weeklyTimeLimit     = 30
+)
+
+var (
+	ErrUnknownPeriod        = errors.New("period must be daily or weekly")
+	ErrInvalidPeriodKey     = errors.New("invalid period key")
+	ErrPeriodBeforeLaunch   = errors.New("challenges hadn't started yet in that period")
+	ErrNoDailyQuestions     = errors.New("no questions available for the challenge")
+	ErrDailyAttemptUsed     = errors.New("you have already played this challenge")
+	ErrDailyAttemptNotFound = errors.New("no attempt in progress for this challenge")
+	ErrDailyChallengePlayed = errors.New("challenge already has attempts and can't be changed")
+)
+
+// DailyPin is an admin override for one period's question set. Question IDs
+// are used in order; otherwise questions are drawn from the themes (all
+// themes if none) with the period's seed.
+type DailyPin struct {
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	QuestionIDs   []uint `json:"question_ids"`
+	ThemeIDs      []uint `json:"theme_ids"`
+	QuestionCount int    `json:"question_count"`
+	TimeLimit     int    `json:"time_limit"`
+}
+
+// DailyChallengeService derives, pins and scores daily and weekly challenges
+type DailyChallengeService struct {
+	launch time.Time  // First day challenges ran; zero until known
+	mu     sync.Mutex // Guards launch
+}
+
+// NewDailyChallengeService creates a new daily challenge service;
+// DAILY_CHALLENGES_LAUNCH (YYYY-MM-DD) sets the first day challenges ran
+func NewDailyChallengeService() *DailyChallengeService {
+	s := &DailyChallengeService{}
+	if v := os.Getenv("DAILY_CHALLENGES_LAUNCH"); v != "" {
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			s.launch = t
+		} else {
+			log.Printf("⚠️  Ignoring DAILY_CHALLENGES_LAUNCH %q: %v", v, err)
+		}
+	}
+	return s
+}
+
+// launchDate returns the first day challenges ran. Without
+// DAILY_CHALLENGES_LAUNCH it is the day the earliest challenge was stored, or
+// today before there is one. Earlier periods have no challenge, so none is
+// derived or pinned for them.
+func (s *DailyChallengeService) launchDate() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.launch.IsZero() {
+		return s.launch
+	}
+
+	var first models.DailyChallenge
+	if err := database.GetDB().Order("created_at").First(&first).Error; err == nil {
+		s.launch = first.CreatedAt.UTC().Truncate(24 * time.Hour)
+		return s.launch
+	}
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// PeriodKey names the period containing t: the UTC date for daily challenges
+// and the ISO week for weekly ones
+func (s *DailyChallengeService) PeriodKey(period string, t time.Time) (string, error) {
+	t = t.UTC()
+	switch period {
+	case models.PeriodDaily:
+		return t.Format("2006-01-02"), nil
+	case models.PeriodWeekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week), nil
+	}
+	return "", ErrUnknownPeriod
+}
+
+// PeriodBounds returns the UTC start and end of a period key. Only the
+// canonical key PeriodKey gives a period is accepted, so each period has one
+// challenge, and periods that ended before the launch are rejected.
+func (s *DailyChallengeService) PeriodBounds(period, key string) (time.Time, time.Time, error) {
+	var start, end time.Time
+	switch period {
+	case models.PeriodDaily:
+		t, err := time.Parse("2006-01-02", key)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidPeriodKey
+		}
+		start, end = t, t.AddDate(0, 0, 1)
+	case models.PeriodWeekly:
+		var year, week int
+		if _, err := fmt.Sscanf(key, "%d-W%d", &year, &week); err != nil || week < 1 || week > 53 {
+			return time.Time{}, time.Time{}, ErrInvalidPeriodKey
+		}
+		// Monday of ISO week 1 is the Monday on or before January 4th
+		jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+		start = jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+(week-1)*7)
+		end = start.AddDate(0, 0, 7)
+	default:
+		return time.Time{}, time.Time{}, ErrUnknownPeriod
+	}
+
+	if canonical, _ := s.PeriodKey(period, start); canonical != key {
+		return time.Time{}, time.Time{}, ErrInvalidPeriodKey
+	}
+	if !end.After(s.launchDate()) {
+		return time.Time{}, time.Time{}, ErrPeriodBeforeLaunch
+	}
+	return start, end, nil
+}
+
+// Get returns the challenge for a period, deriving and storing its question
+// set on first use. The set is seeded by the period, so every server derives
+// the same one, and storing it keeps it fixed as questions are added.
+func (s *DailyChallengeService) Get(period, key string) (*models.DailyChallenge, error) {
+	if _, _, err := s.PeriodBounds(period, key); err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB()
+
+	var challenge models.DailyChallenge
+	if err := db.Where("period = ? AND period_key = ?", period, key).First(&challenge).Error; err == nil {
+		return &challenge, nil
+	}
+
+	challenge = models.DailyChallenge{
+		Period:    period,
+		PeriodKey: key,
+		Title:     defaultDailyTitle(period, key),
+		TimeLimit: dailyTimeLimit,
+	}
+	count := dailyQuestionCount
+	if period == models.PeriodWeekly {
+		challenge.TimeLimit = weeklyTimeLimit
+		count = weeklyQuestionCount
+	}
+
+	questions, err := s.drawQuestions(nil, count, period+":"+key)
+	if err != nil {
+		return nil, err
+	}
+	if err := challenge.SetQuestions(questions); err != nil {
+		return nil, err
+	}
+
+	// Another request may have derived it first; keep theirs
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&challenge).Error; err != nil {
+		return nil, fmt.Errorf("failed to save %s challenge: %w", period, err)
+	}
+	if challenge.ID == 0 {
+		if err := db.Where("period = ? AND period_key = ?", period, key).First(&challenge).Error; err != nil {
+			return nil, err
+		}
+	} else {
+		log.Printf("📅 Derived %s challenge %s (%d questions)", period, key, challenge.QuestionCount)
+	}
+
+	return &challenge, nil
+}
+
+// Current returns the challenge for the period containing now
+func (s *DailyChallengeService) Current(period string, now time.Time) (*models.DailyChallenge, error) {
+	key, err := s.PeriodKey(period, now)
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(period, key)
+}
+
+// Pin sets a period's question set by hand. A period can only be changed
+// before anyone has played it.
+func (s *DailyChallengeService) Pin(period, key string, adminID uint, pin DailyPin) (*models.DailyChallenge, error) {
+	if _, _, err := s.PeriodBounds(period, key); err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB()
+
+	var questions []models.QuestionData
+	if len(pin.QuestionIDs) > 0 {
+		var rows []models.Question
+		if err := db.Preload("Theme").Where("id IN ?", pin.QuestionIDs).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		byID := make(map[uint]models.Question, len(rows))
+		for _, q := range rows {
+			byID[q.ID] = q
+		}
+		rng := SeededRand(period + ":" + key)
+		for _, id := range pin.QuestionIDs {
+			q, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("%w: question %d not found", ErrNoDailyQuestions, id)
+			}
+			questions = append(questions, QuestionDataFrom(q, rng))
+		}
+	} else {
+		count := pin.QuestionCount
+		if count <= 0 {
+			count = dailyQuestionCount
+			if period == models.PeriodWeekly {
+				count = weeklyQuestionCount
+			}
+		}
+		if count > 100 {
+			count = 100
+		}
+		var err error
+		if questions, err = s.drawQuestions(pin.ThemeIDs, count, period+":"+key); err != nil {
+			return nil, err
+		}
+	}
+
+	var challenge models.DailyChallenge
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("period = ? AND period_key = ?", period, key).First(&challenge).Error
+		if err == nil {
+			var attempts int64
+			tx.Model(&models.DailyChallengeAttempt{}).Where("daily_challenge_id = ?", challenge.ID).Count(&attempts)
+			if attempts > 0 {
+				return ErrDailyChallengePlayed
+			}
+		} else if errors.Is(err, gorm.ErrRecordNotFound) {
+			challenge = models.DailyChallenge{Period: period, PeriodKey: key}
+		} else {
+			return err
+		}
+
+		challenge.Title = strings.TrimSpace(pin.Title)
+		if challenge.Title == "" {
+			challenge.Title = defaultDailyTitle(period, key)
+		}
+		challenge.Description = strings.TrimSpace(pin.Description)
+		challenge.TimeLimit = pin.TimeLimit
+		if challenge.TimeLimit < 5 || challenge.TimeLimit > 300 {
+			challenge.TimeLimit = dailyTimeLimit
+			if period == models.PeriodWeekly {
+				challenge.TimeLimit = weeklyTimeLimit
+			}
+		}
+		challenge.IsPinned = true
+		challenge.PinnedBy = &adminID
+		if err := challenge.SetQuestions(questions); err != nil {
+			return err
+		}
+		return tx.Save(&challenge).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("📌 Admin %d pinned %s challenge %s (%d questions)", adminID, period, key, challenge.QuestionCount)
+	return &challenge, nil
+}
+
+// Unpin removes an unplayed override so the period's set is derived again
+func (s *DailyChallengeService) Unpin(period, key string) error {
+	db := database.GetDB()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var challenge models.DailyChallenge
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("period = ? AND period_key = ?", period, key).First(&challenge).Error; err != nil {
+			return nil // Nothing pinned
+		}
+
+		var attempts int64
+		tx.Model(&models.DailyChallengeAttempt{}).Where("daily_challenge_id = ?", challenge.ID).Count(&attempts)
+		if attempts > 0 {
+			return ErrDailyChallengePlayed
+		}
+		return tx.Delete(&challenge).Error
+	})
+}
+
+// List returns stored challenges for a period, newest first
+func (s *DailyChallengeService) List(period string, limit, offset int) ([]models.DailyChallenge, int64, error) {
+	query := database.GetDB().Model(&models.DailyChallenge{})
+	if period != "" {
+		query = query.Where("period = ?", period)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var challenges []models.DailyChallenge
+	err := query.Order("period_key DESC").Limit(limit).Offset(offset).Find(&challenges).Error
+	return challenges, total, err
+}
+
+// drawQuestions picks count questions from active themes with a fixed seed.
+// Every player gets the set, so themes sold in the shop are left out.
+// Questions are read in ID order so the seed alone decides the selection.
+func (s *DailyChallengeService) drawQuestions(themeIDs []uint, count int, seed string) ([]models.QuestionData, error) {
+	query := database.GetDB().Model(&models.Question{}).Preload("Theme").
+		Joins("JOIN themes ON themes.id = questions.theme_id").
+		Where("themes.is_active = ? AND themes.unlock_cost = 0", true)
+	if len(themeIDs) > 0 {
+		query = query.Where("questions.theme_id IN ?", themeIDs)
+	}
+
+	var questions []models.Question
+	if err := query.Order("questions.id").Find(&questions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load questions: %w", err)
+	}
+	if len(questions) == 0 {
+		return nil, ErrNoDailyQuestions
+	}
+
+	return PickQuestions(questions, count, seed), nil
+}
+
+// ================== ATTEMPTS ==================
+
+// Attempt returns a user's attempt at a challenge, or nil
+func (s *DailyChallengeService) Attempt(challengeID, userID uint) *models.DailyChallengeAttempt {
+	var attempt models.DailyChallengeAttempt
+	if err := database.GetDB().Where("daily_challenge_id = ? AND user_id = ?", challengeID, userID).
+		First(&attempt).Error; err != nil {
+		return nil
+	}
+	return &attempt
+}
+
+// BeginAttempt claims the user's one attempt at a challenge for sessionID.
+// If an attempt is already in progress it is returned with started false so
+// the caller can resume its session.
+func (s *DailyChallengeService) BeginAttempt(challengeID, userID uint, sessionID string) (*models.DailyChallengeAttempt, bool, error) {
+	db := database.GetDB()
+
+	attempt := &models.DailyChallengeAttempt{
+		DailyChallengeID: challengeID,
+		UserID:           userID,
+		SessionID:        sessionID,
+		Status:           "playing",
+		StartedAt:        time.Now(),
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(attempt)
+	if result.Error != nil {
+		return nil, false, fmt.Errorf("failed to start attempt: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return attempt, true, nil
+	}
+
+	existing := s.Attempt(challengeID, userID)
+	if existing == nil {
+		return nil, false, ErrDailyAttemptNotFound
+	}
+	if existing.Status == "completed" {
+		return existing, false, ErrDailyAttemptUsed
+	}
+	return existing, false, nil
+}
+
+// AbandonAttempt releases an attempt whose session couldn't be created
+func (s *DailyChallengeService) AbandonAttempt(attemptID uint) {
+	database.GetDB().Where("id = ? AND status = ?", attemptID, "playing").Delete(&models.DailyChallengeAttempt{})
+}
+
+// RecordResult scores a finished attempt inside tx and, for daily challenges,
+// advances the user's daily streak. Returns the streak after the update.
+func (s *DailyChallengeService) RecordResult(tx *gorm.DB, challengeID, userID uint, score, correct, total, timeSpent int) (int, error) {
+	var challenge models.DailyChallenge
+	if err := tx.First(&challenge, challengeID).Error; err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	result := tx.Model(&models.DailyChallengeAttempt{}).
+		Where("daily_challenge_id = ? AND user_id = ? AND status = ?", challengeID, userID, "playing").
+		Updates(map[string]interface{}{
+			"status":          "completed",
+			"score":           score,
+			"correct_answers": correct,
+			"total_questions": total,
+			"time_spent":      timeSpent,
+			"completed_at":    now,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to record attempt: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrDailyAttemptNotFound
+	}
+
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "daily_streak", "best_daily_streak", "last_daily_challenge").
+		First(&user, userID).Error; err != nil {
+		return 0, err
+	}
+	if challenge.Period != models.PeriodDaily || user.LastDailyChallenge >= challenge.PeriodKey {
+		return s.DailyStreak(user, now), nil
+	}
+
+	// Consecutive only if the previous completed daily was the day before
+	day, _ := time.Parse("2006-01-02", challenge.PeriodKey)
+	if user.LastDailyChallenge == day.AddDate(0, 0, -1).Format("2006-01-02") {
+		user.DailyStreak++
+	} else {
+		user.DailyStreak = 1
+	}
+	if user.DailyStreak > user.BestDailyStreak {
+		user.BestDailyStreak = user.DailyStreak
+	}
+
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"daily_streak":         user.DailyStreak,
+		"best_daily_streak":    user.BestDailyStreak,
+		"last_daily_challenge": challenge.PeriodKey,
+	}).Error; err != nil {
+		return 0, fmt.Errorf("failed to update daily streak: %w", err)
+	}
+
+	return user.DailyStreak, nil
+}
+
+// DailyStreak returns the user's live daily streak: it lapses once a full UTC
+// day passes without a completed daily challenge
+func (s *DailyChallengeService) DailyStreak(user models.User, now time.Time) int {
+	today := now.UTC().Format("2006-01-02")
+	yesterday := now.UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	if user.LastDailyChallenge == today || user.LastDailyChallenge == yesterday {
+		return user.DailyStreak
+	}
+	return 0
+}
+
+// Leaderboard returns a page of completed attempts, best score first and
+// fastest on ties
+func (s *DailyChallengeService) Leaderboard(challengeID uint, limit, offset int) ([]models.DailyChallengeAttempt, int64, error) {
+	query := database.GetDB().Model(&models.DailyChallengeAttempt{}).
+		Where("daily_challenge_id = ? AND status = ?", challengeID, "completed")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var attempts []models.DailyChallengeAttempt
+	err := query.Preload("User").Order("score DESC, time_spent ASC, completed_at ASC").
+		Limit(limit).Offset(offset).Find(&attempts).Error
+	return attempts, total, err
+}
+
+// Rank returns the 1-based leaderboard position of a completed attempt
+func (s *DailyChallengeService) Rank(attempt models.DailyChallengeAttempt) int64 {
+	var ahead int64
+	database.GetDB().Model(&models.DailyChallengeAttempt{}).
+		Where("daily_challenge_id = ? AND status = ?", attempt.DailyChallengeID, "completed").
+		Where("score > ? OR (score = ? AND time_spent < ?)", attempt.Score, attempt.Score, attempt.TimeSpent).
+		Count(&ahead)
+	return ahead + 1
+}
+
+func defaultDailyTitle(period, key string) string {
+	if period == models.PeriodWeekly {
+		return "Weekly Challenge " + key
+	}
+	return "Daily Challenge " + key
+}
+
+// Global instance
+var DailyChallenges = NewDailyChallengeService()
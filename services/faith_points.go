@@ -0,0 +1,92 @@
+// services/faith_points.go - Faith Points balance changes and ledger
+package services
+
+import (
+	"errors"
+	"fmt"
+	"ubible/database"
+	"ubible/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrInsufficientFaithPoints = errors.New("not enough Faith Points")
+
+// FaithPointService moves Faith Points and keeps the append-only ledger in step
+type FaithPointService struct{}
+
+// NewFaithPointService creates a new Faith Points service
+func NewFaithPointService() *FaithPointService {
+	return &FaithPointService{}
+}
+
+// Record appends a ledger entry for a balance change that has already been
+// applied. Pass the transaction that changed the balance so both commit together.
+func (s *FaithPointService) Record(tx *gorm.DB, userID uint, amount, balanceAfter int, reason, reference string) error {
+	if amount == 0 {
+		return nil
+	}
+
+	entry := models.FaithPointTransaction{
+		UserID:       userID,
+		Amount:       amount,
+		BalanceAfter: balanceAfter,
+		Reason:       reason,
+		Reference:    reference,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to record Faith Points transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Adjust atomically credits (or, for a negative amount, debits) a user's
+// balance and records it. A debit that would go below zero fails with
+// ErrInsufficientFaithPoints. Returns the new balance.
+func (s *FaithPointService) Adjust(tx *gorm.DB, userID uint, amount int, reason, reference string) (int, error) {
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND faith_points + ? >= 0", userID, amount).
+		Update("faith_points", gorm.Expr("faith_points + ?", amount))
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to update Faith Points: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrInsufficientFaithPoints
+	}
+
+	var balance int
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Select("faith_points").Scan(&balance).Error; err != nil {
+		return 0, fmt.Errorf("failed to read Faith Points balance: %w", err)
+	}
+
+	if err := s.Record(tx, userID, amount, balance, reason, reference); err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+// History returns a page of a user's ledger, newest first, with the total entry count
+func (s *FaithPointService) History(userID uint, limit, offset int) ([]models.FaithPointTransaction, int64, error) {
+	db := database.GetDB()
+
+	var total int64
+	if err := db.Model(&models.FaithPointTransaction{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count Faith Points history: %w", err)
+	}
+
+	var entries []models.FaithPointTransaction
+	if err := db.Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get Faith Points history: %w", err)
+	}
+
+	return entries, total, nil
+}
+
+// Global instance
+var FaithPoints = NewFaithPointService()
@@ -0,0 +1,366 @@
+// services/friends.go - Friend requests, friendships, blocking and mutual friends
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"ubible/database"
+	"ubible/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Friend request statuses
+const (
+	FriendRequestPending   = "pending"
+	FriendRequestAccepted  = "accepted"
+	FriendRequestRejected  = "rejected"
+	FriendRequestCancelled = "cancelled"
+)
+
+var (
+	ErrFriendUserNotFound    = errors.New("user not found")
+	ErrFriendSelf            = errors.New("you can't befriend yourself")
+	ErrFriendGuest           = errors.New("guests can't have friends")
+	ErrAlreadyFriends        = errors.New("already friends")
+	ErrFriendRequestExists   = errors.New("friend request already sent")
+	ErrFriendRequestNotFound = errors.New("friend request not found")
+	ErrNotFriends            = errors.New("not friends")
+	ErrUserBlocked           = errors.New("this user can't be contacted")
+	ErrAlreadyBlocked        = errors.New("user already blocked")
+)
+
+// FriendSummary is a friend with the number of friends you share
+type FriendSummary struct {
+	UserID        uint       `json:"user_id"`
+	Username      string     `json:"username"`
+	DisplayName   string     `json:"display_name"`
+	Avatar        string     `json:"avatar"`
+	Level         int        `json:"level"`
+	Rating        float64    `json:"rating"`
+	LastActivity  *time.Time `json:"last_activity"`
+	MutualFriends int64      `json:"mutual_friends"`
+	FriendsSince  time.Time  `json:"friends_since"`
+}
+
+// FriendService manages friend requests, friendships and blocks
+type FriendService struct{}
+
+// NewFriendService creates a new friend service
+func NewFriendService() *FriendService {
+	return &FriendService{}
+}
+
+// SendRequest asks toID to be fromID's friend. If toID already asked fromID,
+// that request is accepted instead and accepted is true.
+func (s *FriendService) SendRequest(fromID, toID uint) (request *models.FriendRequest, accepted bool, err error) {
+	if fromID == toID {
+		return nil, false, ErrFriendSelf
+	}
+
+	db := database.GetDB()
+
+	var target models.User
+	if err := db.Select("id", "is_guest").First(&target, toID).Error; err != nil {
+		return nil, false, ErrFriendUserNotFound
+	}
+	if target.IsGuest {
+		return nil, false, ErrFriendGuest
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if s.blockedTx(tx, fromID, toID) {
+			return ErrUserBlocked
+		}
+		if s.areFriendsTx(tx, fromID, toID) {
+			return ErrAlreadyFriends
+		}
+
+		// Only one request per pair can be pending (idx_friend_requests_pending_pair).
+		// If a concurrent send wins the insert, ours does nothing and the
+		// second pass sees the winner's request.
+		for pass := 0; pass < 2; pass++ {
+			var pending []models.FriendRequest
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("status = ? AND ((from_user_id = ? AND to_user_id = ?) OR (from_user_id = ? AND to_user_id = ?))",
+					FriendRequestPending, fromID, toID, toID, fromID).
+				Find(&pending).Error; err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				if pending[0].FromUserID == fromID {
+					return ErrFriendRequestExists
+				}
+				request = &pending[0]
+				accepted = true
+				return s.acceptTx(tx, request)
+			}
+
+			request = &models.FriendRequest{
+				FromUserID: fromID,
+				ToUserID:   toID,
+				Status:     FriendRequestPending,
+				CreatedAt:  time.Now(),
+			}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(request)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				return nil
+			}
+		}
+		return ErrFriendRequestExists
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return request, accepted, nil
+}
+
+// Accept accepts a pending request sent to userID and creates the friendship
+func (s *FriendService) Accept(requestID, userID uint) (*models.FriendRequest, error) {
+	var request models.FriendRequest
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND to_user_id = ? AND status = ?", requestID, userID, FriendRequestPending).
+			First(&request).Error; err != nil {
+			return ErrFriendRequestNotFound
+		}
+		if s.blockedTx(tx, request.FromUserID, userID) {
+			return ErrUserBlocked
+		}
+		return s.acceptTx(tx, &request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// acceptTx marks request accepted and creates both friendship rows
+func (s *FriendService) acceptTx(tx *gorm.DB, request *models.FriendRequest) error {
+	if err := tx.Model(request).Update("status", FriendRequestAccepted).Error; err != nil {
+		return err
+	}
+	request.Status = FriendRequestAccepted
+
+	now := time.Now()
+	rows := []models.Friend{
+		{UserID: request.FromUserID, FriendID: request.ToUserID, CreatedAt: now},
+		{UserID: request.ToUserID, FriendID: request.FromUserID, CreatedAt: now},
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to create friendship: %w", err)
+	}
+	return nil
+}
+
+// Reject declines a pending request sent to userID
+func (s *FriendService) Reject(requestID, userID uint) error {
+	return s.closeRequest("id = ? AND to_user_id = ?", requestID, userID, FriendRequestRejected)
+}
+
+// Cancel withdraws a pending request userID sent
+func (s *FriendService) Cancel(requestID, userID uint) error {
+	return s.closeRequest("id = ? AND from_user_id = ?", requestID, userID, FriendRequestCancelled)
+}
+
+func (s *FriendService) closeRequest(where string, requestID, userID uint, status string) error {
+	result := database.GetDB().Model(&models.FriendRequest{}).
+		Where(where, requestID, userID).Where("status = ?", FriendRequestPending).
+		Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFriendRequestNotFound
+	}
+	return nil
+}
+
+// Remove ends a friendship in both directions
+func (s *FriendService) Remove(userID, friendID uint) error {
+	result := database.GetDB().
+		Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)", userID, friendID, friendID, userID).
+		Delete(&models.Friend{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFriends
+	}
+	return nil
+}
+
+// Block stops targetID contacting userID, ending any friendship and pending
+// requests between them
+func (s *FriendService) Block(userID, targetID uint) error {
+	if userID == targetID {
+		return ErrFriendSelf
+	}
+
+	db := database.GetDB()
+	if err := db.Select("id").First(&models.User{}, targetID).Error; err != nil {
+		return ErrFriendUserNotFound
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		block := models.UserBlock{UserID: userID, BlockedID: targetID, CreatedAt: time.Now()}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&block)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAlreadyBlocked
+		}
+
+		if err := tx.Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)",
+			userID, targetID, targetID, userID).Delete(&models.Friend{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.FriendRequest{}).
+			Where("status = ? AND ((from_user_id = ? AND to_user_id = ?) OR (from_user_id = ? AND to_user_id = ?))",
+				FriendRequestPending, userID, targetID, targetID, userID).
+			Update("status", FriendRequestRejected).Error
+	})
+}
+
+// Unblock lifts a block
+func (s *FriendService) Unblock(userID, targetID uint) error {
+	result := database.GetDB().Where("user_id = ? AND blocked_id = ?", userID, targetID).Delete(&models.UserBlock{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFriendUserNotFound
+	}
+	return nil
+}
+
+// ================== QUERIES ==================
+
+// List returns a user's friends, most recently active first, with mutual
+// friend counts
+func (s *FriendService) List(userID uint) ([]FriendSummary, error) {
+	db := database.GetDB()
+
+	var friends []FriendSummary
+	if err := db.Table("friends").
+		Select("users.id AS user_id, users.username, users.display_name, users.avatar, users.level, users.rating, "+
+			"users.last_activity, friends.created_at AS friends_since").
+		Joins("JOIN users ON users.id = friends.friend_id").
+		Where("friends.user_id = ?", userID).
+		Order("users.last_activity DESC NULLS LAST, users.username").
+		Scan(&friends).Error; err != nil {
+		return nil, fmt.Errorf("failed to load friends: %w", err)
+	}
+	if len(friends) == 0 {
+		return friends, nil
+	}
+
+	ids := make([]uint, len(friends))
+	for i, f := range friends {
+		ids[i] = f.UserID
+	}
+
+	// A friend's mutual friends are my friends who are also theirs
+	type mutualCount struct {
+		UserID uint
+		Count  int64
+	}
+	var counts []mutualCount
+	if err := db.Table("friends AS theirs").
+		Select("theirs.user_id, COUNT(*) AS count").
+		Joins("JOIN friends AS mine ON mine.friend_id = theirs.friend_id AND mine.user_id = ?", userID).
+		Where("theirs.user_id IN ?", ids).
+		Group("theirs.user_id").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count mutual friends: %w", err)
+	}
+	byUser := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		byUser[c.UserID] = c.Count
+	}
+	for i := range friends {
+		friends[i].MutualFriends = byUser[friends[i].UserID]
+	}
+
+	return friends, nil
+}
+
+// FriendIDs returns the IDs of a user's friends
+func (s *FriendService) FriendIDs(userID uint) []uint {
+	var ids []uint
+	database.GetDB().Model(&models.Friend{}).Where("user_id = ?", userID).Pluck("friend_id", &ids)
+	return ids
+}
+
+// Mutual returns the friends two users share
+func (s *FriendService) Mutual(userID, otherID uint) ([]models.User, error) {
+	db := database.GetDB()
+
+	var users []models.User
+	err := db.Select("id", "username", "display_name", "avatar", "level").
+		Where("id IN (?)", db.Model(&models.Friend{}).Select("friend_id").Where("user_id = ?", userID)).
+		Where("id IN (?)", db.Model(&models.Friend{}).Select("friend_id").Where("user_id = ?", otherID)).
+		Order("username").
+		Find(&users).Error
+	return users, err
+}
+
+// Requests returns a user's pending incoming and outgoing requests, newest first
+func (s *FriendService) Requests(userID uint) (incoming, outgoing []models.FriendRequest, err error) {
+	db := database.GetDB()
+	userFields := func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username", "display_name", "avatar", "level")
+	}
+
+	if err = db.Preload("FromUser", userFields).
+		Where("to_user_id = ? AND status = ?", userID, FriendRequestPending).
+		Order("created_at DESC").Find(&incoming).Error; err != nil {
+		return nil, nil, err
+	}
+	err = db.Preload("ToUser", userFields).
+		Where("from_user_id = ? AND status = ?", userID, FriendRequestPending).
+		Order("created_at DESC").Find(&outgoing).Error
+	return incoming, outgoing, err
+}
+
+// Blocked returns the users userID has blocked
+func (s *FriendService) Blocked(userID uint) ([]models.UserBlock, error) {
+	var blocks []models.UserBlock
+	err := database.GetDB().Preload("Blocked", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username", "display_name", "avatar")
+	}).Where("user_id = ?", userID).Order("created_at DESC").Find(&blocks).Error
+	return blocks, err
+}
+
+// AreFriends reports whether two users are friends
+func (s *FriendService) AreFriends(userID, otherID uint) bool {
+	return s.areFriendsTx(database.GetDB(), userID, otherID)
+}
+
+// IsBlocked reports whether either user has blocked the other
+func (s *FriendService) IsBlocked(userID, otherID uint) bool {
+	return s.blockedTx(database.GetDB(), userID, otherID)
+}
+
+func (s *FriendService) areFriendsTx(tx *gorm.DB, userID, otherID uint) bool {
+	var count int64
+	tx.Model(&models.Friend{}).Where("user_id = ? AND friend_id = ?", userID, otherID).Count(&count)
+	return count > 0
+}
+
+func (s *FriendService) blockedTx(tx *gorm.DB, userID, otherID uint) bool {
+	var count int64
+	tx.Model(&models.UserBlock{}).
+		Where("(user_id = ? AND blocked_id = ?) OR (user_id = ? AND blocked_id = ?)", userID, otherID, otherID, userID).
+		Count(&count)
+	return count > 0
+}
+
+// Global instance
+var Friends = NewFriendService()
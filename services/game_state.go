@@ -0,0 +1,128 @@
+// services/game_state.go - Active game checkpoints for crash recovery
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"ubible/database"
+	"ubible/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSessionNotFound = errors.New("quiz session not found")
+	ErrSessionClosed   = errors.New("quiz session has already finished")
+)
+
+// GameStateService persists live game checkpoints (active_game_states)
+type GameStateService struct{}
+
+// NewGameStateService creates a new game state service
+func NewGameStateService() *GameStateService {
+	return &GameStateService{}
+}
+
+// SaveCheckpoint upserts the checkpoint for a game, keyed by game_id. A
+// checkpoint that is no longer active (completed, abandoned) is left alone, so
+// a snapshot queued before the game ended can't make it recoverable again.
+func (s *GameStateService) SaveCheckpoint(state *models.ActiveGameState) error {
+	db := database.GetDB()
+
+	state.UpdatedAt = time.Now()
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "game_id"}},
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "active_game_states.status = ?", Vars: []interface{}{"active"}}}},
+		UpdateAll: true,
+	}).Create(state).Error; err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateStatus sets the status of a checkpoint (completed, abandoned)
+func (s *GameStateService) UpdateStatus(gameID, status string) error {
+	db := database.GetDB()
+
+	result := db.Model(&models.ActiveGameState{}).
+		Where("game_id = ?", gameID).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"updated_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to update checkpoint status: %w", result.Error)
+	}
+
+	log.Printf("📊 DB: Game %s checkpoint marked %s", gameID, status)
+	return nil
+}
+
+// GetRecoverableGames returns unexpired multiplayer checkpoints that were still active
+func (s *GameStateService) GetRecoverableGames() ([]models.ActiveGameState, error) {
+	db := database.GetDB()
+
+	var states []models.ActiveGameState
+	if err := db.Where("status = ? AND is_multiplayer = ? AND expires_at > ?", "active", true, time.Now()).
+		Order("started_at ASC").
+		Find(&states).Error; err != nil {
+		return nil, fmt.Errorf("failed to get recoverable games: %w", err)
+	}
+
+	return states, nil
+}
+
+// GetSoloSession loads a user's single-player session
+func (s *GameStateService) GetSoloSession(gameID string, userID uint) (*models.ActiveGameState, error) {
+	db := database.GetDB()
+
+	var state models.ActiveGameState
+	if err := db.Where("game_id = ? AND is_single_player = ? AND user_id = ?", gameID, true, userID).
+		First(&state).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	return &state, nil
+}
+
+// UpdateSoloSession locks an active single-player session, applies update and
+// saves the result. update runs inside the transaction, so anything it writes
+// through tx commits or rolls back together with the session.
+func (s *GameStateService) UpdateSoloSession(gameID string, userID uint, update func(tx *gorm.DB, state *models.ActiveGameState) error) (*models.ActiveGameState, error) {
+	db := database.GetDB()
+
+	var state models.ActiveGameState
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("game_id = ? AND is_single_player = ? AND user_id = ?", gameID, true, userID).
+			First(&state).Error; err != nil {
+			return ErrSessionNotFound
+		}
+		if state.Status != "active" || time.Now().After(state.ExpiresAt) {
+			return ErrSessionClosed
+		}
+
+		if err := update(tx, &state); err != nil {
+			return err
+		}
+
+		state.UpdatedAt = time.Now()
+		if err := tx.Save(&state).Error; err != nil {
+			return fmt.Errorf("failed to save session: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// Global instance
+var GameState = NewGameStateService()
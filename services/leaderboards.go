@@ -0,0 +1,485 @@
+// services/leaderboards.go - Global, time-windowed, per-theme and friends leaderboards
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"ubible/database"
+	"ubible/models"
+)
+
+// Leaderboard windows
+const (
+	WindowDaily   = "daily"
+	WindowWeekly  = "weekly"
+	WindowMonthly = "monthly"
+	WindowSeason  = "season"
+	WindowAll     = "all"
+)
+
+const (
+	LeaderboardCacheTTL   = time.Minute
+	LeaderboardMaxEntries = 1000 // Boards keep this many ranked players
+	LeaderboardMaxCached  = 1000 // Boards the cache holds before evicting the oldest
+)
+
+var (
+	ErrUnknownWindow = errors.New("window must be daily, weekly, monthly, season or all")
+	ErrUnknownMetric = errors.New("unknown leaderboard category")
+	ErrUnknownTheme  = errors.New("theme not found")
+)
+
+// windowMetrics are the categories a windowed board ranks by, with the
+// default first. They are summed from attempts and multiplayer placements.
+var windowMetrics = []string{"xp", "score", "wins", "games", "perfect"}
+
+// allTimeOrder ranks the all-time board from the lifetime counters on users
+var allTimeOrder = map[string]string{
+	"level":    "level DESC, xp DESC",
+	"xp":       "xp DESC, wins DESC, total_games ASC",
+	"wins":     "wins DESC, level DESC",
+	"streak":   "best_streak DESC, level DESC",
+	"accuracy": "perfect_games DESC, wins DESC",
+}
+
+// LeaderboardQuery selects a board. ThemeID limits a windowed board to
+// attempts on one theme.
+type LeaderboardQuery struct {
+	Window  string
+	Metric  string
+	ThemeID uint
+}
+
+// LeaderboardEntry is one ranked player
+type LeaderboardEntry struct {
+	Rank         int    `json:"rank"`
+	UserID       uint   `json:"user_id"`
+	Username     string `json:"username"`
+	Avatar       string `json:"avatar"`
+	Level        int    `json:"level"`
+	Value        int64  `json:"value"` // The ranked category
+	XP           int64  `json:"xp"`
+	Score        int64  `json:"score"`
+	Games        int64  `json:"games"`
+	Wins         int64  `json:"wins"`
+	PerfectGames int64  `json:"perfect_games"`
+}
+
+// Leaderboard is a materialised board, shared between requests until it expires
+type Leaderboard struct {
+	Window      string             `json:"window"`
+	Metric      string             `json:"category"`
+	ThemeID     uint               `json:"theme_id,omitempty"`
+	PeriodKey   string             `json:"period_key,omitempty"`
+	StartsAt    *time.Time         `json:"starts_at,omitempty"`
+	EndsAt      *time.Time         `json:"ends_at,omitempty"`
+	Entries     []LeaderboardEntry `json:"entries"`
+	GeneratedAt time.Time          `json:"generated_at"`
+
+	index map[uint]int
+}
+
+// Find returns a player's entry, if they made the board
+func (b *Leaderboard) Find(userID uint) (LeaderboardEntry, bool) {
+	i, ok := b.index[userID]
+	if !ok {
+		return LeaderboardEntry{}, false
+	}
+	return b.Entries[i], true
+}
+
+// Page returns up to limit entries starting at offset
+func (b *Leaderboard) Page(limit, offset int) []LeaderboardEntry {
+	if offset >= len(b.Entries) {
+		return []LeaderboardEntry{}
+	}
+	end := offset + limit
+	if end > len(b.Entries) {
+		end = len(b.Entries)
+	}
+	return b.Entries[offset:end]
+}
+
+// Around returns the entries within n places of a player
+func (b *Leaderboard) Around(userID uint, n int) []LeaderboardEntry {
+	i, ok := b.index[userID]
+	if !ok {
+		return []LeaderboardEntry{}
+	}
+	start := i - n
+	if start < 0 {
+		start = 0
+	}
+	return b.Page(i+n+1-start, start)
+}
+
+// LeaderboardService builds leaderboards and caches them for
+// LeaderboardCacheTTL so requests don't aggregate on every read
+type LeaderboardService struct {
+	mu     sync.Mutex
+	boards map[string]*Leaderboard
+}
+
+// NewLeaderboardService creates a new leaderboard service
+func NewLeaderboardService() *LeaderboardService {
+	return &LeaderboardService{boards: make(map[string]*Leaderboard)}
+}
+
+// WindowBounds returns the UTC period containing now and its key. The season
+// window is the active season, or the calendar quarter before one is open.
+func (s *LeaderboardService) WindowBounds(window string, now time.Time) (time.Time, time.Time, string, error) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch window {
+	case WindowDaily:
+		return day, day.AddDate(0, 0, 1), day.Format("2006-01-02"), nil
+	case WindowWeekly:
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		year, week := start.ISOWeek()
+		return start, start.AddDate(0, 0, 7), fmt.Sprintf("%d-W%02d", year, week), nil
+	case WindowMonthly:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0), start.Format("2006-01"), nil
+	case WindowSeason:
+		if season := Seasons.Active(); season != nil {
+			return season.StartsAt, season.EndsAt, fmt.Sprintf("S%d", season.Number), nil
+		}
+		start := seasonQuarterStart(now)
+		return start, start.AddDate(0, SeasonLengthMonths, 0), fmt.Sprintf("%d-Q%d", now.Year(), (int(now.Month())-1)/3+1), nil
+	}
+	return time.Time{}, time.Time{}, "", ErrUnknownWindow
+}
+
+// Normalize fills in the default window and category and validates them
+// along with the theme
+func (s *LeaderboardService) Normalize(q LeaderboardQuery) (LeaderboardQuery, error) {
+	if q.Window == "" {
+		q.Window = WindowAll
+	}
+	if q.ThemeID != 0 {
+		var count int64
+		if err := database.GetDB().Model(&models.Theme{}).Where("id = ?", q.ThemeID).Count(&count).Error; err != nil {
+			return q, err
+		}
+		if count == 0 {
+			return q, ErrUnknownTheme
+		}
+	}
+	if q.Window == WindowAll && q.ThemeID == 0 {
+		if q.Metric == "" {
+			q.Metric = "level"
+		}
+		if _, ok := allTimeOrder[q.Metric]; !ok {
+			return q, ErrUnknownMetric
+		}
+		return q, nil
+	}
+
+	if _, _, _, err := s.WindowBounds(q.Window, time.Now()); err != nil && q.Window != WindowAll {
+		return q, err
+	}
+	if q.Metric == "" {
+		q.Metric = windowMetrics[0]
+	}
+	for _, m := range windowMetrics {
+		if m == q.Metric && !(m == "wins" && q.ThemeID != 0) {
+			return q, nil
+		}
+	}
+	return q, ErrUnknownMetric
+}
+
+// Get returns the global board for q, building it if the cached one expired
+func (s *LeaderboardService) Get(q LeaderboardQuery) (*Leaderboard, error) {
+	q, err := s.Normalize(q)
+	if err != nil {
+		return nil, err
+	}
+	return s.cached(s.cacheKey(q, "global"), func() (*Leaderboard, error) {
+		return s.build(q, nil)
+	})
+}
+
+// Friends returns the board for q among a player and their friends
+func (s *LeaderboardService) Friends(q LeaderboardQuery, userID uint) (*Leaderboard, error) {
+	q, err := s.Normalize(q)
+	if err != nil {
+		return nil, err
+	}
+	return s.cached(s.cacheKey(q, "friends:"+strconv.FormatUint(uint64(userID), 10)), func() (*Leaderboard, error) {
+		return s.build(q, append([]uint{userID}, Friends.FriendIDs(userID)...))
+	})
+}
+
+// AllTimeRank ranks a player who fell off the all-time board by counting the
+// players ahead of them
+func (s *LeaderboardService) AllTimeRank(user models.User, metric string) int64 {
+	db := database.GetDB()
+
+	var rank int64
+	switch metric {
+	case "xp":
+		db.Raw("SELECT COUNT(*) + 1 FROM users WHERE is_guest = false AND (xp > ? OR (xp = ? AND wins > ?) OR (xp = ? AND wins = ? AND total_games < ?))",
+			user.XP, user.XP, user.Wins, user.XP, user.Wins, user.TotalGames).Scan(&rank)
+	case "wins":
+		db.Raw("SELECT COUNT(*) + 1 FROM users WHERE is_guest = false AND (wins > ? OR (wins = ? AND level > ?))",
+			user.Wins, user.Wins, user.Level).Scan(&rank)
+	case "streak":
+		db.Raw("SELECT COUNT(*) + 1 FROM users WHERE is_guest = false AND (best_streak > ? OR (best_streak = ? AND level > ?))",
+			user.BestStreak, user.BestStreak, user.Level).Scan(&rank)
+	case "accuracy":
+		db.Raw("SELECT COUNT(*) + 1 FROM users WHERE is_guest = false AND (perfect_games > ? OR (perfect_games = ? AND wins > ?))",
+			user.PerfectGames, user.PerfectGames, user.Wins).Scan(&rank)
+	default:
+		db.Raw("SELECT COUNT(*) + 1 FROM users WHERE is_guest = false AND (level > ? OR (level = ? AND xp > ?))",
+			user.Level, user.Level, user.XP).Scan(&rank)
+	}
+	return rank
+}
+
+// Invalidate drops every cached board
+func (s *LeaderboardService) Invalidate() {
+	s.mu.Lock()
+	s.boards = make(map[string]*Leaderboard)
+	s.mu.Unlock()
+}
+
+func (s *LeaderboardService) cacheKey(q LeaderboardQuery, scope string) string {
+	return fmt.Sprintf("%s|%s|%s|%d", scope, q.Window, q.Metric, q.ThemeID)
+}
+
+// cached returns the board under key, or builds and stores a fresh one. Boards
+// are rebuilt outside the lock, so two requests may race to rebuild one.
+// Storing a board drops the expired ones, and the oldest once the cache is full.
+func (s *LeaderboardService) cached(key string, build func() (*Leaderboard, error)) (*Leaderboard, error) {
+	s.mu.Lock()
+	board, ok := s.boards[key]
+	s.mu.Unlock()
+	if ok && time.Since(board.GeneratedAt) < LeaderboardCacheTTL {
+		return board, nil
+	}
+
+	board, err := build()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.prune()
+	s.boards[key] = board
+	s.mu.Unlock()
+	return board, nil
+}
+
+// prune drops expired boards and, if the cache is still full, the oldest one.
+// Callers hold s.mu.
+func (s *LeaderboardService) prune() {
+	oldestKey := ""
+	var oldest time.Time
+	for key, board := range s.boards {
+		if time.Since(board.GeneratedAt) >= LeaderboardCacheTTL {
+			delete(s.boards, key)
+			continue
+		}
+		if oldestKey == "" || board.GeneratedAt.Before(oldest) {
+			oldestKey, oldest = key, board.GeneratedAt
+		}
+	}
+	if len(s.boards) >= LeaderboardMaxCached {
+		delete(s.boards, oldestKey)
+	}
+}
+
+// build ranks every player (or only userIDs) for q
+func (s *LeaderboardService) build(q LeaderboardQuery, userIDs []uint) (*Leaderboard, error) {
+	board := &Leaderboard{Window: q.Window, Metric: q.Metric, ThemeID: q.ThemeID, GeneratedAt: time.Now()}
+
+	var entries []LeaderboardEntry
+	var err error
+	if q.Window == WindowAll && q.ThemeID == 0 {
+		entries, err = s.buildAllTime(q.Metric, userIDs)
+	} else {
+		var start, end *time.Time
+		if q.Window != WindowAll {
+			from, to, key, _ := s.WindowBounds(q.Window, board.GeneratedAt)
+			start, end, board.PeriodKey = &from, &to, key
+			board.StartsAt, board.EndsAt = start, end
+		}
+		entries, err = s.buildWindow(q, start, end, userIDs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) > LeaderboardMaxEntries {
+		entries = entries[:LeaderboardMaxEntries]
+	}
+	board.Entries = entries
+	board.index = make(map[uint]int, len(entries))
+	for i := range board.Entries {
+		board.Entries[i].Rank = i + 1
+		board.index[board.Entries[i].UserID] = i
+	}
+	return board, nil
+}
+
+// buildAllTime ranks players by their lifetime counters
+func (s *LeaderboardService) buildAllTime(metric string, userIDs []uint) ([]LeaderboardEntry, error) {
+	query := database.GetDB().Model(&models.User{}).
+		Select("id", "username", "avatar", "level", "xp", "total_games", "wins", "best_streak", "perfect_games").
+		Where("is_guest = ?", false)
+	if userIDs != nil {
+		query = query.Where("id IN ?", userIDs)
+	}
+
+	var users []models.User
+	if err := query.Order(allTimeOrder[metric]).Limit(LeaderboardMaxEntries).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to load leaderboard: %w", err)
+	}
+
+	entries := make([]LeaderboardEntry, len(users))
+	for i, u := range users {
+		e := LeaderboardEntry{
+			UserID:       u.ID,
+			Username:     u.Username,
+			Avatar:       u.Avatar,
+			Level:        u.Level,
+			XP:           int64(u.XP),
+			Games:        int64(u.TotalGames),
+			Wins:         int64(u.Wins),
+			PerfectGames: int64(u.PerfectGames),
+		}
+		switch metric {
+		case "level":
+			e.Value = int64(u.Level)
+		case "xp":
+			e.Value = e.XP
+		case "wins":
+			e.Value = e.Wins
+		case "streak":
+			e.Value = int64(u.BestStreak)
+		case "accuracy":
+			e.Value = e.PerfectGames
+		}
+		entries[i] = e
+	}
+	return entries, nil
+}
+
+// buildWindow sums attempts, and multiplayer wins, between start and end (all
+// time when nil) and ranks players by q.Metric with score breaking ties
+func (s *LeaderboardService) buildWindow(q LeaderboardQuery, start, end *time.Time, userIDs []uint) ([]LeaderboardEntry, error) {
+	db := database.GetDB()
+
+	type attemptTotals struct {
+		UserID  uint
+		XP      int64
+		Score   int64
+		Games   int64
+		Perfect int64
+	}
+	attemptQuery := db.Model(&models.Attempt{}).
+		Select("user_id, SUM(xp_earned) AS xp, SUM(score) AS score, COUNT(*) AS games, " +
+			"SUM(CASE WHEN is_perfect THEN 1 ELSE 0 END) AS perfect")
+	if start != nil {
+		attemptQuery = attemptQuery.Where("created_at >= ? AND created_at < ?", *start, *end)
+	}
+	if q.ThemeID != 0 {
+		attemptQuery = attemptQuery.Where("theme_id = ?", q.ThemeID)
+	}
+	if userIDs != nil {
+		attemptQuery = attemptQuery.Where("user_id IN ?", userIDs)
+	}
+	var attempts []attemptTotals
+	if err := attemptQuery.Group("user_id").Scan(&attempts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load leaderboard attempts: %w", err)
+	}
+
+	byUser := make(map[uint]*LeaderboardEntry, len(attempts))
+	for _, a := range attempts {
+		byUser[a.UserID] = &LeaderboardEntry{UserID: a.UserID, XP: a.XP, Score: a.Score, Games: a.Games, PerfectGames: a.Perfect}
+	}
+
+	// Wins are first places in completed multiplayer games; themes are mixed there
+	if q.ThemeID == 0 {
+		type winTotals struct {
+			UserID uint
+			Wins   int64
+		}
+		winQuery := db.Table("multiplayer_game_players AS p").
+			Select("p.user_id, COUNT(*) AS wins").
+			Joins("JOIN multiplayer_games g ON g.id = p.game_id").
+			Where("p.placement = 1 AND p.user_id IS NOT NULL AND g.status = ?", "completed")
+		if start != nil {
+			winQuery = winQuery.Where("g.completed_at >= ? AND g.completed_at < ?", *start, *end)
+		}
+		if userIDs != nil {
+			winQuery = winQuery.Where("p.user_id IN ?", userIDs)
+		}
+		var wins []winTotals
+		if err := winQuery.Group("p.user_id").Scan(&wins).Error; err != nil {
+			return nil, fmt.Errorf("failed to load leaderboard wins: %w", err)
+		}
+		for _, w := range wins {
+			if e, ok := byUser[w.UserID]; ok {
+				e.Wins = w.Wins
+			} else {
+				byUser[w.UserID] = &LeaderboardEntry{UserID: w.UserID, Wins: w.Wins}
+			}
+		}
+	}
+
+	if len(byUser) == 0 {
+		return []LeaderboardEntry{}, nil
+	}
+
+	ids := make([]uint, 0, len(byUser))
+	for id := range byUser {
+		ids = append(ids, id)
+	}
+	var users []models.User
+	if err := db.Select("id", "username", "avatar", "level").
+		Where("id IN ? AND is_guest = ?", ids, false).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to load leaderboard players: %w", err)
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(users))
+	for _, u := range users {
+		e := *byUser[u.ID]
+		e.Username, e.Avatar, e.Level = u.Username, u.Avatar, u.Level
+		switch q.Metric {
+		case "xp":
+			e.Value = e.XP
+		case "score":
+			e.Value = e.Score
+		case "wins":
+			e.Value = e.Wins
+		case "games":
+			e.Value = e.Games
+		case "perfect":
+			e.Value = e.PerfectGames
+		}
+		if e.Value > 0 {
+			entries = append(entries, e)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.UserID < b.UserID
+	})
+	return entries, nil
+}
+
+// Global instance
+var Leaderboards = NewLeaderboardService()
@@ -149,8 +149,9 @@ func (s *MultiplayerDBService) UpdateCurrentQuestion(gameID string, questionInde
 	return nil
 }
 
-// CompleteGame marks a game as completed and calculates placements
-func (s *MultiplayerDBService) CompleteGame(gameID string) error {
+// CompleteGame marks a game as completed and stores each player's placement.
+// Placements come from RankPlacements, so tied players share a place.
+func (s *MultiplayerDBService) CompleteGame(gameID string, placements map[string]int) error {
 	db := database.GetDB()
 
 	now := time.Now()
@@ -165,26 +166,103 @@ func (s *MultiplayerDBService) CompleteGame(gameID string) error {
 		return fmt.Errorf("failed to complete game: %w", err)
 	}
 
-	// Calculate placements
-	var game models.MultiplayerGame
-	if err := db.Where("game_id = ?", gameID).First(&game).Error; err != nil {
-		return fmt.Errorf("game not found: %w", err)
+	for playerID, placement := range placements {
+		if err := db.Model(&models.MultiplayerGamePlayer{}).
+			Where("game_id IN (SELECT id FROM multiplayer_games WHERE game_id = ?) AND player_id = ?", gameID, playerID).
+			Update("placement", placement).Error; err != nil {
+			return fmt.Errorf("failed to record placement: %w", err)
+		}
 	}
 
-	var players []models.MultiplayerGamePlayer
-	if err := db.Where("game_id = ?", game.ID).Order("final_score DESC, correct_answers DESC").Find(&players).Error; err != nil {
-		return fmt.Errorf("failed to get players: %w", err)
+	log.Printf("📊 DB: Game %s completed with %d players", gameID, len(placements))
+	return nil
+}
+
+// RecordPlayerRewards stores the XP and FP a player earned from a game
+func (s *MultiplayerDBService) RecordPlayerRewards(gameID, playerID string, xpEarned, fpEarned int) error {
+	db := database.GetDB()
+
+	result := db.Model(&models.MultiplayerGamePlayer{}).
+		Where("game_id IN (SELECT id FROM multiplayer_games WHERE game_id = ?) AND player_id = ?", gameID, playerID).
+		Updates(map[string]interface{}{
+			"xp_earned": xpEarned,
+			"fp_earned": fpEarned,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to record player rewards: %w", result.Error)
+	}
+
+	return nil
+}
+
+// RecordPlayerRating stores a player's rating change from a game
+func (s *MultiplayerDBService) RecordPlayerRating(gameID, playerID string, before float64, result RatingResult) error {
+	db := database.GetDB()
+
+	dbResult := db.Model(&models.MultiplayerGamePlayer{}).
+		Where("game_id IN (SELECT id FROM multiplayer_games WHERE game_id = ?) AND player_id = ?", gameID, playerID).
+		Updates(map[string]interface{}{
+			"rating_before":    before,
+			"rating_after":     result.Rating,
+			"rating_change":    result.Change,
+			"rating_deviation": result.Deviation,
+		})
+
+	if dbResult.Error != nil {
+		return fmt.Errorf("failed to record player rating: %w", dbResult.Error)
 	}
 
-	// Assign placements
-	for i, player := range players {
-		placement := i + 1
-		db.Model(&models.MultiplayerGamePlayer{}).
-			Where("id = ?", player.ID).
-			Update("placement", placement)
+	return nil
+}
+
+// RatingHistoryEntry is one rated game in a player's history
+type RatingHistoryEntry struct {
+	GameID          string    `json:"game_id"` // Public game UUID
+	Placement       int       `json:"placement"`
+	FinalScore      int       `json:"final_score"`
+	RatingBefore    float64   `json:"rating_before"`
+	RatingAfter     float64   `json:"rating_after"`
+	RatingChange    float64   `json:"rating_change"`
+	RatingDeviation float64   `json:"rating_deviation"`
+	PlayedAt        time.Time `json:"played_at"`
+}
+
+// GetRatingHistory retrieves a user's rated games, newest first
+func (s *MultiplayerDBService) GetRatingHistory(userID uint, limit int) ([]RatingHistoryEntry, error) {
+	db := database.GetDB()
+
+	var entries []RatingHistoryEntry
+	if err := db.Table("multiplayer_game_players AS p").
+		Select("g.game_id, p.placement, p.final_score, p.rating_before, p.rating_after, p.rating_change, p.rating_deviation, p.joined_at AS played_at").
+		Joins("JOIN multiplayer_games g ON g.id = p.game_id").
+		Where("p.user_id = ? AND p.rating_after > 0", userID).
+		Order("p.joined_at DESC").
+		Limit(limit).
+		Scan(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get rating history: %w", err)
+	}
+
+	return entries, nil
+}
+
+// AbandonGame marks a game that ended without completing (e.g. everyone left)
+func (s *MultiplayerDBService) AbandonGame(gameID string) error {
+	db := database.GetDB()
+
+	now := time.Now()
+	result := db.Model(&models.MultiplayerGame{}).
+		Where("game_id = ? AND status IN ?", gameID, []string{"waiting", "playing"}).
+		Updates(map[string]interface{}{
+			"status":       "abandoned",
+			"completed_at": now,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to abandon game: %w", result.Error)
 	}
 
-	log.Printf("📊 DB: Game %s completed with %d players", gameID, len(players))
+	log.Printf("📊 DB: Game %s abandoned", gameID)
 	return nil
 }
 
@@ -324,6 +402,21 @@ func (s *MultiplayerDBService) GetGameEvents(gameID string) ([]models.Multiplaye
 	return events, nil
 }
 
+// GetLastEventSequence returns the highest event sequence number logged for a game
+func (s *MultiplayerDBService) GetLastEventSequence(gameID string) (int64, error) {
+	db := database.GetDB()
+
+	var seq int64
+	if err := db.Model(&models.MultiplayerGameEvent{}).
+		Where("game_id IN (SELECT id FROM multiplayer_games WHERE game_id = ?)", gameID).
+		Select("COALESCE(MAX(sequence_num), 0)").
+		Scan(&seq).Error; err != nil {
+		return 0, fmt.Errorf("failed to get last event sequence: %w", err)
+	}
+
+	return seq, nil
+}
+
 // GetActiveGames retrieves all currently active (waiting or playing) games
 func (s *MultiplayerDBService) GetActiveGames() ([]models.MultiplayerGame, error) {
 	db := database.GetDB()
@@ -0,0 +1,176 @@
+// services/notifications.go - Persistent per-user notifications with live delivery
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"ubible/database"
+	"ubible/models"
+
+	"gorm.io/gorm/clause"
+)
+
+var ErrUnknownNotificationCategory = errors.New("unknown notification category")
+
+// NotificationService stores user-level events and pushes them to the user's
+// open connections
+type NotificationService struct {
+	// Deliver sends a real-time message to every connection a signed-in user
+	// has open. Set by the handlers package; nil stores without pushing.
+	Deliver func(userID uint, msgType string, payload interface{})
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService() *NotificationService {
+	return &NotificationService{}
+}
+
+// Send stores a notification and pushes it live as a "notification" message.
+// Notifications in a category the user muted are stored already read and
+// not pushed.
+func (s *NotificationService) Send(userID uint, category, msgType, title, body string, data map[string]interface{}) (*models.Notification, error) {
+	if !IsNotificationCategory(category) {
+		return nil, ErrUnknownNotificationCategory
+	}
+
+	n := models.Notification{
+		UserID:   userID,
+		Category: category,
+		Type:     msgType,
+		Title:    title,
+		Body:     body,
+	}
+	if err := n.SetData(data); err != nil {
+		return nil, fmt.Errorf("failed to encode notification data: %w", err)
+	}
+
+	muted := s.IsMuted(userID, category)
+	if muted {
+		now := time.Now()
+		n.ReadAt = &now
+	}
+	if err := database.GetDB().Create(&n).Error; err != nil {
+		return nil, fmt.Errorf("failed to store notification: %w", err)
+	}
+
+	if !muted && s.Deliver != nil {
+		s.Deliver(userID, "notification", map[string]interface{}{
+			"notification": n,
+			"unread":       s.UnreadCount(userID),
+		})
+	}
+	return &n, nil
+}
+
+// Push sends a live-only message (one that isn't kept, like a room invite) to
+// the user's open connections unless they muted its category
+func (s *NotificationService) Push(userID uint, category, msgType string, payload interface{}) {
+	if s.Deliver == nil || s.IsMuted(userID, category) {
+		return
+	}
+	s.Deliver(userID, msgType, payload)
+}
+
+// Notify is Send for callers with nothing to do on failure
+func (s *NotificationService) Notify(userID uint, category, msgType, title, body string, data map[string]interface{}) {
+	if _, err := s.Send(userID, category, msgType, title, body, data); err != nil {
+		log.Printf("⚠️  Notification %s for user %d: %v", msgType, userID, err)
+	}
+}
+
+// List returns a page of the user's notifications, newest first, optionally
+// only unread ones or one category
+func (s *NotificationService) List(userID uint, unreadOnly bool, category string, limit, offset int) ([]models.Notification, int64, error) {
+	query := database.GetDB().Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []models.Notification
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+	for i := range notifications {
+		if err := notifications[i].LoadData(); err != nil {
+			log.Printf("⚠️  Notification %d has unreadable data: %v", notifications[i].ID, err)
+		}
+	}
+	return notifications, total, nil
+}
+
+// UnreadCount returns how many of the user's notifications are unread
+func (s *NotificationService) UnreadCount(userID uint) int64 {
+	var count int64
+	database.GetDB().Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).Count(&count)
+	return count
+}
+
+// MarkRead marks the given notifications read. Empty ids marks every unread
+// notification, limited to category when one is given. Returns how many changed.
+func (s *NotificationService) MarkRead(userID uint, ids []uint, category string) (int64, error) {
+	query := database.GetDB().Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	result := query.Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// Mutes returns the categories the user has muted
+func (s *NotificationService) Mutes(userID uint) []string {
+	muted := []string{}
+	database.GetDB().Model(&models.NotificationMute{}).
+		Where("user_id = ?", userID).Order("category").Pluck("category", &muted)
+	return muted
+}
+
+// IsMuted reports whether the user muted category
+func (s *NotificationService) IsMuted(userID uint, category string) bool {
+	var count int64
+	database.GetDB().Model(&models.NotificationMute{}).
+		Where("user_id = ? AND category = ?", userID, category).Count(&count)
+	return count > 0
+}
+
+// SetMuted mutes or unmutes a category for the user
+func (s *NotificationService) SetMuted(userID uint, category string, muted bool) error {
+	if !IsNotificationCategory(category) {
+		return ErrUnknownNotificationCategory
+	}
+
+	db := database.GetDB()
+	if !muted {
+		return db.Where("user_id = ? AND category = ?", userID, category).Delete(&models.NotificationMute{}).Error
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.NotificationMute{UserID: userID, Category: category}).Error
+}
+
+// IsNotificationCategory reports whether category is a known notification category
+func IsNotificationCategory(category string) bool {
+	for _, c := range models.NotificationCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Global instance
+var Notifications = NewNotificationService()
@@ -0,0 +1,195 @@
+// services/powerups.go - Power-up inventory and effects
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"ubible/database"
+	"ubible/models"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// Power-up types accepted by the API and WebSocket protocol
+const (
+	PowerUpFiftyFifty = "fifty_fifty"
+	PowerUpTimeFreeze = "time_freeze"
+	PowerUpHint       = "hint"
+	PowerUpSkip       = "skip"
+	PowerUpDouble     = "double"
+
+	// Extra seconds granted by a time freeze
+	TimeFreezeSeconds = 10
+)
+
+// powerUpColumns maps power-up types to their users table inventory column
+var powerUpColumns = map[string]string{
+	PowerUpFiftyFifty: "power_up5050",
+	PowerUpTimeFreeze: "power_up_time_freeze",
+	PowerUpHint:       "power_up_hint",
+	PowerUpSkip:       "power_up_skip",
+	PowerUpDouble:     "power_up_double",
+}
+
+var (
+	ErrUnknownPowerUp = errors.New("unknown power-up")
+	ErrNoPowerUp      = errors.New("no power-ups of this type left")
+)
+
+// PowerUpService spends and reports power-up inventory
+type PowerUpService struct{}
+
+// NewPowerUpService creates a new power-up service
+func NewPowerUpService() *PowerUpService {
+	return &PowerUpService{}
+}
+
+// NormalizeType accepts legacy spellings ("5050", "timeFreeze") and returns the canonical type
+func (s *PowerUpService) NormalizeType(kind string) string {
+	k := strings.ToLower(strings.TrimSpace(kind))
+	k = strings.NewReplacer("-", "_", " ", "_").Replace(k)
+	switch k {
+	case "5050", "50_50", "fiftyfifty":
+		return PowerUpFiftyFifty
+	case "timefreeze", "freeze":
+		return PowerUpTimeFreeze
+	case "double_points", "doublepoints":
+		return PowerUpDouble
+	}
+	return k
+}
+
+// Consume atomically spends one power-up and returns how many remain
+func (s *PowerUpService) Consume(userID uint, kind string) (int, error) {
+	if _, ok := powerUpColumns[kind]; !ok {
+		return 0, ErrUnknownPowerUp
+	}
+
+	db := database.GetDB()
+	if db == nil {
+		return 0, fmt.Errorf("database not available")
+	}
+
+	var remaining int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		remaining, err = s.ConsumeTx(tx, userID, kind)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return remaining, nil
+}
+
+// ConsumeTx spends one power-up inside an existing transaction
+func (s *PowerUpService) ConsumeTx(tx *gorm.DB, userID uint, kind string) (int, error) {
+	column, ok := powerUpColumns[kind]
+	if !ok {
+		return 0, ErrUnknownPowerUp
+	}
+
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND "+column+" > 0", userID).
+		Update(column, gorm.Expr(column+" - 1"))
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to consume power-up: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrNoPowerUp
+	}
+
+	var remaining int
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Select(column).Scan(&remaining).Error; err != nil {
+		return 0, fmt.Errorf("failed to read power-up inventory: %w", err)
+	}
+
+	return remaining, nil
+}
+
+// Refund returns a power-up that was spent but couldn't be applied
+func (s *PowerUpService) Refund(userID uint, kind string) error {
+	column, ok := powerUpColumns[kind]
+	if !ok {
+		return ErrUnknownPowerUp
+	}
+
+	db := database.GetDB()
+	if err := db.Model(&models.User{}).
+		Where("id = ?", userID).
+		Update(column, gorm.Expr(column+" + 1")).Error; err != nil {
+		return fmt.Errorf("failed to refund power-up: %w", err)
+	}
+
+	return nil
+}
+
+// Inventory returns a user's power-up counts keyed by type
+func (s *PowerUpService) Inventory(userID uint) (map[string]int, error) {
+	db := database.GetDB()
+
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	return map[string]int{
+		PowerUpFiftyFifty: user.PowerUp5050,
+		PowerUpTimeFreeze: user.PowerUpTimeFreeze,
+		PowerUpHint:       user.PowerUpHint,
+		PowerUpSkip:       user.PowerUpSkip,
+		PowerUpDouble:     user.PowerUpDouble,
+	}, nil
+}
+
+// FiftyFifty picks two wrong options to remove
+func (s *PowerUpService) FiftyFifty(options []string, correctAnswer string, rng *rand.Rand) []string {
+	wrong := make([]string, 0, len(options))
+	for _, o := range options {
+		if o != correctAnswer {
+			wrong = append(wrong, o)
+		}
+	}
+
+	rng.Shuffle(len(wrong), func(i, j int) {
+		wrong[i], wrong[j] = wrong[j], wrong[i]
+	})
+
+	if len(wrong) > 2 {
+		wrong = wrong[:2]
+	}
+	return wrong
+}
+
+// Hint masks the correct answer, keeping its first word (or first letter for a
+// single word) and its punctuation, e.g. "John 3:16" -> "John _:__"
+func (s *PowerUpService) Hint(correctAnswer string) string {
+	answer := strings.TrimSpace(correctAnswer)
+	if answer == "" {
+		return ""
+	}
+
+	keep := strings.IndexFunc(answer, unicode.IsSpace)
+	if keep < 0 {
+		// Single word: reveal just the first character
+		keep = len(string([]rune(answer)[0]))
+	}
+
+	var b strings.Builder
+	b.WriteString(answer[:keep])
+	for _, r := range answer[keep:] {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune('_')
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Global instance
+var PowerUps = NewPowerUpService()
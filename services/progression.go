@@ -0,0 +1,250 @@
+// services/progression.go - XP, levels, Faith Points and streaks for every game mode
+package services
+
+import (
+	"fmt"
+	"log"
+	"ubible/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Game modes that report outcomes
+const (
+	ModeMultiplayer    = "multiplayer"
+	ModeSinglePlayer   = "single_player"
+	ModeTeamChallenge  = "team_challenge"
+	ModeDailyChallenge = "daily_challenge"
+)
+
+const (
+	MaxLevel = 100
+
+	perfectBonusXP = 100
+	perfectBonusFP = 50
+	winBonusXP     = 50
+	winBonusFP     = 25
+)
+
+// GameOutcome is a finished game as reported by any mode
+type GameOutcome struct {
+	Mode           string
+	Reference      string // Game, session or challenge ID - recorded in the FP ledger
+	ThemeID        uint   // 0 for mixed themes
+	Difficulty     string
+	Score          int
+	CorrectAnswers int
+	TotalQuestions int
+	TimeElapsed    int  // Seconds
+	Won            bool // Placed first, or passed a solo quiz
+}
+
+// IsPerfect reports whether every question was answered correctly
+func (o GameOutcome) IsPerfect() bool {
+	return o.TotalQuestions > 0 && o.CorrectAnswers == o.TotalQuestions
+}
+
+// RewardItem is one line of a reward breakdown, in the order the client should show them
+type RewardItem struct {
+	Type  string `json:"type"` // base, win_bonus, perfect_bonus, level_up
+	XP    int    `json:"xp,omitempty"`
+	FP    int    `json:"fp,omitempty"`
+	Level int    `json:"level,omitempty"` // New level for level_up
+}
+
+// RewardBreakdown is everything a finished game changed for a user
+type RewardBreakdown struct {
+	Mode          string       `json:"mode"`
+	AttemptID     uint         `json:"attempt_id"`
+	Items         []RewardItem `json:"items"`
+	XPEarned      int          `json:"xp_earned"`
+	FPEarned      int          `json:"fp_earned"` // Including level-up rewards
+	LevelBefore   int          `json:"level_before"`
+	LevelAfter    int          `json:"level_after"`
+	XPBefore      int          `json:"xp_before"`
+	XP            int          `json:"xp"`
+	XPToNextLevel int          `json:"xp_to_next_level"`
+	FaithPoints   int          `json:"faith_points"`
+	StreakBefore  int          `json:"streak_before"`
+	CurrentStreak int          `json:"current_streak"`
+	BestStreak    int          `json:"best_streak"`
+	IsPerfect     bool         `json:"is_perfect"`
+	Won           bool         `json:"won"`
+}
+
+// ProgressionService applies game outcomes to user progression
+type ProgressionService struct{}
+
+// NewProgressionService creates a new progression service
+func NewProgressionService() *ProgressionService {
+	return &ProgressionService{}
+}
+
+// XPForLevel returns the XP needed to advance from level to level+1
+func (s *ProgressionService) XPForLevel(level int) int {
+	return level * level * 100
+}
+
+// LevelReward returns the Faith Points granted on reaching level
+func (s *ProgressionService) LevelReward(level int) int {
+	return 50 + level*10
+}
+
+// GameRewards returns the XP and FP a game earns before level-up rewards, itemized
+func (s *ProgressionService) GameRewards(o GameOutcome) []RewardItem {
+	items := []RewardItem{{Type: "base", XP: o.Score / 10, FP: o.Score / 20}}
+	if o.Won {
+		items = append(items, RewardItem{Type: "win_bonus", XP: winBonusXP, FP: winBonusFP})
+	}
+	if o.IsPerfect() {
+		items = append(items, RewardItem{Type: "perfect_bonus", XP: perfectBonusXP, FP: perfectBonusFP})
+	}
+	return items
+}
+
+// RewardTotals sums the XP and FP of reward items
+func (s *ProgressionService) RewardTotals(items []RewardItem) (int, int) {
+	xp, fp := 0, 0
+	for _, item := range items {
+		xp += item.XP
+		fp += item.FP
+	}
+	return xp, fp
+}
+
+// Apply records the attempt and updates the user's stats, streak, XP, level and
+// Faith Points (with ledger entries) inside tx. The user row is locked so
+// concurrent results for the same user apply one after another.
+func (s *ProgressionService) Apply(tx *gorm.DB, userID uint, o GameOutcome) (*RewardBreakdown, error) {
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	b := &RewardBreakdown{
+		Mode:         o.Mode,
+		Items:        s.GameRewards(o),
+		LevelBefore:  user.Level,
+		XPBefore:     user.XP,
+		StreakBefore: user.CurrentStreak,
+		IsPerfect:    o.IsPerfect(),
+		Won:          o.Won,
+	}
+
+	var ledger []models.FaithPointTransaction
+	xp, gameFP := s.RewardTotals(b.Items)
+	b.XPEarned = xp
+	user.XP += b.XPEarned
+	user.FaithPoints += gameFP
+	ledger = append(ledger, models.FaithPointTransaction{
+		Amount: gameFP, BalanceAfter: user.FaithPoints, Reason: models.FPReasonGameReward,
+	})
+
+	// Stats and streak
+	user.TotalGames++
+	if o.Won {
+		user.Wins++
+		user.CurrentStreak++
+		if user.CurrentStreak > user.BestStreak {
+			user.BestStreak = user.CurrentStreak
+		}
+	} else {
+		user.Losses++
+		user.CurrentStreak = 0
+	}
+	if b.IsPerfect {
+		user.PerfectGames++
+	}
+
+	levelUps, levelLedger := s.levelUp(&user)
+	b.Items = append(b.Items, levelUps...)
+	ledger = append(ledger, levelLedger...)
+
+	if err := tx.Save(&user).Error; err != nil {
+		return nil, fmt.Errorf("failed to update user progression: %w", err)
+	}
+
+	for _, e := range ledger {
+		if err := FaithPoints.Record(tx, userID, e.Amount, e.BalanceAfter, e.Reason, o.Reference); err != nil {
+			return nil, err
+		}
+		b.FPEarned += e.Amount
+	}
+
+	attempt := models.Attempt{
+		UserID:         userID,
+		ThemeID:        o.ThemeID,
+		Score:          o.Score,
+		CorrectAnswers: o.CorrectAnswers,
+		TotalQuestions: o.TotalQuestions,
+		TimeElapsed:    o.TimeElapsed,
+		IsPerfect:      b.IsPerfect,
+		Difficulty:     o.Difficulty,
+		XPEarned:       b.XPEarned,
+		FPEarned:       gameFP,
+	}
+	if err := tx.Create(&attempt).Error; err != nil {
+		return nil, fmt.Errorf("failed to record attempt: %w", err)
+	}
+
+	if err := Seasons.Record(tx, userID, o, b.XPEarned); err != nil {
+		return nil, err
+	}
+
+	b.AttemptID = attempt.ID
+	b.LevelAfter = user.Level
+	b.XP = user.XP
+	b.XPToNextLevel = s.XPForLevel(user.Level)
+	b.FaithPoints = user.FaithPoints
+	b.CurrentStreak = user.CurrentStreak
+	b.BestStreak = user.BestStreak
+
+	return b, nil
+}
+
+// AddXP grants XP earned outside a game, such as an achievement reward, with
+// the same level-ups and level rewards as Apply. The user row is locked
+// inside tx; the returned items are the levels gained.
+func (s *ProgressionService) AddXP(tx *gorm.DB, userID uint, xp int, reference string) ([]RewardItem, error) {
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	user.XP += xp
+	items, ledger := s.levelUp(&user)
+
+	if err := tx.Model(&user).Select("xp", "level", "faith_points").Updates(&user).Error; err != nil {
+		return nil, fmt.Errorf("failed to update user progression: %w", err)
+	}
+	for _, e := range ledger {
+		if err := FaithPoints.Record(tx, userID, e.Amount, e.BalanceAfter, e.Reason, reference); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// levelUp spends the user's XP on as many levels as it covers, crediting each
+// level's Faith Point reward, and returns the levels gained with their ledger
+// entries
+func (s *ProgressionService) levelUp(user *models.User) ([]RewardItem, []models.FaithPointTransaction) {
+	var items []RewardItem
+	var ledger []models.FaithPointTransaction
+	for user.Level < MaxLevel && user.XP >= s.XPForLevel(user.Level) {
+		user.XP -= s.XPForLevel(user.Level)
+		user.Level++
+		reward := s.LevelReward(user.Level)
+		user.FaithPoints += reward
+		items = append(items, RewardItem{Type: "level_up", FP: reward, Level: user.Level})
+		ledger = append(ledger, models.FaithPointTransaction{
+			Amount: reward, BalanceAfter: user.FaithPoints, Reason: models.FPReasonLevelUp,
+		})
+		log.Printf("🎉 Player %s leveled up to %d!", user.Username, user.Level)
+	}
+	return items, ledger
+}
+
+// Global instance
+var Progression = NewProgressionService()
@@ -0,0 +1,75 @@
+// services/question_sets.go - Deterministic question sets for games and challenges
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"log"
+	"math/rand"
+	"ubible/models"
+)
+
+// SeededRand returns a random source derived from seed, so the same seed
+// always produces the same shuffles
+func SeededRand(seed string) *rand.Rand {
+	sum := sha256.Sum256([]byte(seed))
+	return rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(sum[:8]))))
+}
+
+// PickQuestions shuffles questions deterministically from seed and returns the
+// first count of them as playable question data
+func PickQuestions(questions []models.Question, count int, seed string) []models.QuestionData {
+	rng := SeededRand(seed)
+
+	rng.Shuffle(len(questions), func(i, j int) {
+		questions[i], questions[j] = questions[j], questions[i]
+	})
+	if len(questions) > count {
+		questions = questions[:count]
+	}
+
+	result := make([]models.QuestionData, 0, len(questions))
+	for _, q := range questions {
+		result = append(result, QuestionDataFrom(q, rng))
+	}
+	return result
+}
+
+// QuestionDataFrom builds a question's four options (the correct answer plus up
+// to three wrong ones) in an order drawn from rng
+func QuestionDataFrom(q models.Question, rng *rand.Rand) models.QuestionData {
+	var wrongAnswers []string
+	if q.WrongAnswers != "" {
+		if err := json.Unmarshal([]byte(q.WrongAnswers), &wrongAnswers); err != nil {
+			log.Printf("⚠️  Error unmarshaling wrong answers: %v", err)
+			wrongAnswers = []string{}
+		}
+	}
+
+	// Build 4 options (1 correct + 3 wrong)
+	options := make([]string, 0, 4)
+	options = append(options, q.CorrectAnswer)
+	for i := 0; i < 3 && i < len(wrongAnswers); i++ {
+		options = append(options, wrongAnswers[i])
+	}
+	rng.Shuffle(len(options), func(i, j int) {
+		options[i], options[j] = options[j], options[i]
+	})
+
+	themeName := q.ThemeName
+	if q.Theme != nil && q.Theme.ID != 0 {
+		themeName = q.Theme.Name
+	}
+
+	return models.QuestionData{
+		ID:            int(q.ID),
+		ThemeID:       q.ThemeID,
+		ThemeName:     themeName,
+		Text:          q.Text,
+		CorrectAnswer: q.CorrectAnswer,
+		Options:       options,
+		Reference:     q.Reference,
+		Difficulty:    q.Difficulty,
+	}
+}
@@ -0,0 +1,206 @@
+// services/rating.go - Glicko-2 ratings for multiplayer games
+package services
+
+import (
+	"math"
+	"sort"
+)
+
+// Glicko-2 constants (ratings are stored on the familiar Glicko scale)
+const (
+	DefaultRating     = 1500.0
+	DefaultDeviation  = 350.0
+	DefaultVolatility = 0.06
+
+	// A rating is provisional until the player has enough games or a settled deviation
+	ProvisionalGames     = 10
+	ProvisionalDeviation = 110.0
+
+	glickoScale   = 173.7178 // Converts between Glicko and Glicko-2 scales
+	glickoTau     = 0.5      // Constrains volatility change
+	glickoEpsilon = 0.000001 // Convergence tolerance for volatility iteration
+	minDeviation  = 30.0     // Floor so ratings never freeze completely
+	maxDeviation  = DefaultDeviation
+)
+
+// RatingParticipant is one player's pre-game rating and finishing position
+type RatingParticipant struct {
+	Key        string // Player ID in the game
+	Rating     float64
+	Deviation  float64
+	Volatility float64
+	Placement  int  // 1 = first; equal placements are ties
+	Rated      bool // false for guests - they count as opponents but aren't updated
+}
+
+// RatingResult is a player's post-game rating
+type RatingResult struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"rating_deviation"`
+	Volatility float64 `json:"rating_volatility"`
+	Change     float64 `json:"rating_change"`
+}
+
+// RatingService computes multiplayer rating updates
+type RatingService struct{}
+
+// NewRatingService creates a new rating service
+func NewRatingService() *RatingService {
+	return &RatingService{}
+}
+
+// IsProvisional reports whether a rating is still settling
+func (s *RatingService) IsProvisional(ratedGames int, deviation float64) bool {
+	return ratedGames < ProvisionalGames || deviation > ProvisionalDeviation
+}
+
+// RateGame treats a finished game as a Glicko-2 rating period in which every
+// player met every other player once: a better placement is a win, an equal
+// placement a draw. Returns results keyed by participant for rated players only.
+func (s *RatingService) RateGame(participants []RatingParticipant) map[string]RatingResult {
+	results := make(map[string]RatingResult)
+	if len(participants) < 2 {
+		return results
+	}
+
+	for i, p := range participants {
+		if !p.Rated {
+			continue
+		}
+
+		mu, phi, sigma := toGlicko2(p)
+
+		// Accumulate variance (v) and improvement (delta) over all opponents
+		var vInv, deltaSum float64
+		for j, o := range participants {
+			if i == j {
+				continue
+			}
+			muJ, phiJ, _ := toGlicko2(o)
+			g := glickoG(phiJ)
+			e := glickoE(mu, muJ, phiJ)
+			score := placementScore(p.Placement, o.Placement)
+
+			vInv += g * g * e * (1 - e)
+			deltaSum += g * (score - e)
+		}
+
+		v := 1 / vInv
+		delta := v * deltaSum
+
+		newSigma := newVolatility(phi, sigma, v, delta)
+		phiStar := math.Sqrt(phi*phi + newSigma*newSigma)
+		newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+		newMu := mu + newPhi*newPhi*deltaSum
+
+		rating := newMu*glickoScale + DefaultRating
+		deviation := math.Min(math.Max(newPhi*glickoScale, minDeviation), maxDeviation)
+
+		results[p.Key] = RatingResult{
+			Rating:     rating,
+			Deviation:  deviation,
+			Volatility: newSigma,
+			Change:     rating - (mu*glickoScale + DefaultRating), // From the default when p.Rating is unset
+		}
+	}
+
+	return results
+}
+
+// RankPlacements turns scores into 1-based placements, giving ties the same place
+func RankPlacements(scores map[string]int, tiebreak map[string]int) map[string]int {
+	keys := make([]string, 0, len(scores))
+	for k := range scores {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if scores[keys[i]] != scores[keys[j]] {
+			return scores[keys[i]] > scores[keys[j]]
+		}
+		return tiebreak[keys[i]] > tiebreak[keys[j]]
+	})
+
+	placements := make(map[string]int, len(keys))
+	for i, k := range keys {
+		if i > 0 && scores[k] == scores[keys[i-1]] && tiebreak[k] == tiebreak[keys[i-1]] {
+			placements[k] = placements[keys[i-1]]
+		} else {
+			placements[k] = i + 1
+		}
+	}
+	return placements
+}
+
+func toGlicko2(p RatingParticipant) (mu, phi, sigma float64) {
+	rating, deviation, volatility := p.Rating, p.Deviation, p.Volatility
+	if rating == 0 {
+		rating = DefaultRating
+	}
+	if deviation <= 0 {
+		deviation = DefaultDeviation
+	}
+	if volatility <= 0 {
+		volatility = DefaultVolatility
+	}
+	return (rating - DefaultRating) / glickoScale, deviation / glickoScale, volatility
+}
+
+func placementScore(mine, theirs int) float64 {
+	switch {
+	case mine < theirs:
+		return 1
+	case mine == theirs:
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+func glickoG(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func glickoE(mu, muJ, phiJ float64) float64 {
+	return 1 / (1 + math.Exp(-glickoG(phiJ)*(mu-muJ)))
+}
+
+// newVolatility solves for the new volatility using the Illinois algorithm
+// (step 5 of Glickman's Glicko-2 paper)
+func newVolatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		num := ex * (delta*delta - phi*phi - v - ex)
+		den := 2 * math.Pow(phi*phi+v+ex, 2)
+		return num/den - (x-a)/(glickoTau*glickoTau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*glickoTau) < 0 {
+			k++
+		}
+		B = a - k*glickoTau
+	}
+
+	fA, fB := f(A), f(B)
+	for i := 0; math.Abs(B-A) > glickoEpsilon && i < 100; i++ {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
+
+// Global instance
+var Ratings = NewRatingService()
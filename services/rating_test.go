@@ -0,0 +1,171 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRankPlacements(t *testing.T) {
+	tests := []struct {
+		name     string
+		scores   map[string]int
+		tiebreak map[string]int
+		want     map[string]int
+	}{
+		{
+			name:   "distinct scores",
+			scores: map[string]int{"a": 300, "b": 500, "c": 100},
+			want:   map[string]int{"b": 1, "a": 2, "c": 3},
+		},
+		{
+			name:   "tie shares a place and skips the next",
+			scores: map[string]int{"a": 500, "b": 500, "c": 100},
+			want:   map[string]int{"a": 1, "b": 1, "c": 3},
+		},
+		{
+			name:     "tiebreak separates equal scores",
+			scores:   map[string]int{"a": 500, "b": 500, "c": 100},
+			tiebreak: map[string]int{"a": 4, "b": 5},
+			want:     map[string]int{"b": 1, "a": 2, "c": 3},
+		},
+		{
+			name:     "equal tiebreak is still a tie",
+			scores:   map[string]int{"a": 200, "b": 200, "c": 200},
+			tiebreak: map[string]int{"a": 3, "b": 3, "c": 1},
+			want:     map[string]int{"a": 1, "b": 1, "c": 3},
+		},
+		{
+			name:   "single player",
+			scores: map[string]int{"a": 0},
+			want:   map[string]int{"a": 1},
+		},
+	}
+	for _, tt := range tests {
+		got := RankPlacements(tt.scores, tt.tiebreak)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for k, place := range tt.want {
+			if got[k] != place {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+// The worked example from Glickman's "Example of the Glicko-2 system": a
+// 1500/200 player beats a 1400/30 player and loses to 1550/100 and 1700/300
+func TestRateGameGlickmanExample(t *testing.T) {
+	results := NewRatingService().RateGame([]RatingParticipant{
+		{Key: "p", Rating: 1500, Deviation: 200, Volatility: 0.06, Placement: 2, Rated: true},
+		{Key: "a", Rating: 1400, Deviation: 30, Placement: 3},
+		{Key: "b", Rating: 1550, Deviation: 100, Placement: 1},
+		{Key: "c", Rating: 1700, Deviation: 300, Placement: 1},
+	})
+
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want only the rated player's", len(results))
+	}
+	r := results["p"]
+	if math.Abs(r.Rating-1464.06) > 0.05 {
+		t.Errorf("rating = %.2f, want 1464.06", r.Rating)
+	}
+	if math.Abs(r.Deviation-151.52) > 0.05 {
+		t.Errorf("deviation = %.2f, want 151.52", r.Deviation)
+	}
+	if math.Abs(r.Volatility-0.05999) > 0.00001 {
+		t.Errorf("volatility = %.5f, want 0.05999", r.Volatility)
+	}
+	if math.Abs(r.Change-(r.Rating-1500)) > 1e-9 {
+		t.Errorf("change = %.2f, want %.2f", r.Change, r.Rating-1500)
+	}
+}
+
+func TestRateGame(t *testing.T) {
+	rs := NewRatingService()
+
+	tests := []struct {
+		name         string
+		participants []RatingParticipant
+		check        func(t *testing.T, results map[string]RatingResult)
+	}{
+		{
+			name:         "one player isn't rated",
+			participants: []RatingParticipant{{Key: "a", Placement: 1, Rated: true}},
+			check: func(t *testing.T, results map[string]RatingResult) {
+				if len(results) != 0 {
+					t.Errorf("got %v, want no results", results)
+				}
+			},
+		},
+		{
+			name: "winner gains what an equal loser drops",
+			participants: []RatingParticipant{
+				{Key: "a", Placement: 1, Rated: true},
+				{Key: "b", Placement: 2, Rated: true},
+			},
+			check: func(t *testing.T, results map[string]RatingResult) {
+				a, b := results["a"], results["b"]
+				if a.Change <= 0 || b.Change >= 0 {
+					t.Errorf("changes = %.2f, %.2f, want a up and b down", a.Change, b.Change)
+				}
+				if math.Abs(a.Change+b.Change) > 1e-6 {
+					t.Errorf("changes = %.2f, %.2f, want equal and opposite", a.Change, b.Change)
+				}
+				if a.Deviation >= DefaultDeviation {
+					t.Errorf("deviation = %.2f, want below %.0f after a game", a.Deviation, DefaultDeviation)
+				}
+			},
+		},
+		{
+			name: "a tie between equals changes nothing",
+			participants: []RatingParticipant{
+				{Key: "a", Rating: 1600, Deviation: 80, Placement: 1, Rated: true},
+				{Key: "b", Rating: 1600, Deviation: 80, Placement: 1, Rated: true},
+			},
+			check: func(t *testing.T, results map[string]RatingResult) {
+				for k, r := range results {
+					if math.Abs(r.Change) > 1e-6 {
+						t.Errorf("%s change = %.4f, want 0", k, r.Change)
+					}
+				}
+			},
+		},
+		{
+			name: "guests count as opponents but aren't updated",
+			participants: []RatingParticipant{
+				{Key: "guest", Placement: 1},
+				{Key: "a", Placement: 2, Rated: true},
+			},
+			check: func(t *testing.T, results map[string]RatingResult) {
+				if _, ok := results["guest"]; ok {
+					t.Error("guest was rated")
+				}
+				if results["a"].Change >= 0 {
+					t.Errorf("change = %.2f, want a loss to the guest to cost rating", results["a"].Change)
+				}
+			},
+		},
+		{
+			name: "deviation never drops below the floor",
+			participants: []RatingParticipant{
+				{Key: "a", Rating: 1500, Deviation: minDeviation, Volatility: 0.01, Placement: 1, Rated: true},
+				{Key: "b", Rating: 1500, Deviation: minDeviation, Volatility: 0.01, Placement: 2, Rated: true},
+			},
+			check: func(t *testing.T, results map[string]RatingResult) {
+				for k, r := range results {
+					if r.Deviation < minDeviation {
+						t.Errorf("%s deviation = %.2f, want at least %.0f", k, r.Deviation, minDeviation)
+					}
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, rs.RateGame(tt.participants))
+		})
+	}
+}
@@ -0,0 +1,400 @@
+// services/replays.go - Question-by-question replays rebuilt from the multiplayer event log
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"ubible/database"
+	"ubible/models"
+
+	"gorm.io/gorm"
+)
+
+// ReplayFastAnswer is the response time under which a correct answer is
+// counted as fast in a replay's player summary
+const ReplayFastAnswer = 1000 * time.Millisecond
+
+var (
+	ErrReplayNotFound   = errors.New("game not found")
+	ErrReplayForbidden  = errors.New("you didn't play in this game")
+	ErrReplayInProgress = errors.New("game is still in progress")
+)
+
+// ReplayAnswer is one player's answer to one question
+type ReplayAnswer struct {
+	PlayerID       string   `json:"player_id"`
+	Username       string   `json:"username"`
+	Answered       bool     `json:"answered"` // False when the clock ran out first
+	Answer         string   `json:"answer,omitempty"`
+	Correct        bool     `json:"correct"`
+	PointsEarned   int      `json:"points_earned"`
+	ResponseTimeMs int64    `json:"response_time_ms,omitempty"`
+	Score          int      `json:"score"`           // Running total after this question
+	CorrectAnswers int      `json:"correct_answers"` // Running correct count after this question
+	Skipped        bool     `json:"skipped,omitempty"`
+	Doubled        bool     `json:"doubled,omitempty"`
+	PowerUps       []string `json:"powerups,omitempty"` // Power-ups used on this question
+}
+
+// ReplayQuestion is one question of a replay with every player's answer
+type ReplayQuestion struct {
+	Index         int            `json:"index"`
+	QuestionID    int            `json:"question_id"`
+	ThemeName     string         `json:"theme_name,omitempty"`
+	Text          string         `json:"text"`
+	Options       []string       `json:"options"`
+	CorrectAnswer string         `json:"correct_answer"`
+	Reference     string         `json:"reference"`
+	CloseReason   string         `json:"close_reason,omitempty"` // all_answered or timeout; empty if the game ended mid-question
+	ClosedAt      *time.Time     `json:"closed_at,omitempty"`
+	Answers       []ReplayAnswer `json:"answers"`
+
+	answered map[string]int // playerID → index in Answers
+}
+
+// ReplayPlayer summarises one player's game
+type ReplayPlayer struct {
+	PlayerID       string     `json:"player_id"`
+	Username       string     `json:"username"`
+	UserID         *uint      `json:"user_id"`
+	IsGuest        bool       `json:"is_guest"`
+	IsHost         bool       `json:"is_host"`
+	IsPlaying      bool       `json:"is_playing"`
+	FinalScore     int        `json:"final_score"`
+	CorrectAnswers int        `json:"correct_answers"`
+	Placement      int        `json:"placement"`
+	RatingChange   float64    `json:"rating_change"`
+	Answered       int        `json:"answered"`
+	AvgResponseMs  int64      `json:"avg_response_ms"`
+	FastCorrect    int        `json:"fast_correct"` // Correct answers faster than ReplayFastAnswer
+	PowerUpsUsed   int        `json:"powerups_used"`
+	Disconnects    int        `json:"disconnects"`
+	LeftAt         *time.Time `json:"left_at"`
+}
+
+// ReplayEvent is a game-level event shown on a replay's timeline
+type ReplayEvent struct {
+	Sequence      int64                  `json:"sequence"`
+	Type          string                 `json:"type"`
+	PlayerID      string                 `json:"player_id,omitempty"`
+	Username      string                 `json:"username,omitempty"`
+	QuestionIndex *int                   `json:"question_index,omitempty"`
+	Timestamp     time.Time              `json:"timestamp"`
+	Data          map[string]interface{} `json:"data,omitempty"`
+}
+
+// Replay is a multiplayer game rebuilt question by question from its event log
+type Replay struct {
+	Game      models.MultiplayerGame `json:"game"`
+	Players   []ReplayPlayer         `json:"players"`
+	Questions []ReplayQuestion       `json:"questions"`
+	Timeline  []ReplayEvent          `json:"timeline"` // Joins, leaves, disconnects, power-ups...
+	WinnerID  string                 `json:"winner_id,omitempty"`
+	Complete  bool                   `json:"complete"` // The log runs through game_completed
+}
+
+// ReplayService rebuilds finished multiplayer games from their event log
+type ReplayService struct{}
+
+// NewReplayService creates a new replay service
+func NewReplayService() *ReplayService {
+	return &ReplayService{}
+}
+
+// Game loads a game's tracking row by its game ID
+func (s *ReplayService) Game(gameID string) (*models.MultiplayerGame, error) {
+	var game models.MultiplayerGame
+	if err := database.GetDB().Where("game_id = ?", gameID).First(&game).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReplayNotFound
+		}
+		return nil, err
+	}
+	return &game, nil
+}
+
+// ForPlayer rebuilds a game for one of the users who played in it. Games
+// still waiting or playing can't be replayed this way.
+func (s *ReplayService) ForPlayer(gameID string, userID uint) (*Replay, error) {
+	game, err := s.Game(gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	database.GetDB().Model(&models.MultiplayerGamePlayer{}).
+		Where("game_id = ? AND user_id = ?", game.ID, userID).Count(&count)
+	if count == 0 {
+		return nil, ErrReplayForbidden
+	}
+	if game.IsActive() {
+		return nil, ErrReplayInProgress
+	}
+
+	return s.build(*game)
+}
+
+// Build rebuilds any stored game, whatever its status
+func (s *ReplayService) Build(gameID string) (*Replay, error) {
+	game, err := s.Game(gameID)
+	if err != nil {
+		return nil, err
+	}
+	return s.build(*game)
+}
+
+func (s *ReplayService) build(game models.MultiplayerGame) (*Replay, error) {
+	db := database.GetDB()
+
+	var rows []models.MultiplayerGamePlayer
+	if err := db.Where("game_id = ?", game.ID).Order("joined_at ASC, id ASC").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load players: %w", err)
+	}
+	var events []models.MultiplayerGameEvent
+	if err := db.Where("game_id = ?", game.ID).Order("sequence_num ASC, timestamp ASC").Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to load events: %w", err)
+	}
+
+	replay := &Replay{
+		Game:      game,
+		Players:   make([]ReplayPlayer, 0, len(rows)),
+		Questions: []ReplayQuestion{},
+		Timeline:  []ReplayEvent{},
+	}
+	players := make(map[string]*ReplayPlayer, len(rows))
+	for _, row := range rows {
+		replay.Players = append(replay.Players, ReplayPlayer{
+			PlayerID:       row.PlayerID,
+			Username:       row.Username,
+			UserID:         row.UserID,
+			IsGuest:        row.IsGuest,
+			IsHost:         row.IsHost,
+			IsPlaying:      row.IsPlaying,
+			FinalScore:     row.FinalScore,
+			CorrectAnswers: row.CorrectAnswers,
+			Placement:      row.Placement,
+			RatingChange:   row.RatingChange,
+			LeftAt:         row.LeftAt,
+		})
+	}
+	for i := range replay.Players {
+		players[replay.Players[i].PlayerID] = &replay.Players[i]
+	}
+	username := func(playerID string) string {
+		if p, ok := players[playerID]; ok {
+			return p.Username
+		}
+		return ""
+	}
+
+	for _, e := range events {
+		switch e.EventType {
+		case "game_started":
+			var data struct {
+				Questions []models.QuestionData `json:"questions"`
+			}
+			if !decodeReplayEvent(e, &data) {
+				continue
+			}
+			for i, q := range data.Questions {
+				question := replay.question(i)
+				question.QuestionID = q.ID
+				question.ThemeName = q.ThemeName
+				question.Text = q.Text
+				question.Options = q.Options
+				question.CorrectAnswer = q.CorrectAnswer
+				question.Reference = q.Reference
+			}
+			replay.Timeline = append(replay.Timeline, ReplayEvent{
+				Sequence:  e.SequenceNum,
+				Type:      e.EventType,
+				Timestamp: e.Timestamp,
+				Data:      map[string]interface{}{"question_count": len(data.Questions)},
+			})
+
+		case "answer_submitted":
+			var data struct {
+				QuestionID     int    `json:"question_id"`
+				Answer         string `json:"answer"`
+				Correct        bool   `json:"correct"`
+				PointsEarned   int    `json:"points_earned"`
+				ResponseTimeMs int64  `json:"response_time_ms"`
+				Score          int    `json:"score"`
+				CorrectAnswers int    `json:"correct_answers"`
+				Skipped        bool   `json:"skipped"`
+				Doubled        bool   `json:"doubled"`
+			}
+			if e.QuestionIndex == nil || !decodeReplayEvent(e, &data) {
+				continue
+			}
+			question := replay.question(*e.QuestionIndex)
+			if question.QuestionID == 0 {
+				question.QuestionID = data.QuestionID
+			}
+			answer := question.answer(e.PlayerID, username(e.PlayerID))
+			answer.Answered = true
+			answer.Answer = data.Answer
+			answer.Correct = data.Correct
+			answer.PointsEarned = data.PointsEarned
+			answer.ResponseTimeMs = data.ResponseTimeMs
+			answer.Score = data.Score
+			answer.CorrectAnswers = data.CorrectAnswers
+			answer.Skipped = data.Skipped
+			answer.Doubled = data.Doubled
+
+		case "question_closed":
+			var data struct {
+				Reason  string `json:"reason"`
+				Results []struct {
+					PlayerID       string `json:"player_id"`
+					Username       string `json:"username"`
+					Answer         string `json:"answer"`
+					Correct        bool   `json:"correct"`
+					PointsEarned   int    `json:"points_earned"`
+					ResponseTimeMs int64  `json:"response_time_ms"`
+					Score          int    `json:"score"`
+					CorrectAnswers int    `json:"correct_answers"`
+					Skipped        bool   `json:"skipped"`
+					Doubled        bool   `json:"doubled"`
+				} `json:"results"`
+			}
+			if e.QuestionIndex == nil || !decodeReplayEvent(e, &data) {
+				continue
+			}
+			question := replay.question(*e.QuestionIndex)
+			question.CloseReason = data.Reason
+			closedAt := e.Timestamp
+			question.ClosedAt = &closedAt
+			// Players who ran out of time, or whose answer event was
+			// dropped, only appear in the close
+			for _, r := range data.Results {
+				if a, ok := question.answered[r.PlayerID]; ok && question.Answers[a].Answered {
+					continue
+				}
+				answer := question.answer(r.PlayerID, r.Username)
+				answer.Answered = r.Answer != "" || r.Skipped || r.ResponseTimeMs > 0
+				answer.Answer = r.Answer
+				answer.Correct = r.Correct
+				answer.PointsEarned = r.PointsEarned
+				answer.ResponseTimeMs = r.ResponseTimeMs
+				answer.Score = r.Score
+				answer.CorrectAnswers = r.CorrectAnswers
+				answer.Skipped = r.Skipped
+				answer.Doubled = r.Doubled
+			}
+
+		case "powerup_used":
+			var data struct {
+				Type string `json:"type"`
+			}
+			if !decodeReplayEvent(e, &data) {
+				continue
+			}
+			if e.QuestionIndex != nil {
+				answer := replay.question(*e.QuestionIndex).answer(e.PlayerID, username(e.PlayerID))
+				answer.PowerUps = append(answer.PowerUps, data.Type)
+			}
+			if p, ok := players[e.PlayerID]; ok {
+				p.PowerUpsUsed++
+			}
+			replay.Timeline = append(replay.Timeline, replayEvent(e, username(e.PlayerID)))
+
+		case "game_completed":
+			var data struct {
+				WinnerID string `json:"winner_id"`
+			}
+			decodeReplayEvent(e, &data)
+			replay.WinnerID = data.WinnerID
+			replay.Complete = true
+			replay.Timeline = append(replay.Timeline, replayEvent(e, ""))
+
+		case "player_disconnected":
+			if p, ok := players[e.PlayerID]; ok {
+				p.Disconnects++
+			}
+			replay.Timeline = append(replay.Timeline, replayEvent(e, username(e.PlayerID)))
+
+		default:
+			replay.Timeline = append(replay.Timeline, replayEvent(e, username(e.PlayerID)))
+		}
+	}
+
+	// Per-player response summaries
+	responseTotal := make(map[string]int64)
+	for _, q := range replay.Questions {
+		for _, a := range q.Answers {
+			p, ok := players[a.PlayerID]
+			if !ok || !a.Answered || a.Skipped {
+				continue
+			}
+			p.Answered++
+			responseTotal[a.PlayerID] += a.ResponseTimeMs
+			if a.Correct && a.ResponseTimeMs < ReplayFastAnswer.Milliseconds() {
+				p.FastCorrect++
+			}
+		}
+	}
+	for i := range replay.Players {
+		p := &replay.Players[i]
+		if p.Answered > 0 {
+			p.AvgResponseMs = responseTotal[p.PlayerID] / int64(p.Answered)
+		}
+	}
+
+	return replay, nil
+}
+
+// question returns the replay's question at index, adding empty questions up to it
+func (r *Replay) question(index int) *ReplayQuestion {
+	for len(r.Questions) <= index {
+		r.Questions = append(r.Questions, ReplayQuestion{
+			Index:    len(r.Questions),
+			Options:  []string{},
+			Answers:  []ReplayAnswer{},
+			answered: make(map[string]int),
+		})
+	}
+	return &r.Questions[index]
+}
+
+// answer returns the player's answer to the question, adding it if missing
+func (q *ReplayQuestion) answer(playerID, username string) *ReplayAnswer {
+	if i, ok := q.answered[playerID]; ok {
+		return &q.Answers[i]
+	}
+	q.answered[playerID] = len(q.Answers)
+	q.Answers = append(q.Answers, ReplayAnswer{PlayerID: playerID, Username: username})
+	return &q.Answers[len(q.Answers)-1]
+}
+
+// replayEvent turns a logged event into a timeline entry
+func replayEvent(e models.MultiplayerGameEvent, username string) ReplayEvent {
+	event := ReplayEvent{
+		Sequence:      e.SequenceNum,
+		Type:          e.EventType,
+		PlayerID:      e.PlayerID,
+		Username:      username,
+		QuestionIndex: e.QuestionIndex,
+		Timestamp:     e.Timestamp,
+	}
+	decodeReplayEvent(e, &event.Data)
+	return event
+}
+
+// decodeReplayEvent unmarshals an event's data, logging events it can't read
+func decodeReplayEvent(e models.MultiplayerGameEvent, v interface{}) bool {
+	if e.EventData == "" {
+		return true
+	}
+	if err := json.Unmarshal([]byte(e.EventData), v); err != nil {
+		log.Printf("⚠️  Replay skipped unreadable %s event %d: %v", e.EventType, e.ID, err)
+		return false
+	}
+	return true
+}
+
+// Global instance
+var Replays = NewReplayService()
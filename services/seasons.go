@@ -0,0 +1,474 @@
+// services/seasons.go - Competitive seasons: rollover, reward payout and rating soft-reset
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"ubible/database"
+	"ubible/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	SeasonTickInterval = time.Minute
+	SeasonLengthMonths = 3
+
+	// At rollover ratings keep this share of their distance from the mean, and
+	// deviations are raised to at least SeasonResetDeviation so they resettle
+	SeasonResetFactor    = 0.5
+	SeasonResetDeviation = 200.0
+
+	SeasonMinGames      = 5  // Games needed for any reward
+	SeasonMinRatedGames = 5  // Multiplayer games needed to be ranked by rating
+	SeasonLegendPlaces  = 10 // Top rated places that earn the legend tier
+)
+
+var ErrSeasonNotFound = errors.New("season not found")
+
+// SeasonTier is an end-of-season reward band
+type SeasonTier struct {
+	Name      string  `json:"name"`
+	MinRating float64 `json:"min_rating"` // Ignored for legend (placement) and bronze (participation)
+	FPReward  int     `json:"fp_reward"`
+	Icon      string  `json:"icon"`
+}
+
+// SeasonTiers are the reward bands, best first
+var SeasonTiers = []SeasonTier{
+	{Name: models.SeasonTierLegend, FPReward: 1000, Icon: "👑"},
+	{Name: models.SeasonTierDiamond, MinRating: 1900, FPReward: 500, Icon: "💎"},
+	{Name: models.SeasonTierGold, MinRating: 1700, FPReward: 250, Icon: "🥇"},
+	{Name: models.SeasonTierSilver, MinRating: 1550, FPReward: 100, Icon: "🥈"},
+	{Name: models.SeasonTierBronze, FPReward: 50, Icon: "🥉"},
+}
+
+// SeasonService runs season rollover and records season-scoped stats
+type SeasonService struct {
+	mu     sync.RWMutex
+	active *models.Season
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewSeasonService creates a new season service
+func NewSeasonService() *SeasonService {
+	return &SeasonService{stop: make(chan struct{})}
+}
+
+// Start opens the first season if there is none and closes seasons as they
+// end, checked every SeasonTickInterval
+func (s *SeasonService) Start() {
+	go func() {
+		ticker := time.NewTicker(SeasonTickInterval)
+		defer ticker.Stop()
+
+		s.Tick(time.Now())
+		for {
+			select {
+			case now := <-ticker.C:
+				s.Tick(now)
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	log.Printf("🏁 Season scheduler started (every %s)", SeasonTickInterval)
+}
+
+// Stop ends the scheduler
+func (s *SeasonService) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// Tick closes the active season once it has ended, which opens the next one
+func (s *SeasonService) Tick(now time.Time) {
+	db := database.GetDB()
+	if db == nil {
+		return
+	}
+
+	season, err := s.ensureActive(now)
+	if err != nil {
+		log.Printf("⚠️  Failed to load active season: %v", err)
+		return
+	}
+	if now.Before(season.EndsAt) {
+		return
+	}
+
+	if err := s.Close(season.ID, now); err != nil {
+		log.Printf("⚠️  Failed to close season %d: %v", season.Number, err)
+	}
+}
+
+// Active returns the current season, or nil before the scheduler has run
+func (s *SeasonService) Active() *models.Season {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.active == nil {
+		return nil
+	}
+	season := *s.active
+	return &season
+}
+
+// ensureActive loads the active season, opening one for the current quarter
+// if none exists yet
+func (s *SeasonService) ensureActive(now time.Time) (*models.Season, error) {
+	db := database.GetDB()
+
+	var season models.Season
+	err := db.Where("status = ?", models.SeasonStatusActive).Order("number DESC").First(&season).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var last models.Season
+		start := seasonQuarterStart(now)
+		if db.Order("number DESC").First(&last).Error == nil {
+			start = last.EndsAt
+		}
+		season, err = s.open(db, last.Number+1, start, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.active = &season
+	s.mu.Unlock()
+	return &season, nil
+}
+
+// open creates season number starting at start and ending at the first
+// SeasonLengthMonths boundary after now
+func (s *SeasonService) open(tx *gorm.DB, number int, start, now time.Time) (models.Season, error) {
+	end := start.AddDate(0, SeasonLengthMonths, 0)
+	for !end.After(now) {
+		end = end.AddDate(0, SeasonLengthMonths, 0)
+	}
+
+	season := models.Season{
+		Number:   number,
+		Name:     fmt.Sprintf("Season %d", number),
+		StartsAt: start,
+		EndsAt:   end,
+		Status:   models.SeasonStatusActive,
+	}
+	if err := tx.Create(&season).Error; err != nil {
+		return season, fmt.Errorf("failed to open season %d: %w", number, err)
+	}
+
+	log.Printf("🗓️  %s opened (%s - %s)", season.Name, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	return season, nil
+}
+
+// Record adds a finished game to the user's stats for the active season.
+// Games finished after the season ended but before it closed aren't counted.
+func (s *SeasonService) Record(tx *gorm.DB, userID uint, o GameOutcome, xpEarned int) error {
+	season := s.Active()
+	if season == nil || !time.Now().Before(season.EndsAt) {
+		return nil
+	}
+
+	stat := models.SeasonStat{SeasonID: season.ID, UserID: userID}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&stat).Error; err != nil {
+		return fmt.Errorf("failed to create season stats: %w", err)
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("season_id = ? AND user_id = ?", season.ID, userID).First(&stat).Error; err != nil {
+		return fmt.Errorf("failed to load season stats: %w", err)
+	}
+
+	stat.Games++
+	if o.Mode == ModeMultiplayer {
+		stat.RatedGames++
+	}
+	stat.Score += o.Score
+	stat.XPEarned += xpEarned
+	if o.IsPerfect() {
+		stat.PerfectGames++
+	}
+	if o.Won {
+		stat.Wins++
+		stat.CurrentStreak++
+		if stat.CurrentStreak > stat.BestStreak {
+			stat.BestStreak = stat.CurrentStreak
+		}
+	} else {
+		stat.CurrentStreak = 0
+	}
+
+	if err := tx.Save(&stat).Error; err != nil {
+		return fmt.Errorf("failed to update season stats: %w", err)
+	}
+	return nil
+}
+
+// Close archives a season's final standings, pays out tier rewards, soft-resets
+// every rating toward the mean and opens the next season, all in one
+// transaction. Closing a season that is already closed does nothing.
+func (s *SeasonService) Close(seasonID uint, now time.Time) error {
+	db := database.GetDB()
+
+	var season, next models.Season
+	var standings []models.SeasonStanding
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", seasonID, models.SeasonStatusActive).First(&season).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		var err error
+		if standings, err = s.finalStandings(tx, season); err != nil {
+			return err
+		}
+		if len(standings) > 0 {
+			if err := tx.CreateInBatches(&standings, 500).Error; err != nil {
+				return fmt.Errorf("failed to archive standings: %w", err)
+			}
+		}
+		if err := s.payRewards(tx, season, standings); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.User{}).Where("is_guest = ?", false).Updates(map[string]interface{}{
+			"rating":           gorm.Expr("? + (rating - ?) * ?", DefaultRating, DefaultRating, SeasonResetFactor),
+			"rating_deviation": gorm.Expr("GREATEST(rating_deviation, ?)", SeasonResetDeviation),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to reset ratings: %w", err)
+		}
+
+		if err := tx.Model(&season).Updates(map[string]interface{}{
+			"status":    models.SeasonStatusClosed,
+			"closed_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		next, err = s.open(tx, season.Number+1, season.EndsAt, now)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if next.ID == 0 {
+		return nil // Already closed
+	}
+
+	s.mu.Lock()
+	s.active = &next
+	s.mu.Unlock()
+	Leaderboards.Invalidate()
+
+	for _, standing := range standings {
+		if standing.Tier == "" {
+			continue
+		}
+		Notifications.Notify(standing.UserID, models.NotificationCategoryProgress, "season_reward",
+			fmt.Sprintf("%s finished: %s tier", season.Name, standing.Tier),
+			fmt.Sprintf("You placed #%d and earned %d Faith Points", standing.Rank, standing.FPReward),
+			map[string]interface{}{
+				"season":    season.Number,
+				"rank":      standing.Rank,
+				"tier":      standing.Tier,
+				"fp_reward": standing.FPReward,
+			})
+	}
+
+	log.Printf("🏆 %s closed: %d players archived", season.Name, len(standings))
+	return nil
+}
+
+// finalStandings ranks a season's players: those with enough multiplayer
+// games by rating, then everyone else by season XP, and assigns reward tiers
+func (s *SeasonService) finalStandings(tx *gorm.DB, season models.Season) ([]models.SeasonStanding, error) {
+	type row struct {
+		models.SeasonStat
+		Rating float64
+	}
+	var rows []row
+	if err := tx.Table("season_stats").
+		Select("season_stats.*, users.rating").
+		Joins("JOIN users ON users.id = season_stats.user_id").
+		Where("season_stats.season_id = ? AND users.is_guest = ?", season.ID, false).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load season stats: %w", err)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		aRated, bRated := a.RatedGames >= SeasonMinRatedGames, b.RatedGames >= SeasonMinRatedGames
+		if aRated != bRated {
+			return aRated
+		}
+		if aRated && a.Rating != b.Rating {
+			return a.Rating > b.Rating
+		}
+		if a.XPEarned != b.XPEarned {
+			return a.XPEarned > b.XPEarned
+		}
+		return a.UserID < b.UserID
+	})
+
+	standings := make([]models.SeasonStanding, len(rows))
+	for i, r := range rows {
+		standing := models.SeasonStanding{
+			SeasonID:     season.ID,
+			UserID:       r.UserID,
+			Rank:         i + 1,
+			Rating:       r.Rating,
+			Games:        r.Games,
+			RatedGames:   r.RatedGames,
+			Wins:         r.Wins,
+			PerfectGames: r.PerfectGames,
+			Score:        r.Score,
+			XPEarned:     r.XPEarned,
+			BestStreak:   r.BestStreak,
+		}
+		if tier := s.TierFor(standing); tier != nil {
+			standing.Tier = tier.Name
+			standing.FPReward = tier.FPReward
+		}
+		standings[i] = standing
+	}
+	return standings, nil
+}
+
+// TierFor returns the reward band a final standing earned, or nil
+func (s *SeasonService) TierFor(standing models.SeasonStanding) *SeasonTier {
+	if standing.Games < SeasonMinGames {
+		return nil
+	}
+	for i, tier := range SeasonTiers {
+		switch {
+		case tier.Name == models.SeasonTierBronze:
+			return &SeasonTiers[i]
+		case standing.RatedGames < SeasonMinRatedGames:
+			continue
+		case tier.Name == models.SeasonTierLegend:
+			if standing.Rank <= SeasonLegendPlaces {
+				return &SeasonTiers[i]
+			}
+		case standing.Rating >= tier.MinRating:
+			return &SeasonTiers[i]
+		}
+	}
+	return nil
+}
+
+// payRewards credits each standing's Faith Points and grants the season's
+// tier badge, an achievement created on first use
+func (s *SeasonService) payRewards(tx *gorm.DB, season models.Season, standings []models.SeasonStanding) error {
+	reference := fmt.Sprintf("season:%d", season.Number)
+	badges := make(map[string]uint)
+
+	for _, standing := range standings {
+		if standing.Tier == "" {
+			continue
+		}
+
+		if _, err := FaithPoints.Adjust(tx, standing.UserID, standing.FPReward, models.FPReasonSeasonReward, reference); err != nil {
+			return err
+		}
+
+		badgeID, ok := badges[standing.Tier]
+		if !ok {
+			var tier SeasonTier
+			for _, t := range SeasonTiers {
+				if t.Name == standing.Tier {
+					tier = t
+				}
+			}
+			tierName := strings.ToUpper(tier.Name[:1]) + tier.Name[1:]
+			badge := models.Achievement{
+				Name:        fmt.Sprintf("%s %s", season.Name, tierName),
+				Description: fmt.Sprintf("Finished %s in the %s tier", season.Name, tierName),
+				Category:    "Season",
+				Tier:        tierName,
+				Icon:        tier.Icon,
+			}
+			if err := tx.Where("name = ?", badge.Name).FirstOrCreate(&badge).Error; err != nil {
+				return fmt.Errorf("failed to create season badge: %w", err)
+			}
+			badgeID = badge.ID
+			badges[standing.Tier] = badgeID
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserAchievement{
+			UserID:        standing.UserID,
+			AchievementID: badgeID,
+			UnlockedAt:    time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to grant season badge: %w", err)
+		}
+	}
+	return nil
+}
+
+// ================== QUERIES ==================
+
+// Get returns a season by number
+func (s *SeasonService) Get(number int) (*models.Season, error) {
+	var season models.Season
+	if err := database.GetDB().Where("number = ?", number).First(&season).Error; err != nil {
+		return nil, ErrSeasonNotFound
+	}
+	return &season, nil
+}
+
+// List returns every season, newest first
+func (s *SeasonService) List() ([]models.Season, error) {
+	var seasons []models.Season
+	err := database.GetDB().Order("number DESC").Find(&seasons).Error
+	return seasons, err
+}
+
+// Standings returns a page of a closed season's archived standings
+func (s *SeasonService) Standings(seasonID uint, limit, offset int) ([]models.SeasonStanding, int64, error) {
+	query := database.GetDB().Model(&models.SeasonStanding{}).Where("season_id = ?", seasonID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var standings []models.SeasonStanding
+	err := query.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username", "avatar", "level")
+	}).Order("rank").Limit(limit).Offset(offset).Find(&standings).Error
+	return standings, total, err
+}
+
+// Standing returns a user's archived standing in a season, or nil
+func (s *SeasonService) Standing(seasonID, userID uint) *models.SeasonStanding {
+	var standing models.SeasonStanding
+	if err := database.GetDB().Where("season_id = ? AND user_id = ?", seasonID, userID).First(&standing).Error; err != nil {
+		return nil
+	}
+	return &standing
+}
+
+// Stats returns a user's stats for a season, zero if they haven't played
+func (s *SeasonService) Stats(seasonID, userID uint) models.SeasonStat {
+	stat := models.SeasonStat{SeasonID: seasonID, UserID: userID}
+	database.GetDB().Where("season_id = ? AND user_id = ?", seasonID, userID).First(&stat)
+	return stat
+}
+
+// seasonQuarterStart returns the first day of the calendar quarter containing t
+func seasonQuarterStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), time.Month((int(t.Month())-1)/3*3+1), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Global instance
+var Seasons = NewSeasonService()
@@ -0,0 +1,262 @@
+// services/shop.go - Faith Points shop catalog and purchases
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"ubible/database"
+	"ubible/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Shop item types
+const (
+	ShopItemPowerUp = "powerup"
+	ShopItemTheme   = "theme"
+
+	// Most power-ups that can be bought in one purchase
+	MaxPurchaseQuantity = 20
+)
+
+// powerUpCatalog lists the power-ups for sale in display order
+var powerUpCatalog = []struct {
+	Type        string
+	Name        string
+	Description string
+	Price       int
+}{
+	{PowerUpFiftyFifty, "50/50", "Remove two wrong answers", 50},
+	{PowerUpTimeFreeze, "Time Freeze", "Add 10 seconds to the clock", 40},
+	{PowerUpHint, "Hint", "Reveal part of the answer", 30},
+	{PowerUpSkip, "Skip", "Skip a question without losing your streak", 75},
+	{PowerUpDouble, "Double Points", "Double the points of your next answer", 60},
+}
+
+var (
+	ErrUnknownShopItem = errors.New("unknown shop item")
+	ErrAlreadyOwned    = errors.New("theme already unlocked")
+	ErrThemeLocked     = errors.New("theme must be unlocked in the shop")
+	ErrInvalidQuantity = fmt.Errorf("quantity must be between 1 and %d", MaxPurchaseQuantity)
+)
+
+// ShopItem is one entry in the shop catalog. IDs look like "powerup:hint" or "theme:12".
+type ShopItem struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Cost        int    `json:"cost"`
+	PowerUp     string `json:"powerup,omitempty"`
+	ThemeID     uint   `json:"theme_id,omitempty"`
+	Owned       bool   `json:"owned,omitempty"`
+}
+
+// PurchaseResult describes a completed purchase
+type PurchaseResult struct {
+	Item      ShopItem `json:"item"`
+	Quantity  int      `json:"quantity"`
+	TotalCost int      `json:"total_cost"`
+	Balance   int      `json:"faith_points"`
+	Inventory int      `json:"inventory,omitempty"` // New power-up count
+}
+
+// ShopService sells power-ups and theme unlocks for Faith Points
+type ShopService struct{}
+
+// NewShopService creates a new shop service
+func NewShopService() *ShopService {
+	return &ShopService{}
+}
+
+// Catalog lists power-ups and purchasable themes, marking themes the user owns
+func (s *ShopService) Catalog(userID uint) ([]ShopItem, error) {
+	db := database.GetDB()
+
+	items := make([]ShopItem, 0, len(powerUpCatalog))
+	for _, p := range powerUpCatalog {
+		items = append(items, powerUpItem(p.Type))
+	}
+
+	var themes []models.Theme
+	if err := db.Where("is_active = ? AND unlock_cost > 0", true).Order("unlock_cost ASC, name ASC").Find(&themes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load shop themes: %w", err)
+	}
+
+	var owned []uint
+	if err := db.Model(&models.ThemeUnlock{}).Where("user_id = ?", userID).Pluck("theme_id", &owned).Error; err != nil {
+		return nil, fmt.Errorf("failed to load theme unlocks: %w", err)
+	}
+	ownedSet := make(map[uint]bool, len(owned))
+	for _, id := range owned {
+		ownedSet[id] = true
+	}
+
+	for _, t := range themes {
+		item := themeItem(t)
+		item.Owned = ownedSet[t.ID]
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// Purchase debits Faith Points and credits the item in a single transaction
+func (s *ShopService) Purchase(userID uint, itemID string, quantity int) (*PurchaseResult, error) {
+	kind, key, _ := strings.Cut(itemID, ":")
+
+	db := database.GetDB()
+	var result *PurchaseResult
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		switch kind {
+		case ShopItemPowerUp:
+			result, err = s.buyPowerUp(tx, userID, PowerUps.NormalizeType(key), quantity)
+		case ShopItemTheme:
+			themeID, parseErr := strconv.ParseUint(key, 10, 64)
+			if parseErr != nil {
+				return ErrUnknownShopItem
+			}
+			result, err = s.buyTheme(tx, userID, uint(themeID))
+		default:
+			return ErrUnknownShopItem
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *ShopService) buyPowerUp(tx *gorm.DB, userID uint, kind string, quantity int) (*PurchaseResult, error) {
+	column, ok := powerUpColumns[kind]
+	if !ok {
+		return nil, ErrUnknownShopItem
+	}
+	if quantity < 1 || quantity > MaxPurchaseQuantity {
+		return nil, ErrInvalidQuantity
+	}
+
+	item := powerUpItem(kind)
+	total := item.Cost * quantity
+
+	balance, err := FaithPoints.Adjust(tx, userID, -total, models.FPReasonPurchase, item.ID+" x"+strconv.Itoa(quantity))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&models.User{}).
+		Where("id = ?", userID).
+		Update(column, gorm.Expr(column+" + ?", quantity)).Error; err != nil {
+		return nil, fmt.Errorf("failed to credit power-up: %w", err)
+	}
+
+	var inventory int
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Select(column).Scan(&inventory).Error; err != nil {
+		return nil, fmt.Errorf("failed to read power-up inventory: %w", err)
+	}
+
+	return &PurchaseResult{Item: item, Quantity: quantity, TotalCost: total, Balance: balance, Inventory: inventory}, nil
+}
+
+func (s *ShopService) buyTheme(tx *gorm.DB, userID, themeID uint) (*PurchaseResult, error) {
+	var theme models.Theme
+	if err := tx.Where("id = ? AND is_active = ? AND unlock_cost > 0", themeID, true).First(&theme).Error; err != nil {
+		return nil, ErrUnknownShopItem
+	}
+
+	unlock := models.ThemeUnlock{
+		UserID:     userID,
+		ThemeID:    theme.ID,
+		Cost:       theme.UnlockCost,
+		UnlockedAt: time.Now(),
+	}
+	created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&unlock)
+	if created.Error != nil {
+		return nil, fmt.Errorf("failed to unlock theme: %w", created.Error)
+	}
+	if created.RowsAffected == 0 {
+		return nil, ErrAlreadyOwned
+	}
+
+	item := themeItem(theme)
+	balance, err := FaithPoints.Adjust(tx, userID, -theme.UnlockCost, models.FPReasonPurchase, item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Owned = true
+	return &PurchaseResult{Item: item, Quantity: 1, TotalCost: theme.UnlockCost, Balance: balance}, nil
+}
+
+// lockedThemeIDs is a subquery of the paid themes a user hasn't unlocked.
+// userID 0 (anonymous) has unlocked nothing.
+func lockedThemeIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Model(&models.Theme{}).
+		Select("id").
+		Where("unlock_cost > 0 AND id NOT IN (?)",
+			db.Model(&models.ThemeUnlock{}).Select("theme_id").Where("user_id = ?", userID))
+}
+
+// CheckThemeAccess returns ErrThemeLocked if any of the themes costs Faith
+// Points and the user hasn't bought it
+func (s *ShopService) CheckThemeAccess(userID uint, themeIDs []int) error {
+	if len(themeIDs) == 0 {
+		return nil
+	}
+
+	db := database.GetDB()
+	var locked models.Theme
+	err := db.Where("id IN ? AND id IN (?)", themeIDs, lockedThemeIDs(db, userID)).
+		Order("id ASC").
+		Limit(1).
+		Find(&locked).Error
+	if err != nil {
+		return fmt.Errorf("failed to check theme unlocks: %w", err)
+	}
+	if locked.ID != 0 {
+		return fmt.Errorf("%w: %s costs %d Faith Points", ErrThemeLocked, locked.Name, locked.UnlockCost)
+	}
+	return nil
+}
+
+// ExcludeLockedThemes limits a question query to the themes a user may play
+func (s *ShopService) ExcludeLockedThemes(query *gorm.DB, userID uint) *gorm.DB {
+	return query.Where("theme_id NOT IN (?)", lockedThemeIDs(database.GetDB(), userID))
+}
+
+func powerUpItem(kind string) ShopItem {
+	for _, p := range powerUpCatalog {
+		if p.Type == kind {
+			return ShopItem{
+				ID:          ShopItemPowerUp + ":" + p.Type,
+				Type:        ShopItemPowerUp,
+				Name:        p.Name,
+				Description: p.Description,
+				Cost:        p.Price,
+				PowerUp:     p.Type,
+			}
+		}
+	}
+	return ShopItem{}
+}
+
+func themeItem(t models.Theme) ShopItem {
+	return ShopItem{
+		ID:          ShopItemTheme + ":" + strconv.FormatUint(uint64(t.ID), 10),
+		Type:        ShopItemTheme,
+		Name:        t.Name,
+		Description: t.Description,
+		Cost:        t.UnlockCost,
+		ThemeID:     t.ID,
+	}
+}
+
+// Global instance
+var Shop = NewShopService()
@@ -5,12 +5,32 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"strings"
 	"time"
 	"ubible/models"
+	"unicode/utf8"
 
 	"gorm.io/gorm"
 )
 
+const MaxTeamNameLength = 100
+
+var (
+	ErrTeamNotFound          = errors.New("team not found")
+	ErrTeamNameRequired      = errors.New("team name is required")
+	ErrTeamNameTooLong       = errors.New("team name is too long")
+	ErrNotTeamMember         = errors.New("not a member of this team")
+	ErrAlreadyTeamMember     = errors.New("already a member of this team")
+	ErrTeamMemberNotFound    = errors.New("member not found")
+	ErrTeamAdminRequired     = errors.New("only team owner or admin can do this")
+	ErrTeamOwnerRequired     = errors.New("only team owner can do this")
+	ErrTeamOwnerProtected    = errors.New("cannot remove or demote team owner")
+	ErrTeamOwnerMustTransfer = errors.New("team owner must transfer ownership before leaving")
+)
+
+// memberRoleOrder sorts owner, admins, then members
+const memberRoleOrder = "CASE role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END"
+
 type TeamService struct {
 	db *gorm.DB
 }
@@ -23,8 +43,9 @@ func NewTeamService(db *gorm.DB) *TeamService {
 
 // CreateTeam creates a new team with the user as owner
 func (s *TeamService) CreateTeam(name, description string, isPublic bool, creatorID uint) (*models.Team, error) {
-	if name == "" {
-		return nil, errors.New("team name is required")
+	name, err := validateTeamName(name)
+	if err != nil {
+		return nil, err
 	}
 
 	// Generate unique team code
@@ -41,7 +62,7 @@ func (s *TeamService) CreateTeam(name, description string, isPublic bool, creato
 	}
 
 	// Create team and add creator as owner in a transaction
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(team).Error; err != nil {
 			return err
 		}
@@ -71,12 +92,14 @@ func (s *TeamService) CreateTeam(name, description string, isPublic bool, creato
 func (s *TeamService) GetTeamByID(teamID uint) (*models.Team, error) {
 	var team models.Team
 	err := s.db.Where("id = ? AND is_active = ?", teamID, true).
-		Preload("Members").
+		Preload("Members", func(db *gorm.DB) *gorm.DB {
+			return db.Where("is_active = ?", true).Order(memberRoleOrder + ", total_score DESC")
+		}).
 		Preload("Members.User").
 		First(&team).Error
 
 	if err != nil {
-		return nil, err
+		return nil, ErrTeamNotFound
 	}
 
 	return &team, nil
@@ -85,12 +108,11 @@ func (s *TeamService) GetTeamByID(teamID uint) (*models.Team, error) {
 // GetTeamByCode retrieves a team by its join code
 func (s *TeamService) GetTeamByCode(code string) (*models.Team, error) {
 	var team models.Team
-	err := s.db.Where("team_code = ? AND is_active = ?", code, true).
-		Preload("Members").
+	err := s.db.Where("team_code = ? AND is_active = ?", strings.ToLower(strings.TrimSpace(code)), true).
 		First(&team).Error
 
 	if err != nil {
-		return nil, errors.New("team not found or inactive")
+		return nil, ErrTeamNotFound
 	}
 
 	return &team, nil
@@ -103,17 +125,36 @@ func (s *TeamService) GetUserTeams(userID uint) ([]models.Team, error) {
 	err := s.db.Joins("JOIN team_members ON team_members.team_id = teams.id").
 		Where("team_members.user_id = ? AND team_members.is_active = ? AND teams.is_active = ?",
 			userID, true, true).
-		Preload("Members", "is_active = ?", true).
+		Order("team_members.joined_at ASC").
 		Find(&teams).Error
 
 	return teams, err
 }
 
+// GetUserMemberships returns a user's active memberships with their teams
+func (s *TeamService) GetUserMemberships(userID uint) ([]models.TeamMember, error) {
+	var memberships []models.TeamMember
+
+	err := s.db.Joins("JOIN teams ON teams.id = team_members.team_id").
+		Where("team_members.user_id = ? AND team_members.is_active = ? AND teams.is_active = ?",
+			userID, true, true).
+		Preload("Team").
+		Order("team_members.joined_at ASC").
+		Find(&memberships).Error
+
+	return memberships, err
+}
+
 // UpdateTeam updates team information (owner/admin only)
 func (s *TeamService) UpdateTeam(teamID uint, name, description string, isPublic bool, updaterID uint) error {
 	// Verify updater is owner or admin
 	if !s.IsTeamAdmin(updaterID, teamID) {
-		return errors.New("only team owner or admin can update team")
+		return ErrTeamAdminRequired
+	}
+
+	name, err := validateTeamName(name)
+	if err != nil {
+		return err
 	}
 
 	updates := map[string]interface{}{
@@ -129,13 +170,13 @@ func (s *TeamService) UpdateTeam(teamID uint, name, description string, isPublic
 // DeleteTeam soft deletes a team (owner only)
 func (s *TeamService) DeleteTeam(teamID, ownerID uint) error {
 	// Verify ownership
-	var member models.TeamMember
-	if err := s.db.Where("team_id = ? AND user_id = ?", teamID, ownerID).First(&member).Error; err != nil {
-		return errors.New("team not found")
+	role, err := s.GetMemberRole(ownerID, teamID)
+	if err != nil {
+		return err
 	}
 
-	if member.Role != models.TeamRoleOwner {
-		return errors.New("only team owner can delete team")
+	if role != models.TeamRoleOwner {
+		return ErrTeamOwnerRequired
 	}
 
 	// Soft delete team and all memberships
@@ -154,17 +195,31 @@ func (s *TeamService) DeleteTeam(teamID, ownerID uint) error {
 
 // ================== TEAM MEMBERSHIP OPERATIONS ==================
 
-// JoinTeam adds a user to a team via invite code
-func (s *TeamService) JoinTeam(userID uint, teamCode string) error {
+// JoinTeam adds a user to a team via invite code and returns the team
+func (s *TeamService) JoinTeam(userID uint, teamCode string) (*models.Team, error) {
 	// Get team by code
 	team, err := s.GetTeamByCode(teamCode)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Check if already a member
 	if s.IsTeamMember(userID, team.ID) {
-		return errors.New("already a member of this team")
+		return nil, ErrAlreadyTeamMember
+	}
+
+	// Rejoining reactivates the old membership as a regular member
+	var previous models.TeamMember
+	if err := s.db.Where("team_id = ? AND user_id = ?", team.ID, userID).First(&previous).Error; err == nil {
+		err := s.db.Model(&previous).Updates(map[string]interface{}{
+			"is_active": true,
+			"role":      models.TeamRoleMember,
+			"joined_at": time.Now(),
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+		return team, nil
 	}
 
 	// Add as member
@@ -178,19 +233,23 @@ func (s *TeamService) JoinTeam(userID uint, teamCode string) error {
 		QuizzesPlayed: 0,
 	}
 
-	return s.db.Create(member).Error
+	if err := s.db.Create(member).Error; err != nil {
+		return nil, err
+	}
+
+	return team, nil
 }
 
 // LeaveTeam removes a user from a team
 func (s *TeamService) LeaveTeam(userID, teamID uint) error {
-	var member models.TeamMember
-	if err := s.db.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error; err != nil {
-		return errors.New("not a member of this team")
+	member, err := s.activeMember(teamID, userID)
+	if err != nil {
+		return ErrNotTeamMember
 	}
 
 	// Owner cannot leave without transferring ownership
 	if member.Role == models.TeamRoleOwner {
-		return errors.New("team owner must transfer ownership before leaving")
+		return ErrTeamOwnerMustTransfer
 	}
 
 	return s.db.Model(&member).Update("is_active", false).Error
@@ -199,83 +258,86 @@ func (s *TeamService) LeaveTeam(userID, teamID uint) error {
 // RemoveMember removes a member from team (admin/owner only)
 func (s *TeamService) RemoveMember(teamID, adminID, memberID uint) error {
 	// Verify admin permissions
-	if !s.IsTeamAdmin(adminID, teamID) {
-		return errors.New("only team admin or owner can remove members")
+	adminRole, err := s.GetMemberRole(adminID, teamID)
+	if err != nil {
+		return err
+	}
+	if adminRole != models.TeamRoleOwner && adminRole != models.TeamRoleAdmin {
+		return ErrTeamAdminRequired
 	}
 
 	// Cannot remove owner
-	var targetMember models.TeamMember
-	if err := s.db.Where("team_id = ? AND user_id = ?", teamID, memberID).First(&targetMember).Error; err != nil {
-		return errors.New("member not found")
+	targetMember, err := s.activeMember(teamID, memberID)
+	if err != nil {
+		return err
 	}
 
 	if targetMember.Role == models.TeamRoleOwner {
-		return errors.New("cannot remove team owner")
+		return ErrTeamOwnerProtected
+	}
+
+	// Only the owner can remove admins
+	if targetMember.Role == models.TeamRoleAdmin && adminRole != models.TeamRoleOwner {
+		return ErrTeamOwnerRequired
 	}
 
-	return s.db.Model(&targetMember).Update("is_active", false).Error
+	return s.db.Model(targetMember).Update("is_active", false).Error
 }
 
 // PromoteMember promotes a member to admin (owner only)
 func (s *TeamService) PromoteMember(teamID, ownerID, memberID uint) error {
 	// Verify ownership
-	var owner models.TeamMember
-	if err := s.db.Where("team_id = ? AND user_id = ?", teamID, ownerID).First(&owner).Error; err != nil {
-		return errors.New("not authorized")
+	if err := s.requireOwner(teamID, ownerID); err != nil {
+		return err
+	}
+
+	targetMember, err := s.activeMember(teamID, memberID)
+	if err != nil {
+		return err
 	}
 
-	if owner.Role != models.TeamRoleOwner {
-		return errors.New("only team owner can promote members")
+	if targetMember.Role == models.TeamRoleOwner {
+		return ErrTeamOwnerProtected
 	}
 
 	// Update member role
-	return s.db.Model(&models.TeamMember{}).
-		Where("team_id = ? AND user_id = ?", teamID, memberID).
-		Update("role", models.TeamRoleAdmin).Error
+	return s.db.Model(targetMember).Update("role", models.TeamRoleAdmin).Error
 }
 
 // DemoteMember demotes an admin to regular member (owner only)
 func (s *TeamService) DemoteMember(teamID, ownerID, memberID uint) error {
 	// Verify ownership
-	var owner models.TeamMember
-	if err := s.db.Where("team_id = ? AND user_id = ?", teamID, ownerID).First(&owner).Error; err != nil {
-		return errors.New("not authorized")
-	}
-
-	if owner.Role != models.TeamRoleOwner {
-		return errors.New("only team owner can demote members")
+	if err := s.requireOwner(teamID, ownerID); err != nil {
+		return err
 	}
 
 	// Cannot demote owner
-	var targetMember models.TeamMember
-	if err := s.db.Where("team_id = ? AND user_id = ?", teamID, memberID).First(&targetMember).Error; err != nil {
-		return errors.New("member not found")
+	targetMember, err := s.activeMember(teamID, memberID)
+	if err != nil {
+		return err
 	}
 
 	if targetMember.Role == models.TeamRoleOwner {
-		return errors.New("cannot demote team owner")
+		return ErrTeamOwnerProtected
 	}
 
 	// Update member role
-	return s.db.Model(&targetMember).Update("role", models.TeamRoleMember).Error
+	return s.db.Model(targetMember).Update("role", models.TeamRoleMember).Error
 }
 
 // TransferOwnership transfers team ownership to another member
 func (s *TeamService) TransferOwnership(teamID, currentOwnerID, newOwnerID uint) error {
 	// Verify current ownership
-	var currentOwner models.TeamMember
-	if err := s.db.Where("team_id = ? AND user_id = ?", teamID, currentOwnerID).First(&currentOwner).Error; err != nil {
-		return errors.New("not authorized")
+	if err := s.requireOwner(teamID, currentOwnerID); err != nil {
+		return err
 	}
-
-	if currentOwner.Role != models.TeamRoleOwner {
-		return errors.New("only team owner can transfer ownership")
+	if newOwnerID == currentOwnerID {
+		return nil
 	}
 
 	// Verify new owner is a member
-	var newOwnerMember models.TeamMember
-	if err := s.db.Where("team_id = ? AND user_id = ?", teamID, newOwnerID).First(&newOwnerMember).Error; err != nil {
-		return errors.New("new owner must be a team member")
+	if _, err := s.activeMember(teamID, newOwnerID); err != nil {
+		return err
 	}
 
 	// Update roles in transaction
@@ -303,32 +365,34 @@ func (s *TeamService) TransferOwnership(teamID, currentOwnerID, newOwnerID uint)
 
 // ================== TEAM STATISTICS & LEADERBOARD ==================
 
-// GetTeamMembers returns all active members of a team with stats
-func (s *TeamService) GetTeamMembers(teamID uint) ([]models.TeamMember, error) {
-	var members []models.TeamMember
-
-	err := s.db.Where("team_id = ? AND is_active = ?", teamID, true).
-		Preload("User").
-		Order("role ASC, total_score DESC").
-		Find(&members).Error
+// GetTeamMembers returns a page of active members of a team with stats, owner
+// and admins first, plus the total member count
+func (s *TeamService) GetTeamMembers(teamID uint, limit, offset int) ([]models.TeamMember, int64, error) {
+	return s.pageMembers(teamID, memberRoleOrder+", total_score DESC, id ASC", limit, offset)
+}
 
-	return members, err
+// GetTeamLeaderboard returns a page of team members sorted by score, plus the
+// total member count
+func (s *TeamService) GetTeamLeaderboard(teamID uint, limit, offset int) ([]models.TeamMember, int64, error) {
+	return s.pageMembers(teamID, "total_score DESC, quizzes_played DESC, id ASC", limit, offset)
 }
 
-// GetTeamLeaderboard returns team members sorted by score
-func (s *TeamService) GetTeamLeaderboard(teamID uint, limit int) ([]models.TeamMember, error) {
+func (s *TeamService) pageMembers(teamID uint, order string, limit, offset int) ([]models.TeamMember, int64, error) {
 	var members []models.TeamMember
+	var total int64
 
-	query := s.db.Where("team_id = ? AND is_active = ?", teamID, true).
-		Preload("User").
-		Order("total_score DESC, quizzes_played DESC")
+	query := s.db.Model(&models.TeamMember{}).Where("team_id = ? AND is_active = ?", teamID, true)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
 
+	query = query.Preload("User").Order(order).Offset(offset)
 	if limit > 0 {
 		query = query.Limit(limit)
 	}
 
 	err := query.Find(&members).Error
-	return members, err
+	return members, total, err
 }
 
 // UpdateMemberStats updates a member's game statistics
@@ -383,23 +447,30 @@ func (s *TeamService) GetTeamStats(teamID uint) (map[string]interface{}, error)
 
 // ================== TEAM SEARCH & DISCOVERY ==================
 
-// SearchPublicTeams searches for public teams by name
-func (s *TeamService) SearchPublicTeams(query string, limit int) ([]models.Team, error) {
+// SearchPublicTeams returns a page of public teams matching query by name or
+// description, plus the total number of matches
+func (s *TeamService) SearchPublicTeams(query string, limit, offset int) ([]models.Team, int64, error) {
 	var teams []models.Team
+	var total int64
 
-	searchQuery := s.db.Where("is_public = ? AND is_active = ?", true, true)
+	searchQuery := s.db.Model(&models.Team{}).Where("is_public = ? AND is_active = ?", true, true)
 
 	if query != "" {
-		searchQuery = searchQuery.Where("name LIKE ? OR description LIKE ?", "%"+query+"%", "%"+query+"%")
+		pattern := "%" + strings.ToLower(query) + "%"
+		searchQuery = searchQuery.Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ?", pattern, pattern)
+	}
+
+	if err := searchQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
 
 	err := searchQuery.
-		Preload("Members", "is_active = ?", true).
-		Limit(limit).
 		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
 		Find(&teams).Error
 
-	return teams, err
+	return teams, total, err
 }
 
 // GetPopularTeams returns teams with most members
@@ -413,7 +484,6 @@ func (s *TeamService) GetPopularTeams(limit int) ([]models.Team, error) {
 		Group("teams.id").
 		Order("member_count DESC").
 		Limit(limit).
-		Preload("Members", "is_active = ?", true).
 		Find(&teams).Error
 
 	return teams, err
@@ -425,7 +495,6 @@ func (s *TeamService) GetPublicTeams(limit int) ([]models.Team, error) {
 
 	err := s.db.
 		Where("is_public = ? AND is_active = ?", true, true).
-		Preload("Members", "is_active = ?", true).
 		Order("created_at DESC").
 		Limit(limit).
 		Find(&teams).Error
@@ -464,12 +533,69 @@ func (s *TeamService) GetMemberRole(userID, teamID uint) (models.TeamRole, error
 		First(&member).Error
 
 	if err != nil {
-		return "", errors.New("not a member of this team")
+		return "", ErrNotTeamMember
 	}
 
 	return member.Role, nil
 }
 
+// MemberCounts returns the number of active members for each team
+func (s *TeamService) MemberCounts(teamIDs []uint) map[uint]int64 {
+	counts := make(map[uint]int64, len(teamIDs))
+	if len(teamIDs) == 0 {
+		return counts
+	}
+
+	var rows []struct {
+		TeamID uint
+		Count  int64
+	}
+	s.db.Model(&models.TeamMember{}).
+		Select("team_id, COUNT(*) as count").
+		Where("team_id IN ? AND is_active = ?", teamIDs, true).
+		Group("team_id").
+		Scan(&rows)
+
+	for _, row := range rows {
+		counts[row.TeamID] = row.Count
+	}
+	return counts
+}
+
+// activeMember loads a user's active membership in a team
+func (s *TeamService) activeMember(teamID, userID uint) (*models.TeamMember, error) {
+	var member models.TeamMember
+	if err := s.db.Where("team_id = ? AND user_id = ? AND is_active = ?", teamID, userID, true).
+		First(&member).Error; err != nil {
+		return nil, ErrTeamMemberNotFound
+	}
+	return &member, nil
+}
+
+// requireOwner checks that userID owns the team
+func (s *TeamService) requireOwner(teamID, userID uint) error {
+	role, err := s.GetMemberRole(userID, teamID)
+	if err != nil {
+		return err
+	}
+	if role != models.TeamRoleOwner {
+		return ErrTeamOwnerRequired
+	}
+	return nil
+}
+
+// validateTeamName trims a team name and checks it fits the column
+func validateTeamName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrTeamNameRequired
+	}
+	if utf8.RuneCountInString(name) > MaxTeamNameLength {
+		return "", ErrTeamNameTooLong
+	}
+	return name, nil
+}
+
 // generateUniqueTeamCode generates a unique 6-character alphanumeric code
 func (s *TeamService) generateUniqueTeamCode() string {
 	for {
@@ -0,0 +1,432 @@
+// services/team_themes.go - Team verse collections as playable question banks
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"ubible/database"
+	"ubible/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	MaxTeamThemeSize = 512 * 1024 // Bytes of verse list text
+	MaxTeamThemeTags = 10
+	maxTeamThemeTag  = 30
+	minThemeVerses   = 4 // Wrong answers are drawn from the same list
+)
+
+var (
+	ErrTeamThemeNotFound     = errors.New("team theme not found")
+	ErrInvalidTeamTheme      = errors.New("invalid team theme")
+	ErrThemeReviewPending    = errors.New("theme is already awaiting review")
+	ErrThemeAlreadyPublished = errors.New("theme is already published")
+	ErrThemeNotInReview      = errors.New("theme is not awaiting review")
+)
+
+// TeamThemeInput creates or edits a team theme. Nil fields keep their current
+// value. Content is the verse list in any format LoadVersesFromTXT accepts.
+type TeamThemeInput struct {
+	Name        *string  `json:"name"`
+	Description *string  `json:"description"`
+	Content     *string  `json:"content"`
+	IsPublic    *bool    `json:"is_public"`
+	Tags        []string `json:"tags"`
+}
+
+// ThemeBankReport describes the question bank parsed from a verse list
+type ThemeBankReport struct {
+	Format    string `json:"format"` // verses or qa
+	Verses    int    `json:"verses"`
+	Questions int    `json:"questions"`
+	BadLines  []int  `json:"bad_lines,omitempty"` // Lines skipped in the verse format
+}
+
+// TeamThemeService manages team verse collections and their question banks
+type TeamThemeService struct{}
+
+// NewTeamThemeService creates a new team theme service
+func NewTeamThemeService() *TeamThemeService {
+	return &TeamThemeService{}
+}
+
+// ================== THEME MANAGEMENT ==================
+
+// Create adds a verse collection to a team, rejecting lists that don't
+// produce a playable bank
+func (s *TeamThemeService) Create(teamID, creatorID uint, in TeamThemeInput) (*models.TeamTheme, *ThemeBankReport, error) {
+	if in.Content == nil {
+		return nil, nil, fmt.Errorf("%w: verse content is required", ErrInvalidTeamTheme)
+	}
+
+	theme := &models.TeamTheme{
+		TeamID:    teamID,
+		CreatedBy: creatorID,
+		Tags:      models.StringArray{},
+		IsActive:  true,
+		CreatedAt: time.Now(),
+	}
+	report, err := s.apply(theme, in)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := database.GetDB().Create(theme).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to create team theme: %w", err)
+	}
+
+	log.Printf("📚 Team %d theme %d %q created with %d questions", teamID, theme.ID, theme.Name, report.Questions)
+	return theme, report, nil
+}
+
+// Update edits a team theme. The report is nil unless the content changed.
+func (s *TeamThemeService) Update(themeID uint, in TeamThemeInput) (*models.TeamTheme, *ThemeBankReport, error) {
+	theme, err := s.Get(themeID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report, err := s.apply(theme, in)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	theme.UpdatedAt = time.Now()
+	if err := database.GetDB().Save(theme).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to update team theme: %w", err)
+	}
+
+	return theme, report, nil
+}
+
+// Delete deactivates a team theme. Challenges keep the questions they fixed.
+func (s *TeamThemeService) Delete(themeID uint) error {
+	result := database.GetDB().Model(&models.TeamTheme{}).
+		Where("id = ? AND is_active = ?", themeID, true).
+		Updates(map[string]interface{}{"is_active": false, "updated_at": time.Now()})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete team theme: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrTeamThemeNotFound
+	}
+	return nil
+}
+
+// Get returns an active team theme
+func (s *TeamThemeService) Get(themeID uint) (*models.TeamTheme, error) {
+	var theme models.TeamTheme
+	if err := database.GetDB().Where("id = ? AND is_active = ?", themeID, true).First(&theme).Error; err != nil {
+		return nil, ErrTeamThemeNotFound
+	}
+	return &theme, nil
+}
+
+// List returns a page of a team's themes, most used first, optionally only
+// those carrying tag
+func (s *TeamThemeService) List(teamID uint, tag string, limit, offset int) ([]models.TeamTheme, int64, error) {
+	query := database.GetDB().Model(&models.TeamTheme{}).Where("team_id = ? AND is_active = ?", teamID, true)
+	if tag = normalizeTag(tag); tag != "" {
+		query = query.Where("? = ANY(tags)", tag)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var themes []models.TeamTheme
+	err := query.Order("usage_count DESC, name ASC").Limit(limit).Offset(offset).Find(&themes).Error
+	return themes, total, err
+}
+
+// ListForUser returns the themes of every team the user belongs to, most used
+// first, optionally only those carrying tag
+func (s *TeamThemeService) ListForUser(userID uint, tag string, limit int) ([]models.TeamTheme, error) {
+	query := database.GetDB().
+		Joins("JOIN team_members ON team_members.team_id = team_themes.team_id").
+		Where("team_members.user_id = ? AND team_members.is_active = ? AND team_themes.is_active = ?", userID, true, true)
+	if tag = normalizeTag(tag); tag != "" {
+		query = query.Where("? = ANY(team_themes.tags)", tag)
+	}
+
+	var themes []models.TeamTheme
+	err := query.Preload("Team").Preload("Creator").
+		Order("team_themes.usage_count DESC, team_themes.name ASC").
+		Limit(limit).Find(&themes).Error
+	return themes, err
+}
+
+// Tags returns the distinct tags used by a team's themes
+func (s *TeamThemeService) Tags(teamID uint) ([]string, error) {
+	tags := []string{}
+	err := database.GetDB().Raw(
+		"SELECT DISTINCT unnest(tags) AS tag FROM team_themes WHERE team_id = ? AND is_active = ? ORDER BY tag",
+		teamID, true).Scan(&tags).Error
+	return tags, err
+}
+
+// apply validates input onto theme and parses new content
+func (s *TeamThemeService) apply(theme *models.TeamTheme, in TeamThemeInput) (*ThemeBankReport, error) {
+	if in.Name != nil {
+		theme.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Description != nil {
+		theme.Description = strings.TrimSpace(*in.Description)
+	}
+	if in.IsPublic != nil {
+		theme.IsPublic = *in.IsPublic
+	}
+	if in.Tags != nil {
+		tags := models.StringArray{}
+		seen := make(map[string]bool)
+		for _, tag := range in.Tags {
+			if tag = normalizeTag(tag); tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			if len(tag) > maxTeamThemeTag {
+				return nil, fmt.Errorf("%w: tags can be at most %d characters", ErrInvalidTeamTheme, maxTeamThemeTag)
+			}
+			tags = append(tags, tag)
+		}
+		if len(tags) > MaxTeamThemeTags {
+			return nil, fmt.Errorf("%w: at most %d tags", ErrInvalidTeamTheme, MaxTeamThemeTags)
+		}
+		theme.Tags = tags
+	}
+
+	if theme.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidTeamTheme)
+	}
+	if len(theme.Name) > 100 {
+		return nil, fmt.Errorf("%w: name is too long", ErrInvalidTeamTheme)
+	}
+
+	if in.Content == nil {
+		return nil, nil
+	}
+
+	content := strings.TrimSpace(*in.Content)
+	if len(content) > MaxTeamThemeSize {
+		return nil, fmt.Errorf("%w: verse list is larger than %d KB", ErrInvalidTeamTheme, MaxTeamThemeSize/1024)
+	}
+
+	_, report, err := parseThemeBank(content)
+	if err != nil {
+		return nil, err
+	}
+
+	theme.VerseFile = content
+	return report, nil
+}
+
+// ================== QUESTION BANKS ==================
+
+// Questions generates the question bank for a team theme. The questions are
+// scoped to the theme and never stored in the global catalog.
+func (s *TeamThemeService) Questions(theme models.TeamTheme) ([]models.Question, error) {
+	questions, _, err := parseThemeBank(themeContent(theme))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range questions {
+		questions[i].ThemeName = theme.Name
+	}
+	return questions, nil
+}
+
+// RecordUse counts a play of a team theme
+func (s *TeamThemeService) RecordUse(tx *gorm.DB, themeID uint) error {
+	now := time.Now()
+	return tx.Model(&models.TeamTheme{}).Where("id = ?", themeID).Updates(map[string]interface{}{
+		"usage_count": gorm.Expr("usage_count + 1"),
+		"last_used":   now,
+	}).Error
+}
+
+// themeContent returns the verse list of a theme. VerseFile is either the list
+// itself or, for older themes, the name of a file in the verses directory.
+func themeContent(theme models.TeamTheme) string {
+	source := strings.TrimSpace(theme.VerseFile)
+	if !strings.Contains(source, "\n") {
+		if data, err := os.ReadFile(filepath.Join(VersesDirectory, filepath.Base(source))); err == nil {
+			return string(data)
+		}
+	}
+	return source
+}
+
+// parseThemeBank turns a verse list into questions. Numbered or direct verse
+// lines are tried first; the Q&A format is accepted when VERSE_FORMAT_ALLOW_QA
+// is set, as for the verse files.
+func parseThemeBank(content string) ([]models.Question, *ThemeBankReport, error) {
+	verses, badLines, err := ParseVerses(strings.NewReader(content))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidTeamTheme, err)
+	}
+
+	if len(verses) > 0 {
+		if len(verses) < minThemeVerses {
+			return nil, nil, fmt.Errorf("%w: the verse list needs at least %d verses (has %d)", ErrInvalidTeamTheme, minThemeVerses, len(verses))
+		}
+		questions := VerseQuestions(verses)
+		return questions, &ThemeBankReport{Format: "verses", Verses: len(verses), Questions: len(questions), BadLines: badLines}, nil
+	}
+
+	if os.Getenv("VERSE_FORMAT_ALLOW_QA") == "true" {
+		questions, err := ParseQA(strings.NewReader(content))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidTeamTheme, err)
+		}
+		if len(questions) > 0 {
+			return questions, &ThemeBankReport{Format: "qa", Questions: len(questions)}, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("%w: no verses found - use one verse per line as 'N. <Reference> — <Text>'", ErrInvalidTeamTheme)
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// ================== PUBLISHING ==================
+
+// RequestPublish submits a team theme for review into the global catalog
+func (s *TeamThemeService) RequestPublish(themeID uint) (*models.TeamTheme, error) {
+	theme, err := s.Get(themeID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch theme.PublishStatus {
+	case models.ThemePublishPending:
+		return nil, ErrThemeReviewPending
+	case models.ThemePublishApproved:
+		return nil, ErrThemeAlreadyPublished
+	}
+
+	theme.PublishStatus = models.ThemePublishPending
+	theme.ReviewNote = ""
+	theme.ReviewedBy = nil
+	theme.ReviewedAt = nil
+	if err := database.GetDB().Save(theme).Error; err != nil {
+		return nil, fmt.Errorf("failed to submit theme for review: %w", err)
+	}
+
+	log.Printf("📝 Team theme %d %q submitted for catalog review", theme.ID, theme.Name)
+	return theme, nil
+}
+
+// ListForReview returns team themes with a publish status, oldest first
+func (s *TeamThemeService) ListForReview(status string, limit, offset int) ([]models.TeamTheme, int64, error) {
+	query := database.GetDB().Model(&models.TeamTheme{}).Where("is_active = ? AND publish_status = ?", true, status)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var themes []models.TeamTheme
+	err := query.Preload("Team").Preload("Creator").Order("updated_at ASC").Limit(limit).Offset(offset).Find(&themes).Error
+	return themes, total, err
+}
+
+// Review approves or rejects a pending theme. Approval copies its questions
+// into a new public theme in the global catalog.
+func (s *TeamThemeService) Review(themeID, reviewerID uint, approve bool, note string) (*models.TeamTheme, error) {
+	db := database.GetDB()
+
+	var theme models.TeamTheme
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND is_active = ?", themeID, true).First(&theme).Error; err != nil {
+			return ErrTeamThemeNotFound
+		}
+		if theme.PublishStatus != models.ThemePublishPending {
+			return ErrThemeNotInReview
+		}
+
+		now := time.Now()
+		theme.ReviewNote = strings.TrimSpace(note)
+		theme.ReviewedBy = &reviewerID
+		theme.ReviewedAt = &now
+		theme.PublishStatus = models.ThemePublishRejected
+
+		if approve {
+			catalogID, err := s.publish(tx, theme)
+			if err != nil {
+				return err
+			}
+			theme.PublishStatus = models.ThemePublishApproved
+			theme.PublishedThemeID = &catalogID
+		}
+
+		return tx.Save(&theme).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("📝 Team theme %d %q %s by admin %d", theme.ID, theme.Name, theme.PublishStatus, reviewerID)
+	return &theme, nil
+}
+
+// publish creates the catalog theme and its questions inside tx
+func (s *TeamThemeService) publish(tx *gorm.DB, theme models.TeamTheme) (uint, error) {
+	var existing int64
+	tx.Model(&models.Theme{}).Where("LOWER(name) = LOWER(?)", theme.Name).Count(&existing)
+	if existing > 0 {
+		return 0, fmt.Errorf("%w: a catalog theme named %q already exists", ErrInvalidTeamTheme, theme.Name)
+	}
+
+	content := themeContent(theme)
+	questions, report, err := parseThemeBank(content)
+	if err != nil {
+		return 0, err
+	}
+
+	catalog := models.Theme{
+		Name:        theme.Name,
+		Description: theme.Description,
+		IsActive:    true,
+		IsPublic:    true,
+		CreatedBy:   &theme.CreatedBy,
+	}
+	if catalog.Description == "" {
+		catalog.Description = fmt.Sprintf("Questions about %s", theme.Name)
+	}
+	if err := tx.Create(&catalog).Error; err != nil {
+		return 0, fmt.Errorf("failed to create catalog theme: %w", err)
+	}
+
+	if report.Format == "verses" {
+		// Regenerate against the catalog so wrong answers can come from other themes too
+		verses, _, _ := ParseVerses(strings.NewReader(content))
+		if err := generateQuestionsFromVerses(tx, catalog, verses, false); err != nil {
+			return 0, err
+		}
+	} else {
+		for i := range questions {
+			questions[i].ThemeID = catalog.ID
+			questions[i].ThemeName = catalog.Name
+			if err := saveQuestion(tx, &questions[i]); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return catalog.ID, nil
+}
+
+// Global instance
+var TeamThemes = NewTeamThemeService()
@@ -1,27 +1,23 @@
 package services
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
-	"os"
+	"math"
 	"strings"
+	"ubible/models"
+	"ubible/reference"
 )
 
-// BibleBook represents a book in the KJV Bible
-type BibleBook struct {
-	Abbrev   string     `json:"abbrev"`
-	Name     string     `json:"name"`
-	Chapters [][]string `json:"chapters"`
-}
-
 // SearchResult represents a verse found in search
 type SearchResult struct {
-	Reference string `json:"reference"`
-	Text      string `json:"text"`
-	Book      string `json:"book"`
-	Chapter   int    `json:"chapter"`
-	Verse     int    `json:"verse"`
+	Reference string  `json:"reference"`
+	OSIS      string  `json:"osis"`
+	Text      string  `json:"text"`
+	Book      string  `json:"book"`
+	Chapter   int     `json:"chapter"`
+	Verse     int     `json:"verse"`
+	Score     float64 `json:"score"` // Relevance to the query
 }
 
 // GeneratedTheme represents a complete generated theme
@@ -37,59 +33,26 @@ type GeneratedTheme struct {
 
 // ThemeGeneratorRequest represents the request for theme generation
 type ThemeGeneratorRequest struct {
-	Keywords  []string `json:"keywords"`
-	Count     int      `json:"count"`
-	Testament string   `json:"testament"` // "OT", "NT", or "BOTH"
-	Books     []string `json:"books"`     // Specific books to search in
+	Keywords    []string `json:"keywords"` // Verses matching any keyword, when there's no query
+	Query       string   `json:"query"`    // Words, "phrases", AND, OR, NOT: love "one another" NOT hate
+	Count       int      `json:"count"`
+	Testament   string   `json:"testament"`   // "OT", "NT", or "BOTH"
+	Books       []string `json:"books"`       // Specific books to search in, by any name or abbreviation
+	Translation string   `json:"translation"` // Translation to search, default KJV
 }
 
-var (
-	bibleData      []BibleBook
-	bibleDataError error
-	otBooks        = map[string]bool{
-		"genesis": true, "exodus": true, "leviticus": true, "numbers": true, "deuteronomy": true,
-		"joshua": true, "judges": true, "ruth": true, "1 samuel": true, "2 samuel": true,
-		"1 kings": true, "2 kings": true, "1 chronicles": true, "2 chronicles": true,
-		"ezra": true, "nehemiah": true, "esther": true, "job": true, "psalms": true,
-		"proverbs": true, "ecclesiastes": true, "song of solomon": true, "isaiah": true,
-		"jeremiah": true, "lamentations": true, "ezekiel": true, "daniel": true, "hosea": true,
-		"joel": true, "amos": true, "obadiah": true, "jonah": true, "micah": true,
-		"nahum": true, "habakkuk": true, "zephaniah": true, "haggai": true, "zechariah": true, "malachi": true,
-	}
-)
-
-// LoadBibleData loads the KJV Bible data from kjv-full.json
-func LoadBibleData() error {
-	if len(bibleData) > 0 {
-		return nil // Already loaded
-	}
-
-	if bibleDataError != nil {
-		return bibleDataError
+// SearchVerses searches a translation in the Bible store for the verses most
+// relevant to the request's query, or to any of its keywords, spread across
+// the books that match. References are written in the translation's language.
+func SearchVerses(req ThemeGeneratorRequest) ([]SearchResult, error) {
+	if req.Translation == "" {
+		req.Translation = DefaultTranslation
 	}
-
-	file, err := os.ReadFile("./verses/kjv-full.json")
+	bible, err := Bible.Text(req.Translation)
 	if err != nil {
-		bibleDataError = fmt.Errorf("failed to read kjv-full.json: %w", err)
-		return bibleDataError
-	}
-
-	if err := json.Unmarshal(file, &bibleData); err != nil {
-		bibleDataError = fmt.Errorf("failed to parse kjv-full.json: %w", err)
-		return bibleDataError
-	}
-
-	log.Printf("Loaded %d books from KJV Bible", len(bibleData))
-	return nil
-}
-
-// SearchVerses searches for verses matching keywords
-func SearchVerses(req ThemeGeneratorRequest) ([]SearchResult, error) {
-	if err := LoadBibleData(); err != nil {
 		return nil, err
 	}
 
-	var results []SearchResult
 	count := req.Count
 	if count <= 0 {
 		count = 20
@@ -98,68 +61,71 @@ func SearchVerses(req ThemeGeneratorRequest) ([]SearchResult, error) {
 		count = 500
 	}
 
-	// Normalize books filter
-	bookFilter := make(map[string]bool)
-	for _, book := range req.Books {
-		bookFilter[strings.ToLower(strings.TrimSpace(book))] = true
+	bookFilter, err := ResolveBooks(req.Books)
+	if err != nil {
+		return nil, err
 	}
 
-	// Search through all books
-	for _, book := range bibleData {
-		// Skip if we have enough results
-		if len(results) >= count {
-			break
-		}
-
-		bookNameLower := strings.ToLower(book.Name)
+	query, err := req.SearchQuery()
+	if err != nil {
+		return nil, err
+	}
 
+	matches := bible.Search(query, func(v models.BibleVerse) bool {
 		// Filter by testament
-		if req.Testament == "OT" && !otBooks[bookNameLower] {
-			continue
+		book := reference.ByID(v.Book)
+		if req.Testament != "" && req.Testament != "BOTH" && book.Testament != req.Testament {
+			return false
 		}
-		if req.Testament == "NT" && otBooks[bookNameLower] {
-			continue
-		}
-
 		// Filter by specific books
-		if len(bookFilter) > 0 && !bookFilter[bookNameLower] {
+		return len(bookFilter) == 0 || bookFilter[book.ID]
+	})
+	total := len(matches)
+	matches = spreadAcrossBooks(matches, count)
+
+	results := make([]SearchResult, 0, len(matches))
+	for _, m := range matches {
+		book := reference.ByID(m.Verse.Book)
+		passage := reference.Passage{Book: book, Chapter: m.Verse.Chapter, Verse: m.Verse.Verse, EndChapter: m.Verse.Chapter, EndVerse: m.Verse.Verse}
+		results = append(results, SearchResult{
+			Reference: passage.Format(bible.Lang()),
+			OSIS:      passage.OSIS(),
+			Text:      m.Verse.Text,
+			Book:      book.NameIn(bible.Lang()),
+			Chapter:   m.Verse.Chapter,
+			Verse:     m.Verse.Verse,
+			Score:     math.Round(m.Score*100) / 100,
+		})
+	}
+
+	log.Printf("Found %d verses in %s matching %v, returning %d", total, bible.Translation.Code, query.Terms(), len(results))
+	return results, nil
+}
+
+// SearchQuery parses the request's query, or searches for any of its keywords
+// when there is none
+func (req ThemeGeneratorRequest) SearchQuery() (SearchQuery, error) {
+	if strings.TrimSpace(req.Query) != "" {
+		return ParseSearchQuery(req.Query)
+	}
+	return KeywordsQuery(req.Keywords)
+}
+
+// ResolveBooks turns a list of book names or abbreviations into a set of
+// OSIS book IDs. An empty list gives an empty set.
+func ResolveBooks(names []string) (map[string]bool, error) {
+	ids := make(map[string]bool, len(names))
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
 			continue
 		}
-
-		// Search through chapters and verses
-		for chapterNum, verses := range book.Chapters {
-			for verseNum, verseText := range verses {
-				if len(results) >= count {
-					break
-				}
-
-				// Check if verse matches any keyword (case-insensitive)
-				matches := false
-				verseTextLower := strings.ToLower(verseText)
-				for _, keyword := range req.Keywords {
-					keywordLower := strings.ToLower(strings.TrimSpace(keyword))
-					if keywordLower != "" && strings.Contains(verseTextLower, keywordLower) {
-						matches = true
-						break
-					}
-				}
-
-				if matches {
-					reference := fmt.Sprintf("%s %d:%d", book.Name, chapterNum+1, verseNum+1)
-					results = append(results, SearchResult{
-						Reference: reference,
-						Text:      verseText,
-						Book:      book.Name,
-						Chapter:   chapterNum + 1,
-						Verse:     verseNum + 1,
-					})
-				}
-			}
+		book, _, ok := reference.LookupBook(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", reference.ErrUnknownBook, name)
 		}
+		ids[book.ID] = true
 	}
-
-	log.Printf("Found %d verses matching keywords: %v", len(results), req.Keywords)
-	return results, nil
+	return ids, nil
 }
 
 // GenerateTheme generates a theme based on search results
@@ -234,7 +200,7 @@ func generateKeyInsights(keywords []string, verses []SearchResult) string {
 	otCount := 0
 	ntCount := 0
 	for _, v := range verses {
-		if otBooks[strings.ToLower(v.Book)] {
+		if book, _, ok := reference.LookupBook(v.Book); ok && book.Testament == reference.OldTestament {
 			otCount++
 		} else {
 			ntCount++
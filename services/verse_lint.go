@@ -0,0 +1,232 @@
+// services/verse_lint.go - Offline checks for the theme files in ./verses
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"ubible/reference"
+	"ubible/verseparser"
+)
+
+// Lint severities. Errors are problems the loader skips or works around with
+// placeholders; warnings are content an editor probably didn't intend.
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// minDistractorVerses is the fewest verses a list needs for every question to
+// draw its three wrong answers from the list itself
+const minDistractorVerses = 4
+
+// LintIssue is one problem found in a theme file. Line is 0 when the issue
+// concerns the whole file or a JSON question.
+type LintIssue struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	loc := i.File
+	if i.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", i.File, i.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s (%s)", loc, i.Severity, i.Message, i.Code)
+}
+
+// LintReport is the result of checking one file
+type LintReport struct {
+	File      string      `json:"file"`
+	Kind      string      `json:"kind"` // "verses" (TXT list), "questions" (JSON VerseFile) or "bible" (skipped)
+	Verses    int         `json:"verses,omitempty"`
+	Questions int         `json:"questions,omitempty"`
+	Issues    []LintIssue `json:"issues"`
+}
+
+func (r *LintReport) add(line int, severity, code, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, LintIssue{
+		File:     r.File,
+		Line:     line,
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Count returns how many issues have a severity
+func (r *LintReport) Count(severity string) int {
+	n := 0
+	for _, i := range r.Issues {
+		if i.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// LintVerseFile checks a theme file the way the loader reads it: TXT verse
+// lists through parseVerseFile and JSON files against the VerseFile schema.
+// JSON Bible texts (the sources of the Bible store) are recognised and skipped.
+func LintVerseFile(path string) LintReport {
+	report := LintReport{File: path, Issues: []LintIssue{}}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt":
+		report.Kind = "verses"
+		lintVerseList(&report)
+	case ".json":
+		report.Kind = "questions"
+		lintVerseFileJSON(&report)
+	default:
+		report.add(0, LintError, "unsupported_file", "only .txt verse lists and .json question files are loaded")
+	}
+	sort.SliceStable(report.Issues, func(i, j int) bool { return report.Issues[i].Line < report.Issues[j].Line })
+	return report
+}
+
+func lintVerseList(report *LintReport) {
+	verses, badLines, err := parseVerseFile(report.File, false)
+	if err != nil {
+		report.add(0, LintError, "unreadable", "%v", err)
+		return
+	}
+	report.Verses = len(verses)
+
+	for _, bl := range badLines {
+		switch {
+		case errors.Is(bl.Err, reference.ErrUnknownBook), errors.Is(bl.Err, reference.ErrChapterOutOfRange),
+			errors.Is(bl.Err, reference.ErrVerseOutOfRange):
+			report.add(bl.Line, LintError, "outside_canon", "%v", bl.Err)
+		case errors.Is(bl.Err, verseparser.ErrNoText):
+			report.add(bl.Line, LintError, "missing_text", "%v", bl.Err)
+		default:
+			report.add(bl.Line, LintError, "unparseable_line", "%v; expected 'N. <Reference> — <Text>'", bl.Err)
+		}
+	}
+
+	firstLine := make(map[string]int, len(verses))
+	byText := make(map[string]int, len(verses))
+	distinct := 0
+	for _, v := range verses {
+		if line, ok := firstLine[v.OSIS]; ok {
+			report.add(v.Line, LintWarning, "duplicate_verse", "%s is already listed on line %d", v.Reference, line)
+			continue
+		}
+		firstLine[v.OSIS] = v.Line
+		distinct++
+
+		key := normalizeVerseText(v.Text)
+		if line, ok := byText[key]; ok {
+			report.add(v.Line, LintWarning, "duplicate_text", "%s has the same text as line %d", v.Reference, line)
+			continue
+		}
+		byText[key] = v.Line
+	}
+
+	switch {
+	case len(verses) == 0:
+		report.add(0, LintError, "no_verses", "no verses found; the loader will skip this file")
+	case distinct < minDistractorVerses:
+		report.add(0, LintError, "too_few_verses",
+			"only %d distinct verses; at least %d are needed to draw 3 wrong answers per question from the list",
+			distinct, minDistractorVerses)
+	}
+}
+
+func lintVerseFileJSON(report *LintReport) {
+	data, err := os.ReadFile(report.File)
+	if err != nil {
+		report.add(0, LintError, "unreadable", "%v", err)
+		return
+	}
+	if _, err := ReadBibleFile(report.File); err == nil {
+		report.Kind = "bible"
+		return
+	}
+
+	var syntaxErr *json.SyntaxError
+	if err := json.Unmarshal(data, new(interface{})); errors.As(err, &syntaxErr) {
+		report.add(lineAt(data, syntaxErr.Offset), LintError, "invalid_json", "%v", err)
+		return
+	} else if err != nil {
+		report.add(0, LintError, "invalid_json", "%v", err)
+		return
+	}
+
+	var file VerseFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			report.add(lineAt(data, typeErr.Offset), LintError, "schema", "%v", err)
+		} else {
+			report.add(0, LintError, "schema", "not a theme file (%v); expected {\"theme\": ..., \"questions\": [...]}", err)
+		}
+		return
+	}
+	report.Questions = len(file.Questions)
+
+	if strings.TrimSpace(file.Theme) == "" {
+		report.add(0, LintError, "schema", "missing \"theme\"; the loader will skip this file")
+	}
+	if len(file.Questions) == 0 {
+		report.add(0, LintError, "no_questions", "no questions")
+	}
+
+	seen := make(map[string]int, len(file.Questions))
+	for i, q := range file.Questions {
+		n := i + 1
+		if q.Text == "" || q.CorrectAnswer == "" || len(q.Options) < 2 {
+			report.add(0, LintError, "invalid_question",
+				"question %d needs text, correct_answer and at least 2 options; the loader will skip it", n)
+			continue
+		}
+		if first, ok := seen[q.Text]; ok {
+			report.add(0, LintWarning, "duplicate_question", "question %d repeats question %d; the loader keeps only the first", n, first)
+		} else {
+			seen[q.Text] = n
+		}
+
+		wrong := 0
+		hasCorrect := false
+		for _, opt := range dedup(q.Options) {
+			if opt == q.CorrectAnswer {
+				hasCorrect = true
+			} else {
+				wrong++
+			}
+		}
+		if !hasCorrect {
+			report.add(0, LintWarning, "answer_not_in_options", "question %d: correct_answer %q isn't one of its options", n, q.CorrectAnswer)
+		}
+		if wrong < 3 {
+			report.add(0, LintWarning, "too_few_options", "question %d has %d wrong answers; the rest will be drawn from other themes", n, wrong)
+		}
+
+		if ref := strings.TrimSpace(q.Reference); ref != "" {
+			if _, err := reference.Parse(ref); errors.Is(err, reference.ErrUnknownBook) ||
+				errors.Is(err, reference.ErrChapterOutOfRange) || errors.Is(err, reference.ErrVerseOutOfRange) {
+				report.add(0, LintError, "outside_canon", "question %d: %v", n, err)
+			} else if err != nil {
+				report.add(0, LintError, "bad_reference", "question %d: %v", n, err)
+			}
+		}
+	}
+}
+
+// lineAt returns the 1-based line of a byte offset
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
@@ -3,10 +3,12 @@ package services
 import (
 	"ubible/database"
 	"ubible/models"
+	"ubible/reference"
 	"ubible/verseparser"
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"math/rand"
 	"os"
@@ -31,12 +33,31 @@ type Question struct {
 	CorrectAnswer string   `json:"correct_answer"`
 	Difficulty    string   `json:"difficulty"`
 	Reference     string   `json:"reference"`
+	OSIS          string   `json:"osis,omitempty"`
 	ThemeName     string   `json:"theme_name,omitempty"`
 }
 
+// Verse is a verse parsed from a verse list. Reference is the canonical
+// display form in the list's language, OSIS the canonical ID.
 type Verse struct {
 	Reference string `json:"reference"`
+	OSIS      string `json:"osis"`
 	Text      string `json:"text"`
+	Line      int    `json:"-"` // Line in the verse list it was parsed from
+}
+
+// LineError is a line of a verse list that didn't parse
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e LineError) Unwrap() error {
+	return e.Err
 }
 
 var (
@@ -149,6 +170,13 @@ func LoadVersesFromFiles() error {
 			}
 			wa = dedup(wa)
 
+			ref, osis := strings.TrimSpace(q.Reference), ""
+			if parsed, err := reference.Parse(ref); err == nil {
+				ref, osis = parsed.String(), parsed.OSIS()
+			} else if ref != "" {
+				log.Printf("WARN %s: question reference %q: %v", file, ref, err)
+			}
+
 			wrongAnswersJSON, err := json.Marshal(wa)
 			if err != nil {
 				log.Printf("Failed to marshal wrong answers: %v", err)
@@ -161,7 +189,8 @@ func LoadVersesFromFiles() error {
 				WrongAnswers:  string(wrongAnswersJSON),
 				CorrectAnswer: strings.TrimSpace(q.CorrectAnswer),
 				Difficulty:    strings.TrimSpace(q.Difficulty),
-				Reference:     strings.TrimSpace(q.Reference),
+				Reference:     ref,
+				OSIS:          osis,
 			}
 
 			if err := db.Create(&question).Error; err != nil {
@@ -228,7 +257,7 @@ func LoadVersesFromTXT() error {
 			continue
 		}
 		for _, bl := range badLines {
-			log.Printf("WARN %s:%d: does not match 'N. <Reference> — <Text>'", file, bl)
+			log.Printf("WARN %s:%d: %v", file, bl.Line, bl.Err)
 		}
 
 		if len(verses) > 0 {
@@ -253,16 +282,32 @@ func LoadVersesFromTXT() error {
 	return nil
 }
 
-func parseVerseFile(filePath string, _ bool) ([]Verse, []int, error) {
+func parseVerseFile(filePath string, _ bool) ([]Verse, []LineError, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
+	return parseVerseLines(file)
+}
+
+// ParseVerses reads a verse list in the TXT format and returns the verses plus
+// the line numbers that didn't parse
+func ParseVerses(r io.Reader) ([]Verse, []int, error) {
+	verses, lineErrs, err := parseVerseLines(r)
+	badLines := make([]int, len(lineErrs))
+	for i, le := range lineErrs {
+		badLines[i] = le.Line
+	}
+	return verses, badLines, err
+}
+
+// parseVerseLines reads a verse list, keeping why each bad line didn't parse
+func parseVerseLines(r io.Reader) ([]Verse, []LineError, error) {
 	var verses []Verse
-	var badLines []int
-	scanner := bufio.NewScanner(file)
+	var badLines []LineError
+	scanner := bufio.NewScanner(r)
 
 	// Example accepted formats:
 	// 1. N. John 3:16 — For God so loved the world ...
@@ -276,19 +321,17 @@ func parseVerseFile(filePath string, _ bool) ([]Verse, []int, error) {
 		// Normalize special Unicode spaces to regular spaces
 		line = strings.ReplaceAll(line, "\u202F", " ") // Narrow no-break space
 		line = strings.ReplaceAll(line, "\u00A0", " ") // Non-breaking space
-		line = strings.ReplaceAll(line, "–", "-")      // En dash
-		line = strings.ReplaceAll(line, "—", "-")      // Em dash
 
 		if line == "" {
 			continue
 		}
 
-		if ref, text := verseparser.ParseVerseSmart(line); ref != "" && text != "" {
-			verses = append(verses, Verse{Reference: ref, Text: text})
+		ref, text, err := verseparser.ParseVerseSmart(line)
+		if err != nil {
+			badLines = append(badLines, LineError{Line: lineNum, Err: err})
 			continue
 		}
-
-		badLines = append(badLines, lineNum)
+		verses = append(verses, Verse{Reference: ref.String(), OSIS: ref.OSIS(), Text: text, Line: lineNum})
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -305,10 +348,39 @@ func parseQAFormat(db *gorm.DB, theme models.Theme, filePath string) error {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	questions, err := ParseQA(file)
+	if err != nil {
+		return err
+	}
+
+	for i := range questions {
+		questions[i].ThemeID = theme.ID
+		if err := saveQuestion(db, &questions[i]); err != nil {
+			log.Printf("Failed to save question: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// ParseQA reads questions in the Q&A format: a "Q:" line followed by "A:" to
+// "D:" options, where A is the correct answer
+func ParseQA(r io.Reader) ([]models.Question, error) {
+	scanner := bufio.NewScanner(r)
+	var questions []models.Question
 	var currentQuestion *models.Question
 	var currentOptions []string
 
+	flush := func() {
+		if currentQuestion != nil && len(currentOptions) > 0 {
+			if err := setWrongAnswers(currentQuestion, currentOptions); err != nil {
+				log.Printf("Failed to build question: %v", err)
+				return
+			}
+			questions = append(questions, *currentQuestion)
+		}
+	}
+
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" {
@@ -316,13 +388,8 @@ func parseQAFormat(db *gorm.DB, theme models.Theme, filePath string) error {
 		}
 
 		if strings.HasPrefix(line, "Q:") {
-			if currentQuestion != nil && len(currentOptions) > 0 {
-				if err := saveQuestion(db, currentQuestion, currentOptions); err != nil {
-					log.Printf("Failed to save question: %v", err)
-				}
-			}
+			flush()
 			currentQuestion = &models.Question{
-				ThemeID:    theme.ID,
 				Text:       strings.TrimSpace(line[2:]),
 				Difficulty: "medium",
 			}
@@ -338,18 +405,13 @@ func parseQAFormat(db *gorm.DB, theme models.Theme, filePath string) error {
 			}
 		}
 	}
-
-	if currentQuestion != nil && len(currentOptions) > 0 {
-		if err := saveQuestion(db, currentQuestion, currentOptions); err != nil {
-			return fmt.Errorf("failed to save last question: %w", err)
-		}
-	}
+	flush()
 
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("scanner error: %w", err)
+		return nil, fmt.Errorf("scanner error: %w", err)
 	}
 
-	return nil
+	return questions, nil
 }
 
 func generateQuestionsFromVerses(db *gorm.DB, theme models.Theme, verses []Verse, _ bool) error {
@@ -391,6 +453,23 @@ func generateQuestionsFromVerses(db *gorm.DB, theme models.Theme, verses []Verse
 	return nil
 }
 
+// VerseQuestions builds the verse→reference and reference→verse questions for
+// a verse list without saving them. Wrong answers are drawn from the same list.
+func VerseQuestions(verses []Verse) []models.Question {
+	questions := make([]models.Question, 0, len(verses)*2)
+	for i, verse := range verses {
+		for _, q := range []models.Question{
+			generateVerseToReferenceQuestion(verse, verses, i, nil),
+			generateReferenceToVerseQuestion(verse, verses, i, nil),
+		} {
+			if strings.TrimSpace(q.Text) != "" {
+				questions = append(questions, q)
+			}
+		}
+	}
+	return questions
+}
+
 // generateVerseToReferenceQuestion: Shows verse text → user picks reference
 func generateVerseToReferenceQuestion(correct Verse, allVerses []Verse, _ int, db *gorm.DB) models.Question {
 	wrongRefs := generateWrongReferencesFromDB(db, correct.Reference, allVerses)
@@ -404,6 +483,7 @@ func generateVerseToReferenceQuestion(correct Verse, allVerses []Verse, _ int, d
 		CorrectAnswer: strings.TrimSpace(correct.Reference),
 		WrongAnswers:  string(wrongAnswersJSON),
 		Reference:     strings.TrimSpace(correct.Reference),
+		OSIS:          correct.OSIS,
 		Difficulty:    "medium",
 	}
 }
@@ -421,6 +501,7 @@ func generateReferenceToVerseQuestion(correct Verse, allVerses []Verse, _ int, d
 		CorrectAnswer: cleanText,
 		WrongAnswers:  string(wrongAnswersJSON),
 		Reference:     strings.TrimSpace(correct.Reference),
+		OSIS:          correct.OSIS,
 		Difficulty:    "medium",
 	}
 }
@@ -499,7 +580,9 @@ func generateReferenceToVerseQuestion(correct Verse, allVerses []Verse, _ int, d
 // 	}
 // }
 
-func saveQuestion(db *gorm.DB, question *models.Question, options []string) error {
+// setWrongAnswers stores every option except the correct answer as the
+// question's wrong answers
+func setWrongAnswers(question *models.Question, options []string) error {
 	if question == nil || len(options) == 0 {
 		return fmt.Errorf("invalid question or options")
 	}
@@ -518,7 +601,10 @@ func saveQuestion(db *gorm.DB, question *models.Question, options []string) erro
 		return fmt.Errorf("failed to marshal wrong answers: %w", err)
 	}
 	question.WrongAnswers = string(wrongAnswersJSON)
+	return nil
+}
 
+func saveQuestion(db *gorm.DB, question *models.Question) error {
 	var existing models.Question
 	if err := db.Where("text = ? AND theme_id = ?", strings.TrimSpace(question.Text), question.ThemeID).First(&existing).Error; err == nil {
 		return nil
@@ -665,6 +751,7 @@ func GetRandomQuestions(count int, themes []string, difficulty string) ([]Questi
 			CorrectAnswer: strings.TrimSpace(q.CorrectAnswer),
 			Difficulty:    strings.TrimSpace(q.Difficulty),
 			Reference:     strings.TrimSpace(q.Reference),
+			OSIS:          q.OSIS,
 			ThemeName:     strings.TrimSpace(q.Theme.Name),
 		}
 	}
@@ -736,14 +823,14 @@ func generateWrongReferencesFromDB(_ *gorm.DB, correctRef string, themeVerses []
 		}
 	}
 
-	// Fallback: generate random references if not enough verses in theme
+	// Fallback: random verses from the canon, named in the same language
 	if len(wrong) < 3 {
-		books := []string{"John", "Matthew", "Mark", "Luke", "Romans", "Genesis", "Psalms", "Proverbs", "Isaiah", "Jeremiah"}
+		lang := reference.English
+		if parsed, err := reference.Parse(correctRef); err == nil {
+			lang = parsed.Lang
+		}
 		for len(wrong) < 3 {
-			book := books[rand.Intn(len(books))]
-			chapter := rand.Intn(20) + 1
-			verse := rand.Intn(30) + 1
-			ref := fmt.Sprintf("%s %d:%d", book, chapter, verse)
+			ref := reference.Random(lang).String()
 			if !used[ref] {
 				wrong = append(wrong, ref)
 				used[ref] = true
@@ -0,0 +1,469 @@
+// services/verse_search.go - Inverted index and query language for verse search
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"ubible/models"
+	"ubible/reference"
+	"unicode"
+)
+
+var (
+	ErrInvalidSearchQuery = errors.New("invalid search query")
+	ErrEmptySearchQuery   = errors.New("search query has no words to find")
+)
+
+// BM25 ranking parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// SearchQuery is a parsed verse search: verses matching any group are found.
+//
+//	love "one another"        love AND the phrase "one another"
+//	faith OR hope             either word
+//	love NOT hate, love -hate love without hate
+type SearchQuery struct {
+	Groups []queryGroup
+}
+
+// queryGroup is one OR branch: every Must clause and no MustNot clause
+type queryGroup struct {
+	Must    []queryClause
+	MustNot []queryClause
+}
+
+// queryClause is a word or, with several words, a phrase
+type queryClause struct {
+	Words []string
+}
+
+func (c queryClause) String() string {
+	return strings.Join(c.Words, " ")
+}
+
+// ParseSearchQuery parses a query of words, "quoted phrases" and the
+// operators AND, OR and NOT (or a leading -). Words next to each other must
+// all match; AND binds tighter than OR.
+func ParseSearchQuery(s string) (SearchQuery, error) {
+	var q SearchQuery
+	group := queryGroup{}
+	negate := false
+
+	endGroup := func() error {
+		if negate {
+			return errors.New("NOT must be followed by a word or phrase")
+		}
+		if len(group.Must) == 0 {
+			if len(group.MustNot) > 0 {
+				return errors.New("a NOT needs something to search for alongside it")
+			}
+			return errors.New("OR must have a word or phrase on each side")
+		}
+		q.Groups = append(q.Groups, group)
+		group = queryGroup{}
+		return nil
+	}
+	addClause := func(text string) {
+		words := searchWords(text)
+		if len(words) == 0 {
+			return
+		}
+		if negate {
+			group.MustNot = append(group.MustNot, queryClause{Words: words})
+		} else {
+			group.Must = append(group.Must, queryClause{Words: words})
+		}
+		negate = false
+	}
+
+	rest := strings.TrimSpace(s)
+	for rest != "" {
+		var tok string
+		quoted := false
+		if rest[0] == '"' || strings.HasPrefix(rest, "-\"") {
+			if rest[0] == '-' {
+				negate = true
+				rest = rest[1:]
+			}
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return SearchQuery{}, fmt.Errorf("%w: unclosed quote", ErrInvalidSearchQuery)
+			}
+			tok, rest = rest[1:end+1], rest[end+2:]
+			quoted = true
+		} else if i := strings.IndexFunc(rest, unicode.IsSpace); i >= 0 {
+			tok, rest = rest[:i], rest[i:]
+		} else {
+			tok, rest = rest, ""
+		}
+		rest = strings.TrimSpace(rest)
+
+		switch {
+		case quoted:
+			addClause(tok)
+		case tok == "AND":
+		case tok == "OR":
+			if err := endGroup(); err != nil {
+				return SearchQuery{}, fmt.Errorf("%w: %v", ErrInvalidSearchQuery, err)
+			}
+		case tok == "NOT":
+			negate = true
+		case strings.HasPrefix(tok, "-") && len(tok) > 1:
+			negate = true
+			addClause(tok[1:])
+		default:
+			addClause(tok)
+		}
+	}
+	if len(q.Groups) == 0 && len(group.Must) == 0 && len(group.MustNot) == 0 {
+		return SearchQuery{}, ErrEmptySearchQuery
+	}
+	if err := endGroup(); err != nil {
+		return SearchQuery{}, fmt.Errorf("%w: %v", ErrInvalidSearchQuery, err)
+	}
+	return q, nil
+}
+
+// KeywordsQuery matches verses containing any of the keywords. A keyword of
+// several words is searched as a phrase.
+func KeywordsQuery(keywords []string) (SearchQuery, error) {
+	var q SearchQuery
+	for _, keyword := range keywords {
+		if words := searchWords(keyword); len(words) > 0 {
+			q.Groups = append(q.Groups, queryGroup{Must: []queryClause{{Words: words}}})
+		}
+	}
+	if len(q.Groups) == 0 {
+		return SearchQuery{}, ErrEmptySearchQuery
+	}
+	return q, nil
+}
+
+// Terms returns the words and phrases the query searches for, without the
+// excluded ones
+func (q SearchQuery) Terms() []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, g := range q.Groups {
+		for _, c := range g.Must {
+			if t := c.String(); !seen[t] {
+				seen[t] = true
+				terms = append(terms, t)
+			}
+		}
+	}
+	return terms
+}
+
+// VerseMatch is a verse found by a search with its relevance score
+type VerseMatch struct {
+	Verse models.BibleVerse
+	Score float64
+}
+
+// verseIndex is an inverted index over one translation's verses. Verses are
+// stored as sequences of term IDs so phrases can be checked without keeping
+// positions in the postings.
+type verseIndex struct {
+	lang     reference.Language
+	terms    map[string]uint32
+	postings [][]int32  // Term ID -> verses containing it, ascending
+	docs     [][]uint32 // Verse -> its term IDs in order
+	avgLen   float64
+}
+
+// newVerseIndex indexes verses; verse positions in the slice are the doc IDs
+func newVerseIndex(verses []models.BibleVerse, lang reference.Language) *verseIndex {
+	ix := &verseIndex{
+		lang:  lang,
+		terms: make(map[string]uint32),
+		docs:  make([][]uint32, len(verses)),
+	}
+	total := 0
+	for d, v := range verses {
+		words := searchWords(v.Text)
+		ids := make([]uint32, len(words))
+		for i, w := range words {
+			term := ix.stem(w)
+			id, ok := ix.terms[term]
+			if !ok {
+				id = uint32(len(ix.postings))
+				ix.terms[term] = id
+				ix.postings = append(ix.postings, nil)
+			}
+			ids[i] = id
+			if p := ix.postings[id]; len(p) == 0 || p[len(p)-1] != int32(d) {
+				ix.postings[id] = append(p, int32(d))
+			}
+		}
+		ix.docs[d] = ids
+		total += len(ids)
+	}
+	if len(verses) > 0 {
+		ix.avgLen = float64(total) / float64(len(verses))
+	}
+	return ix
+}
+
+func (ix *verseIndex) stem(word string) string {
+	if ix.lang == reference.English {
+		return stemEnglish(word)
+	}
+	return word
+}
+
+// clauseTerms turns a clause's words into term IDs, false if any word isn't
+// in the index
+func (ix *verseIndex) clauseTerms(c queryClause) ([]uint32, bool) {
+	ids := make([]uint32, len(c.Words))
+	for i, w := range c.Words {
+		id, ok := ix.terms[ix.stem(w)]
+		if !ok {
+			return nil, false
+		}
+		ids[i] = id
+	}
+	return ids, true
+}
+
+// hasSequence reports whether a verse contains the terms in a row
+func (ix *verseIndex) hasSequence(doc int32, seq []uint32) bool {
+	terms := ix.docs[doc]
+	for i := 0; i+len(seq) <= len(terms); i++ {
+		match := true
+		for j, id := range seq {
+			if terms[i+j] != id {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// search scores every verse matching the query that keep accepts. A verse
+// matching several OR groups scores the sum of them.
+func (ix *verseIndex) search(q SearchQuery, keep func(doc int32) bool) map[int32]float64 {
+	scores := make(map[int32]float64)
+	for _, g := range q.Groups {
+		var must [][]uint32
+		ok := true
+		for _, c := range g.Must {
+			ids, found := ix.clauseTerms(c)
+			if !found {
+				ok = false
+				break
+			}
+			must = append(must, ids)
+		}
+		if !ok {
+			continue
+		}
+		var mustNot [][]uint32
+		for _, c := range g.MustNot {
+			if ids, found := ix.clauseTerms(c); found {
+				mustNot = append(mustNot, ids)
+			}
+		}
+
+		for _, doc := range ix.candidates(must) {
+			if !keep(doc) || !ix.matchesAll(doc, must) || ix.matchesAny(doc, mustNot) {
+				continue
+			}
+			scores[doc] += ix.score(doc, must)
+		}
+	}
+	return scores
+}
+
+// candidates intersects the postings of every term, rarest first
+func (ix *verseIndex) candidates(clauses [][]uint32) []int32 {
+	var lists [][]int32
+	for _, ids := range clauses {
+		for _, id := range ids {
+			lists = append(lists, ix.postings[id])
+		}
+	}
+	if len(lists) == 0 {
+		return nil
+	}
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+
+	result := lists[0]
+	for _, list := range lists[1:] {
+		var next []int32
+		i, j := 0, 0
+		for i < len(result) && j < len(list) {
+			switch {
+			case result[i] == list[j]:
+				next = append(next, result[i])
+				i++
+				j++
+			case result[i] < list[j]:
+				i++
+			default:
+				j++
+			}
+		}
+		result = next
+	}
+	return result
+}
+
+// matchesAll checks the phrases among the clauses; single words are already
+// guaranteed by the postings intersection
+func (ix *verseIndex) matchesAll(doc int32, clauses [][]uint32) bool {
+	for _, seq := range clauses {
+		if len(seq) > 1 && !ix.hasSequence(doc, seq) {
+			return false
+		}
+	}
+	return true
+}
+
+func (ix *verseIndex) matchesAny(doc int32, clauses [][]uint32) bool {
+	for _, seq := range clauses {
+		if ix.hasSequence(doc, seq) {
+			return true
+		}
+	}
+	return false
+}
+
+// score is the verse's BM25 score for the clauses' terms
+func (ix *verseIndex) score(doc int32, clauses [][]uint32) float64 {
+	terms := ix.docs[doc]
+	n := float64(len(ix.docs))
+	docLen := float64(len(terms))
+
+	score := 0.0
+	for _, seq := range clauses {
+		for _, id := range seq {
+			tf := 0.0
+			for _, t := range terms {
+				if t == id {
+					tf++
+				}
+			}
+			df := float64(len(ix.postings[id]))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*docLen/ix.avgLen))
+		}
+	}
+	return score
+}
+
+// Search finds the verses matching a query, best first. keep filters verses
+// before they're scored.
+func (t *BibleText) Search(q SearchQuery, keep func(models.BibleVerse) bool) []VerseMatch {
+	if t.index == nil {
+		return nil
+	}
+	scores := t.index.search(q, func(doc int32) bool {
+		return keep == nil || keep(t.verses[doc])
+	})
+
+	docs := make([]int32, 0, len(scores))
+	for doc := range scores {
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if scores[docs[i]] != scores[docs[j]] {
+			return scores[docs[i]] > scores[docs[j]]
+		}
+		return docs[i] < docs[j] // Canonical order between equals
+	})
+
+	matches := make([]VerseMatch, len(docs))
+	for i, doc := range docs {
+		matches[i] = VerseMatch{Verse: t.verses[doc], Score: scores[doc]}
+	}
+	return matches
+}
+
+// spreadAcrossBooks picks count matches, best first, taking no more than an
+// even share from any one book until every book has had its turn
+func spreadAcrossBooks(matches []VerseMatch, count int) []VerseMatch {
+	if len(matches) <= count {
+		return matches
+	}
+	books := make(map[string]bool)
+	for _, m := range matches {
+		books[m.Verse.Book] = true
+	}
+	share := (count + len(books) - 1) / len(books)
+
+	picked := make([]VerseMatch, 0, count)
+	taken := make([]bool, len(matches))
+	perBook := make(map[string]int)
+	for i, m := range matches {
+		if len(picked) == count {
+			break
+		}
+		if perBook[m.Verse.Book] < share {
+			perBook[m.Verse.Book]++
+			picked = append(picked, m)
+			taken[i] = true
+		}
+	}
+	for i, m := range matches {
+		if len(picked) == count {
+			break
+		}
+		if !taken[i] {
+			picked = append(picked, m)
+		}
+	}
+	return picked
+}
+
+// searchWords splits text into lower-case words, ignoring punctuation.
+// Apostrophes are dropped rather than split on, so "Lord's" is one word.
+func searchWords(text string) []string {
+	text = strings.NewReplacer("'", "", "’", "", "‘", "").Replace(strings.ToLower(text))
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// stemEnglish strips common inflections, including the KJV's -eth and -est,
+// so "loveth", "loved", "loving" and "love" are all one term. It is
+// deliberately light: it only needs to be consistent between verses and
+// queries.
+func stemEnglish(word string) string {
+	if len(word) <= 3 {
+		return word
+	}
+	for _, suffix := range []string{"ings", "ing", "eth", "est", "edst", "ed", "ies", "es", "s"} {
+		if !strings.HasSuffix(word, suffix) || len(word)-len(suffix) < 3 {
+			continue
+		}
+		if suffix == "s" && (strings.HasSuffix(word, "ss") || strings.HasSuffix(word, "us")) {
+			break
+		}
+		stem := word[:len(word)-len(suffix)]
+		if suffix == "ies" {
+			stem += "y"
+		}
+		// "sinned" -> "sin", but "killed" stays "kill"
+		if n := len(stem); n > 3 && stem[n-1] == stem[n-2] && !strings.ContainsRune("lsz", rune(stem[n-1])) && !isVowel(stem[n-1]) {
+			stem = stem[:n-1]
+		}
+		word = stem
+		break
+	}
+	return strings.TrimSuffix(word, "e")
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) >= 0
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// describeQuery writes a query compactly: groups split by " | ", phrases in
+// brackets and excluded clauses with a leading -
+func describeQuery(q SearchQuery) string {
+	groups := make([]string, len(q.Groups))
+	for i, g := range q.Groups {
+		var parts []string
+		for _, c := range g.Must {
+			parts = append(parts, describeClause(c))
+		}
+		for _, c := range g.MustNot {
+			parts = append(parts, "-"+describeClause(c))
+		}
+		groups[i] = strings.Join(parts, " ")
+	}
+	return strings.Join(groups, " | ")
+}
+
+func describeClause(c queryClause) string {
+	if len(c.Words) > 1 {
+		return "[" + c.String() + "]"
+	}
+	return c.String()
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"love", "love"},
+		{"Love  Mercy", "love mercy"},
+		{"love AND mercy", "love mercy"},
+		{`love "one another"`, "love [one another]"},
+		{"faith OR hope", "faith | hope"},
+		{"faith hope OR love", "faith hope | love"},
+		{"love NOT hate", "love -hate"},
+		{"love -hate", "love -hate"},
+		{`love -"hate thee"`, "love -[hate thee]"},
+		{"NOT hate love", "love -hate"},
+		{"don't fear", "dont fear"},
+		{`"Lord's prayer"`, "[lords prayer]"},
+		{"a-b", "[a b]"},
+	}
+	for _, tt := range tests {
+		q, err := ParseSearchQuery(tt.in)
+		if err != nil {
+			t.Errorf("ParseSearchQuery(%q): %v", tt.in, err)
+			continue
+		}
+		if got := describeQuery(q); got != tt.want {
+			t.Errorf("ParseSearchQuery(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseSearchQueryErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want error
+	}{
+		{"", ErrEmptySearchQuery},
+		{"   ", ErrEmptySearchQuery},
+		{"!!", ErrEmptySearchQuery},
+		{`"one another`, ErrInvalidSearchQuery},
+		{"faith OR", ErrInvalidSearchQuery},
+		{"OR hope", ErrInvalidSearchQuery},
+		{"faith OR OR hope", ErrInvalidSearchQuery},
+		{"love NOT", ErrInvalidSearchQuery},
+		{"-hate", ErrInvalidSearchQuery},
+		{"NOT hate OR love", ErrInvalidSearchQuery},
+	}
+	for _, tt := range tests {
+		if _, err := ParseSearchQuery(tt.in); !errors.Is(err, tt.want) {
+			t.Errorf("ParseSearchQuery(%q) error = %v, want %v", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestSearchQueryTerms(t *testing.T) {
+	q, err := ParseSearchQuery(`love "one another" OR love -hate`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(q.Terms(), ","); got != "love,one another" {
+		t.Errorf("Terms() = %q, want %q", got, "love,one another")
+	}
+}
@@ -1,86 +1,97 @@
 package verseparser
 
 import (
+	"errors"
+	"fmt"
 	"regexp"
 	"strings"
+	"ubible/reference"
 )
 
-var numPrefix = regexp.MustCompile(`^\d+\.`)
+var (
+	ErrNoReference = errors.New("line does not start with a reference")
+	ErrNoText      = errors.New("no verse text after the reference")
+)
+
+var (
+	numPrefix = regexp.MustCompile(`^\d+\.\s*`)
+	specToken = regexp.MustCompile(`^[\d:.,;\-]+$`)
+)
+
+// maxBookWords is the most words a book name takes ("1 Mambo ya Nyakati")
+const maxBookWords = 4
 
 func normalize(line string) string {
 	line = strings.TrimSpace(line)
 	line = strings.ReplaceAll(line, "\u202F", " ")
 	line = strings.ReplaceAll(line, "\u00A0", " ")
-	line = strings.ReplaceAll(line, "–", " ")
-	line = strings.ReplaceAll(line, "—", " ")
-	line = strings.ReplaceAll(line, "=>", " ")
-	line = strings.ReplaceAll(line, "->", " ")
+	line = strings.ReplaceAll(line, "–", " - ")
+	line = strings.ReplaceAll(line, "—", " - ")
+	line = strings.ReplaceAll(line, "=>", " - ")
+	line = strings.ReplaceAll(line, "->", " - ")
 	return line
 }
 
-func ParseVerseSmart(line string) (string, string) {
-	line = normalize(line)
+// ParseVerseSmart splits a verse line such as "12. Yohana 3:16 — Kwa maana..."
+// into its reference and text. The book name is matched against the canon,
+// the longest known name first, and the chapter/verse list that follows is
+// checked against the book's bounds. Errors from the reference package are
+// returned wrapped, so callers can tell unknown books from bad chapters.
+func ParseVerseSmart(line string) (reference.Reference, string, error) {
+	line = numPrefix.ReplaceAllString(normalize(line), "")
 	tokens := strings.Fields(line)
-	
-	if len(tokens) < 5 {
-		return "", ""
+
+	for words := min(maxBookWords, len(tokens)); words >= 1; words-- {
+		book := strings.Join(tokens[:words], " ")
+		if _, _, ok := reference.LookupBook(book); !ok {
+			continue
+		}
+
+		spec, rest := splitSpec(tokens[words:])
+		if spec == "" {
+			return reference.Reference{}, "", fmt.Errorf("%w: %s has no chapter or verse", ErrNoReference, book)
+		}
+		ref, err := reference.Parse(book + " " + spec)
+		if err != nil {
+			return reference.Reference{}, "", err
+		}
+
+		// Text must have at least 2 words
+		if len(rest) < 2 {
+			return ref, "", ErrNoText
+		}
+		return ref, strings.Join(rest, " "), nil
 	}
-	
-	// Find colon token
-	colonIdx := -1
-	for i, t := range tokens {
-		if strings.Contains(t, ":") {
-			colonIdx = i
+
+	// A chapter:verse after a few words means the book name wasn't recognised
+	for i, tok := range tokens {
+		if i > maxBookWords {
 			break
 		}
-	}
-	
-	if colonIdx == -1 {
-		return "", ""
-	}
-	
-	// Book name: up to 3 words before colon
-	bookStart := 0
-	if numPrefix.MatchString(tokens[0]) {
-		bookStart = 1
-	}
-	
-	if colonIdx - bookStart > 3 {
-		bookStart = colonIdx - 3
-	}
-	
-	// Build reference: book + chapter:verse
-	refTokens := tokens[bookStart : colonIdx+1]
-	
-	// Check for verse range: "5:22 — 23" or "5:22 23"
-	textStart := colonIdx + 1
-	if textStart < len(tokens) {
-		next := tokens[textStart]
-		if regexp.MustCompile(`^\d+$`).MatchString(next) {
-			// It's a verse range
-			refTokens = append(refTokens, next)
-			textStart++
+		if i > 0 && specToken.MatchString(tok) && strings.Contains(tok, ":") {
+			return reference.Reference{}, "", fmt.Errorf("%w: %q", reference.ErrUnknownBook, strings.Join(tokens[:i], " "))
 		}
 	}
-	
-	// Text must have at least 2 words
-	if len(tokens) - textStart < 2 {
-		return "", ""
-	}
-	
-	// Build reference string
-	ref := strings.Join(refTokens, " ")
-	ref = numPrefix.ReplaceAllString(ref, "")
-	ref = strings.TrimSpace(ref)
-	
-	// Normalize verse range format
-	if regexp.MustCompile(`\d+:\d+\s+\d+$`).MatchString(ref) {
-		ref = regexp.MustCompile(`(\d+:\d+)\s+(\d+)`).ReplaceAllString(ref, "$1-$2")
+	return reference.Reference{}, "", ErrNoReference
+}
+
+// splitSpec takes the chapter/verse tokens off the front of the words after
+// a book name: "5:22", "-", "23", "-", "Lakini" gives "5:22-23". A bare
+// number straight after a verse is read as the end of a range.
+func splitSpec(tokens []string) (string, []string) {
+	var sb strings.Builder
+	i := 0
+	for ; i < len(tokens) && specToken.MatchString(tokens[i]); i++ {
+		tok := tokens[i]
+		if sb.Len() > 0 && isDigit(sb.String()[sb.Len()-1]) && isDigit(tok[0]) {
+			sb.WriteByte('-')
+		}
+		sb.WriteString(tok)
 	}
-	
-	// Build text string
-	text := strings.Join(tokens[textStart:], " ")
-	text = strings.TrimSpace(text)
-	
-	return ref, text
+	spec := strings.TrimRight(sb.String(), "-,;.")
+	return spec, tokens[i:]
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
 }